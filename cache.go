@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	gap "github.com/muesli/go-app-paths"
+)
+
+// cachedAtHeader is set on responses which were served from the cache
+// without revalidation, i.e. in offline mode. It holds the time the cached
+// copy was last fetched or revalidated.
+const cachedAtHeader = "X-Glow-Cached-At"
+
+// errNotCached is returned in offline mode when a URL has never been fetched.
+var errNotCached = errors.New("not available offline")
+
+func getHTTPCacheDir() (string, error) {
+	dir, err := gap.NewScope(gap.User, "glow").CacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get cache dir: %w", err)
+	}
+	return filepath.Join(dir, "http"), nil
+}
+
+// cacheEntry is the metadata stored next to a cached response body.
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// cachingTransport stores successful GET responses on disk and revalidates
+// them with If-None-Match and If-Modified-Since on subsequent requests. When
+// offline is set, cached responses are served without touching the network.
+type cachingTransport struct {
+	next    http.RoundTripper
+	dir     string
+	offline bool
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req) //nolint:wrapcheck
+	}
+
+	key := cacheKey(req.URL.String())
+	entry, body, cacheErr := t.load(key)
+	cached := cacheErr == nil
+
+	if t.offline {
+		if !cached {
+			return nil, errNotCached
+		}
+		res := entry.response(req, body)
+		res.Header.Set(cachedAtHeader, entry.FetchedAt.Format(time.RFC3339))
+		return res, nil
+	}
+
+	if cached {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached:
+		_ = res.Body.Close()
+		log.Debug("http cache revalidated", "url", req.URL)
+		entry.FetchedAt = time.Now()
+		t.save(key, entry, nil)
+		return entry.response(req, body), nil
+
+	case res.StatusCode == http.StatusOK:
+		b, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read http response body: %w", err)
+		}
+		t.save(key, cacheEntry{
+			URL:          req.URL.String(),
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			ContentType:  res.Header.Get("Content-Type"),
+			FetchedAt:    time.Now(),
+		}, b)
+		res.Body = io.NopCloser(bytes.NewReader(b))
+		return res, nil
+	}
+
+	return res, nil
+}
+
+func (t *cachingTransport) load(key string) (cacheEntry, []byte, error) {
+	var entry cacheEntry
+	meta, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if err != nil {
+		return entry, nil, fmt.Errorf("unable to read cache entry: %w", err)
+	}
+	if err := json.Unmarshal(meta, &entry); err != nil {
+		return entry, nil, fmt.Errorf("unable to parse cache entry: %w", err)
+	}
+	body, err := os.ReadFile(filepath.Join(t.dir, key+".body"))
+	if err != nil {
+		return entry, nil, fmt.Errorf("unable to read cache entry: %w", err)
+	}
+	return entry, body, nil
+}
+
+// save writes a cache entry. If body is nil only the metadata is updated.
+// Failing to write the cache is logged, but never fails the request.
+func (t *cachingTransport) save(key string, entry cacheEntry, body []byte) {
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		log.Debug("unable to create http cache dir", "error", err)
+		return
+	}
+	if body != nil {
+		if err := os.WriteFile(filepath.Join(t.dir, key+".body"), body, 0o600); err != nil {
+			log.Debug("unable to write http cache", "error", err)
+			return
+		}
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		log.Debug("unable to encode http cache entry", "error", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, key+".json"), meta, 0o600); err != nil {
+		log.Debug("unable to write http cache", "error", err)
+	}
+}
+
+// response synthesizes a successful response from a cache entry.
+func (e cacheEntry) response(req *http.Request, body []byte) *http.Response {
+	h := http.Header{}
+	if e.ContentType != "" {
+		h.Set("Content-Type", e.ContentType)
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func cacheKey(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return hex.EncodeToString(sum[:])
+}
+
+// responseCachedAt returns when an offline response was originally fetched,
+// or the zero time if it came from the network.
+func responseCachedAt(res *http.Response) time.Time {
+	t, err := time.Parse(time.RFC3339, res.Header.Get(cachedAtHeader))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachingTransport(t *testing.T) {
+	var requests, revalidations int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "# Cached")
+	}))
+
+	dir := t.TempDir()
+	get := func(offline bool) (string, time.Time, error) {
+		c, err := newHTTPClient(httpOptions{timeout: time.Second, cacheDir: dir, offline: offline})
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved := httpClient
+		httpClient = c
+		defer func() { httpClient = saved }()
+
+		res, err := httpGet(context.Background(), srv.URL+"/doc.md")
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		defer res.Body.Close() //nolint:errcheck
+		b, err := io.ReadAll(res.Body)
+		return string(b), responseCachedAt(res), err
+	}
+
+	t.Run("offline without cache fails", func(t *testing.T) {
+		if _, _, err := get(true); !errors.Is(err, errNotCached) {
+			t.Errorf("expected errNotCached, got %v", err)
+		}
+	})
+
+	t.Run("first fetch populates cache", func(t *testing.T) {
+		body, cachedAt, err := get(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "# Cached" {
+			t.Errorf("expected body %q, got %q", "# Cached", body)
+		}
+		if !cachedAt.IsZero() {
+			t.Error("network response should not report a cache time")
+		}
+	})
+
+	t.Run("second fetch revalidates", func(t *testing.T) {
+		body, _, err := get(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "# Cached" {
+			t.Errorf("expected cached body %q, got %q", "# Cached", body)
+		}
+		if revalidations != 1 {
+			t.Errorf("expected 1 revalidation, got %d", revalidations)
+		}
+	})
+
+	t.Run("offline serves cache", func(t *testing.T) {
+		srv.Close()
+		before := requests
+
+		body, cachedAt, err := get(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "# Cached" {
+			t.Errorf("expected cached body %q, got %q", "# Cached", body)
+		}
+		if cachedAt.IsZero() {
+			t.Error("offline response should report when it was cached")
+		}
+		if requests != before {
+			t.Error("offline mode should not hit the network")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/glow/v2/utils"
+)
+
+// htmlAnchor matches the anchors of HTML elements in markdown, which links
+// can point to as well as to headings.
+var htmlAnchor = regexp.MustCompile(`<[a-zA-Z][^>]*\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+
+// brokenLink is a link of a document that doesn't resolve.
+type brokenLink struct {
+	file    string
+	line    int
+	message string
+}
+
+func (l brokenLink) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.file, l.line, l.message)
+}
+
+// linkChecker checks that the local links of markdown documents point to
+// files and headings that exist.
+type linkChecker struct {
+	// root is the directory absolute link paths are resolved against.
+	root string
+	// anchors caches the anchors of the documents links point to, by path.
+	anchors map[string]map[string]bool
+}
+
+func newLinkChecker(root string) *linkChecker {
+	return &linkChecker{
+		root:    root,
+		anchors: map[string]map[string]bool{},
+	}
+}
+
+// check returns the broken links of a markdown file, reported under name.
+func (c *linkChecker) check(path, name string) ([]brokenLink, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file: %w", err)
+	}
+	doc, err := structure.Parse(b)
+	if err != nil {
+		return []brokenLink{{file: name, line: 1, message: err.Error()}}, nil
+	}
+	c.anchors[path] = documentAnchors(b, doc)
+
+	var broken []brokenLink
+	for _, l := range doc.Links {
+		if msg := c.checkLink(path, l); msg != "" {
+			broken = append(broken, brokenLink{file: name, line: l.Line, message: msg})
+		}
+	}
+	return broken, nil
+}
+
+// checkLink checks a link of the file at path. It returns what's wrong with
+// the link, or nothing if it resolves.
+func (c *linkChecker) checkLink(path string, l structure.Link) string {
+	kind := "link"
+	if l.Image {
+		kind = "image"
+	}
+	if l.URL == "" {
+		return "empty " + kind
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return fmt.Sprintf("invalid %s %q", kind, l.URL)
+	}
+	// remote links, and links like mailto: aren't checked
+	if u.Scheme != "" || u.Host != "" {
+		return ""
+	}
+
+	target := path
+	if u.Path != "" {
+		p := filepath.FromSlash(u.Path)
+		if strings.HasPrefix(u.Path, "/") {
+			target = filepath.Join(c.root, p)
+		} else {
+			target = filepath.Join(filepath.Dir(path), p)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			return fmt.Sprintf("broken %s: %s does not exist", kind, u.Path)
+		}
+		if info.IsDir() {
+			return ""
+		}
+	}
+
+	if u.Fragment == "" || !utils.IsMarkdownFile(target) {
+		return ""
+	}
+	anchors, ok := c.anchors[target]
+	if !ok {
+		b, err := os.ReadFile(target)
+		if err != nil {
+			return fmt.Sprintf("broken %s: unable to read %s", kind, u.Path)
+		}
+		doc, err := structure.Parse(b)
+		if err != nil {
+			return fmt.Sprintf("broken %s: %s: %v", kind, u.Path, err)
+		}
+		anchors = documentAnchors(b, doc)
+		c.anchors[target] = anchors
+	}
+	if anchors[strings.ToLower(u.Fragment)] {
+		return ""
+	}
+	if u.Path == "" {
+		return fmt.Sprintf("broken anchor: no heading for #%s", u.Fragment)
+	}
+	return fmt.Sprintf("broken anchor: no heading for #%s in %s", u.Fragment, u.Path)
+}
+
+// documentAnchors returns the anchors of a document: the slugs of its
+// headings, and the ids and names of its HTML elements.
+func documentAnchors(src []byte, doc *structure.Document) map[string]bool {
+	anchors := map[string]bool{}
+	structure.Walk(doc.Headings, func(h *structure.Heading) {
+		anchors[h.Slug] = true
+	})
+	for _, m := range htmlAnchor.FindAllSubmatch(src, -1) {
+		anchors[strings.ToLower(string(m[1]))] = true
+	}
+	return anchors
+}
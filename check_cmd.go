@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/muesli/gitcha"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:     "check [DIR|FILE]...",
+	Short:   "Check the local links and anchors of markdown documents",
+	Long:    paragraph(fmt.Sprintf("\n%s that the relative links, images and #anchors of markdown documents point to files and headings that exist. Directories are searched for markdown files like the file listing does, skipping ignored files. Broken links are reported as file:line: message.", keyword("Check"))),
+	Example: paragraph("glow check\nglow check docs\nglow check README.md CONTRIBUTING.md"),
+	Args:    cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		var broken, files int
+		for _, arg := range args {
+			paths, err := checkFiles(arg)
+			if err != nil {
+				return err
+			}
+			c := newLinkChecker(checkRoot(arg))
+			for _, path := range paths {
+				links, err := c.check(path, displayPath(path))
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				for _, l := range links {
+					fmt.Fprintln(cmd.OutOrStdout(), l)
+				}
+				if len(links) > 0 {
+					broken += len(links)
+					files++
+				}
+			}
+		}
+		switch {
+		case broken == 1:
+			return errors.New("found 1 broken link")
+		case files == 1:
+			return fmt.Errorf("found %d broken links in 1 file", broken)
+		case broken > 0:
+			return fmt.Errorf("found %d broken links in %d files", broken, files)
+		}
+		return nil
+	},
+}
+
+// checkFiles returns the markdown files to check for an argument, which is
+// either a file or a directory to search.
+func checkFiles(arg string) ([]string, error) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{arg}, nil
+	}
+
+	files, err := stashFiles(arg)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range files {
+		if utils.IsMarkdownFile(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// checkRoot returns the directory absolute links in the documents of an
+// argument resolve against: the root of their git repository, or the
+// directory itself.
+func checkRoot(arg string) string {
+	dir, err := filepath.Abs(arg)
+	if err != nil {
+		return arg
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	if root, err := gitcha.GitRepoForPath(dir); err == nil && root != "" {
+		return root
+	}
+	return dir
+}
+
+// displayPath returns a path relative to the working directory, if it's
+// below it.
+func displayPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLinkChecker(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"README.md": "# Project\n\n" +
+			"Read the [guide](docs/guide.md#setup) and the [notes](docs/notes.md).\n" +
+			"![logo](logo.png) ![banner](banner.png)\n\n" +
+			"## Usage\n\n" +
+			"[up](#project) [down](#missing) <span id=\"custom\"></span> [custom](#Custom)\n" +
+			"[site](https://example.com) [mail](mailto:a@b.c) [docs](docs) [root](/docs/guide.md)\n\n" +
+			"```md\n[in code](nowhere.md)\n```\n",
+		"docs/guide.md": "# Guide\n\n## Setup\n\n[back](../README.md#usage) [wrong](../README.md#setup) [empty]()\n",
+		"logo.png":      "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file string
+		want []brokenLink
+	}{
+		{"README.md", []brokenLink{
+			{"README.md", 3, "broken link: docs/notes.md does not exist"},
+			{"README.md", 4, "broken image: banner.png does not exist"},
+			{"README.md", 8, "broken anchor: no heading for #missing"},
+		}},
+		{"docs/guide.md", []brokenLink{
+			{"docs/guide.md", 5, "broken anchor: no heading for #setup in ../README.md"},
+			{"docs/guide.md", 5, "empty link"},
+		}},
+	}
+	c := newLinkChecker(dir)
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := c.check(filepath.Join(dir, tt.file), tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected:\n%v\ngot:\n%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBrokenLinkString(t *testing.T) {
+	l := brokenLink{file: "docs/a.md", line: 12, message: "broken link: b.md does not exist"}
+	if want := "docs/a.md:12: broken link: b.md does not exist"; l.String() != want {
+		t.Errorf("expected %q, got %q", want, l.String())
+	}
+}
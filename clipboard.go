@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+var (
+	// readClipboard reads the system clipboard. It's a variable so tests can
+	// replace it.
+	readClipboard = clipboard.ReadAll
+
+	// clipboardUnsupported reports whether no clipboard utility is available.
+	clipboardUnsupported = func() bool { return clipboard.Unsupported }
+
+	errNoClipboard = errors.New("no clipboard utility available: install xclip, xsel or wl-clipboard")
+)
+
+// clipboardSource creates a readable source from the contents of the system
+// clipboard.
+func clipboardSource() (*source, error) {
+	if clipboardUnsupported() {
+		return nil, errNoClipboard
+	}
+	s, err := readClipboard()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read clipboard: %w", err)
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("clipboard is empty")
+	}
+	return &source{reader: io.NopCloser(strings.NewReader(s))}, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestClipboardSource(t *testing.T) {
+	savedRead, savedUnsupported := readClipboard, clipboardUnsupported
+	t.Cleanup(func() {
+		readClipboard, clipboardUnsupported = savedRead, savedUnsupported
+	})
+
+	t.Run("reads clipboard contents", func(t *testing.T) {
+		clipboardUnsupported = func() bool { return false }
+		readClipboard = func() (string, error) { return "# From clipboard", nil }
+
+		src, err := clipboardSource()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(src.reader)
+		if string(b) != "# From clipboard" {
+			t.Errorf("expected clipboard contents, got %q", b)
+		}
+		if src.URL != "" {
+			t.Errorf("expected empty URL, got %q", src.URL)
+		}
+	})
+
+	t.Run("no clipboard utility", func(t *testing.T) {
+		clipboardUnsupported = func() bool { return true }
+
+		if _, err := clipboardSource(); !errors.Is(err, errNoClipboard) {
+			t.Errorf("expected errNoClipboard, got %v", err)
+		}
+	})
+
+	t.Run("empty clipboard", func(t *testing.T) {
+		clipboardUnsupported = func() bool { return false }
+		readClipboard = func() (string, error) { return " \n", nil }
+
+		if _, err := clipboardSource(); err == nil {
+			t.Error("expected error for empty clipboard")
+		}
+	})
+}
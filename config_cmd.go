@@ -23,6 +23,14 @@ pager: false
 width: 80
 # show all files, including hidden and ignored.
 all: false
+# show front matter as a table above documents
+frontMatter: true
+# list CSV and TSV files in the file listing (TUI-mode only)
+showTables: false
+# timeout for fetching remote documents
+timeout: 30s
+# PEM bundle of additional certificate authorities to trust
+# caFile: ~/certs/corporate.pem
 `
 
 var configCmd = &cobra.Command{
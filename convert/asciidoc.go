@@ -0,0 +1,675 @@
+package convert
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AsciiDoc converts an AsciiDoc document into markdown. Sections, lists,
+// delimited blocks, admonitions, tables, links, images and inline markup
+// are converted; attribute references are resolved.
+func AsciiDoc(b []byte) (string, error) {
+	c := adocConverter{
+		attributes: map[string]string{
+			"nbsp": " ", "sp": " ", "empty": "", "plus": "+", "amp": "&",
+			"lt": "<", "gt": ">", "startsb": "[", "endsb": "]",
+			"vbar": "|", "caret": "^", "asterisk": "*", "tilde": "~",
+			"backslash": "\\", "backtick": "`", "two-colons": "::",
+		},
+	}
+	return strings.Join(c.blocks(splitLines(string(b), 4)), "\n\n") + "\n", nil
+}
+
+type adocConverter struct {
+	attributes map[string]string
+
+	// block attributes and title, which apply to the next block
+	blockAttrs []string
+	blockTitle string
+}
+
+var (
+	adocAttributeEntry = regexp.MustCompile(`^:(!?)([\w][\w-]*)(!?):\s*(.*)$`)
+	adocPreprocessor   = regexp.MustCompile(`^(ifdef|ifndef|ifeval|endif|include)::.*\[.*\]$`)
+	adocBlockAttrs     = regexp.MustCompile(`^\[([^\[\]]*)\]$`)
+	adocAnchor         = regexp.MustCompile(`^\[\[[^\]]*\]\]$`)
+	adocBlockTitle     = regexp.MustCompile(`^\.([^.\s].*)$`)
+	adocHeading        = regexp.MustCompile(`^(={1,6}|#{1,6}) +(.+?)(?: +=+)?$`)
+	adocBreak          = regexp.MustCompile(`^('{3,}|-{3}|\*{3}|_{3})$`)
+	adocDelimiter      = regexp.MustCompile("^(-{4,}|\\.{4,}|={4,}|_{4,}|\\*{4,}|\\+{4,}|/{4,}|--|```.*)$")
+	adocTable          = regexp.MustCompile(`^[|,:!]={3,}$`)
+	adocAdmonitionPara = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION): +(.*)$`)
+	adocListItem       = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5}|\d+\.) +(.*)$`)
+	adocDescription    = regexp.MustCompile(`^(\S.*?)(:{2,4}|;;)(?: +(.*))?$`)
+	adocCallout        = regexp.MustCompile(`\s*(?://|#)?\s*<\d+>$`)
+	adocBlockMacro     = regexp.MustCompile(`^(\w+)::(\S*)\[(.*)\]$`)
+
+	adocAttrRef     = regexp.MustCompile(`\{([\w][\w-]*)\}`)
+	adocURL         = regexp.MustCompile(`(?:link:)?((?:https?|ftp|irc)://[^\s\[\]]+|mailto:[^\s\[\]]+)\[([^\]]*)\]`)
+	adocLink        = regexp.MustCompile(`link:([^\s\[\]]+)\[([^\]]*)\]`)
+	adocXref        = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]+))?>>`)
+	adocXrefMacro   = regexp.MustCompile(`xref:([^\s\[\]]+)\[([^\]]*)\]`)
+	adocImage       = regexp.MustCompile(`image:([^:\s\[\]][^\s\[\]]*)\[([^\]]*)\]`)
+	adocKbd         = regexp.MustCompile(`kbd:\[([^\]]*)\]`)
+	adocButton      = regexp.MustCompile(`btn:\[([^\]]*)\]`)
+	adocMenu        = regexp.MustCompile(`menu:([^\[\s]+)\[([^\]]*)\]`)
+	adocFootnote    = regexp.MustCompile(`footnote:[\w-]*\[([^\]]*)\]`)
+	adocStrong      = regexp.MustCompile(`(^|[^\w*\\])\*([^*\s](?:[^*]*[^*\s])?)\*($|[^\w*])`)
+	adocEmphasis    = regexp.MustCompile(`__(.+?)__`)
+	adocHighlight   = regexp.MustCompile(`(^|[^\w#\\])#([^#\s](?:[^#]*[^#\s])?)#($|[^\w#])`)
+	adocSuperscript = regexp.MustCompile(`\^([^\s^]+)\^`)
+	adocSubscript   = regexp.MustCompile(`~([^\s~]+)~`)
+	adocPassthrough = regexp.MustCompile(`\+\+?([^+]+)\+?\+`)
+)
+
+var adocAdmonitionLabels = map[string]string{
+	"NOTE": "Note", "TIP": "Tip", "IMPORTANT": "Important",
+	"WARNING": "Warning", "CAUTION": "Caution",
+}
+
+func (c *adocConverter) blocks(lines []string) []string {
+	var out []string
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		if c.blockTitle != "" {
+			out = append(out, "**"+c.inline(c.blockTitle)+"**")
+		}
+		out = append(out, s)
+		c.blockAttrs, c.blockTitle = nil, ""
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		// comments
+		case strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "////"):
+			i++
+
+		case adocAttributeEntry.MatchString(line):
+			m := adocAttributeEntry.FindStringSubmatch(line)
+			if m[1] != "" || m[3] != "" {
+				delete(c.attributes, m[2])
+			} else {
+				c.attributes[m[2]] = m[4]
+			}
+			i++
+
+		case adocPreprocessor.MatchString(line), adocAnchor.MatchString(line), line == "<<<":
+			i++
+
+		case adocBlockAttrs.MatchString(line):
+			c.blockAttrs = splitAttrs(adocBlockAttrs.FindStringSubmatch(line)[1])
+			i++
+
+		case adocBlockTitle.MatchString(line):
+			c.blockTitle = adocBlockTitle.FindStringSubmatch(line)[1]
+			i++
+
+		case adocHeading.MatchString(line):
+			m := adocHeading.FindStringSubmatch(line)
+			c.blockAttrs, c.blockTitle = nil, ""
+			add(strings.Repeat("#", len(m[1])) + " " + c.inline(m[2]))
+			i++
+
+		case adocBreak.MatchString(line):
+			add("---")
+			i++
+
+		case adocDelimiter.MatchString(line):
+			end := i + 1
+			delim := line
+			if strings.HasPrefix(delim, "```") {
+				delim = "```"
+			}
+			for end < len(lines) && lines[end] != delim {
+				end++
+			}
+			add(c.delimitedBlock(line, lines[i+1:end]))
+			i = end + 1
+
+		case adocTable.MatchString(line):
+			end := i + 1
+			for end < len(lines) && lines[end] != line {
+				end++
+			}
+			add(c.table(line[:1], lines[i+1:min(end, len(lines))]))
+			i = end + 1
+
+		case adocBlockMacro.MatchString(line):
+			m := adocBlockMacro.FindStringSubmatch(line)
+			if m[1] == "image" {
+				alt, _, _ := strings.Cut(m[3], ",")
+				add("![" + alt + "](" + linkDestination(m[2]) + ")")
+			}
+			// other block macros, like toc::[] or video::, are skipped
+			i++
+
+		case adocAdmonitionPara.MatchString(line):
+			m := adocAdmonitionPara.FindStringSubmatch(line)
+			j := paragraphEnd(lines, i)
+			text := strings.Join(append([]string{m[2]}, lines[i+1:j]...), "\n")
+			add(blockquote("**" + adocAdmonitionLabels[m[1]] + ":** " + c.paragraph(text)))
+			i = j
+
+		case adocListItem.MatchString(line) || isDescriptionItem(line):
+			var list string
+			list, i = c.list(lines, i)
+			add(list)
+
+		// literal paragraphs are indented
+		case indentOf(line) > 0:
+			j := paragraphEnd(lines, i)
+			add(codeFence(strings.Join(dedent(lines[i:j]), "\n"), ""))
+			i = j
+
+		default:
+			j := paragraphEnd(lines, i)
+			add(c.styledParagraph(strings.Join(lines[i:j], "\n")))
+			i = j
+		}
+	}
+	return out
+}
+
+// paragraphEnd returns the index of the line following the paragraph
+// starting at lines[i].
+func paragraphEnd(lines []string, i int) int {
+	j := i + 1
+	for j < len(lines) && !isBlank(lines[j]) && !adocDelimiter.MatchString(lines[j]) &&
+		!adocBlockAttrs.MatchString(lines[j]) {
+		j++
+	}
+	return j
+}
+
+// splitAttrs splits a block attribute list like `source,go,opts=linenums`.
+// Commas in quoted values, like `cols="1,2"`, don't separate attributes.
+func splitAttrs(s string) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, s[start:])
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"`)
+	}
+	return parts
+}
+
+// style returns the block style given by the first block attribute, like
+// "source" or "NOTE".
+func (c *adocConverter) style() string {
+	if len(c.blockAttrs) == 0 {
+		return ""
+	}
+	// shorthand roles and options like "source%linenums" or "#id"
+	style, _, _ := strings.Cut(c.blockAttrs[0], "%")
+	style, _, _ = strings.Cut(style, "#")
+	style, _, _ = strings.Cut(style, ".")
+	return style
+}
+
+func (c *adocConverter) attr(i int) string {
+	if i < len(c.blockAttrs) {
+		return c.blockAttrs[i]
+	}
+	return ""
+}
+
+// namedAttr returns a named block attribute, like cols="1,2".
+func (c *adocConverter) namedAttr(name string) string {
+	for _, a := range c.blockAttrs {
+		if k, v, ok := strings.Cut(a, "="); ok && strings.TrimSpace(k) == name {
+			return strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return ""
+}
+
+// styledParagraph converts a paragraph, taking its block style into
+// account.
+func (c *adocConverter) styledParagraph(text string) string {
+	style := c.style()
+	switch {
+	case adocAdmonitionLabels[style] != "":
+		return blockquote("**" + adocAdmonitionLabels[style] + ":** " + c.paragraph(text))
+	case style == "source" || style == "listing":
+		return codeFence(adocCallout.ReplaceAllString(text, ""), c.sourceLanguage())
+	case style == "literal":
+		return codeFence(text, "")
+	case style == "quote" || style == "verse":
+		return c.quote(c.paragraph(text))
+	}
+	return c.paragraph(text)
+}
+
+// paragraph converts the inline markup of a paragraph. Lines ending with
+// " +" are hard line breaks.
+func (c *adocConverter) paragraph(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if hard := strings.HasSuffix(l, " +"); hard {
+			lines[i] = c.inline(strings.TrimSuffix(l, " +")) + "\\"
+		} else {
+			lines[i] = c.inline(l)
+		}
+	}
+	return strings.TrimSuffix(strings.Join(lines, "\n"), "\\")
+}
+
+func (c *adocConverter) sourceLanguage() string {
+	if lang := c.attr(1); lang != "" && !strings.Contains(lang, "=") {
+		return lang
+	}
+	if lang := c.namedAttr("language"); lang != "" {
+		return lang
+	}
+	return c.attributes["source-language"]
+}
+
+// quote turns markdown into a block quote, with the attribution given in the
+// block attributes.
+func (c *adocConverter) quote(md string) string {
+	var attribution []string
+	for _, a := range []string{c.attr(1), c.attr(2)} {
+		if a != "" {
+			attribution = append(attribution, c.inline(a))
+		}
+	}
+	if len(attribution) > 0 {
+		md += "\n\n— " + strings.Join(attribution, ", ")
+	}
+	return blockquote(md)
+}
+
+func (c *adocConverter) delimitedBlock(delim string, content []string) string {
+	// the block attributes apply to this block, not to blocks nested in it
+	style := c.style()
+	attrs := c.blockAttrs
+	c.blockAttrs = nil
+	defer func() { c.blockAttrs = attrs }()
+
+	nested := func() string {
+		title := c.blockTitle
+		c.blockTitle = ""
+		defer func() { c.blockTitle = title }()
+		return strings.Join(c.blocks(content), "\n\n")
+	}
+
+	switch {
+	case strings.HasPrefix(delim, "```"):
+		return codeFence(strings.Join(content, "\n"), strings.TrimSpace(strings.TrimPrefix(delim, "```")))
+
+	case delim[0] == '-' && delim != "--":
+		code := strings.Join(content, "\n")
+		c.blockAttrs = attrs
+		if style == "source" || (style == "" && len(attrs) > 1) {
+			return codeFence(adocCallout.ReplaceAllString(code, ""), c.sourceLanguage())
+		}
+		return codeFence(code, "")
+
+	case delim[0] == '.':
+		return codeFence(strings.Join(content, "\n"), "")
+
+	case delim[0] == '/':
+		return ""
+
+	case delim[0] == '+':
+		md, _ := HTML([]byte(strings.Join(content, "\n")))
+		return strings.TrimSpace(md)
+	}
+
+	inner := nested()
+	switch {
+	case adocAdmonitionLabels[style] != "":
+		return blockquote("**" + adocAdmonitionLabels[style] + ":** " + inner)
+	case delim[0] == '_' || style == "quote" || style == "verse":
+		c.blockAttrs = attrs
+		return c.quote(inner)
+	case delim[0] == '*':
+		return blockquote(inner)
+	}
+	return inner
+}
+
+// table converts a table. Cells start with a separator, which is "|" for
+// regular tables and "," or ":" for CSV and DSV tables.
+func (c *adocConverter) table(sep string, lines []string) string {
+	// "!" is the separator of nested tables
+	if sep != "," && sep != ":" && sep != "!" {
+		sep = "|"
+	}
+
+	var cells []string
+	firstLineCells := 0
+	implicitHeader := false
+	for n, l := range lines {
+		if isBlank(l) {
+			if n > 0 && firstLineCells > 0 && cells != nil && n == firstNonBlank(lines)+1 {
+				implicitHeader = true
+			}
+			continue
+		}
+
+		var parts []string
+		if sep == "|" || sep == "!" {
+			parts = strings.Split(l, sep)
+			// text before the first separator continues the previous cell
+			if before := strings.TrimSpace(parts[0]); before != "" && len(cells) > 0 {
+				cells[len(cells)-1] += " " + before
+			}
+			parts = parts[1:]
+		} else {
+			parts = strings.Split(l, sep)
+		}
+		for _, p := range parts {
+			cells = append(cells, strings.TrimSpace(p))
+		}
+		if firstLineCells == 0 {
+			firstLineCells = len(parts)
+		}
+	}
+
+	cols := firstLineCells
+	if spec := c.namedAttr("cols"); spec != "" {
+		if n, err := strconv.Atoi(spec); err == nil {
+			cols = n
+		} else {
+			cols = len(strings.Split(spec, ","))
+		}
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	var rows [][]string
+	for len(cells) > 0 {
+		n := min(cols, len(cells))
+		row := make([]string, n)
+		for i, cell := range cells[:n] {
+			row[i] = c.inline(cell)
+		}
+		rows = append(rows, row)
+		cells = cells[n:]
+	}
+
+	header := implicitHeader
+	for _, a := range c.blockAttrs {
+		if strings.Contains(a, "%header") || strings.Contains(a, "header") && strings.HasPrefix(a, "options") {
+			header = true
+		}
+	}
+	if header && len(rows) > 0 {
+		return markdownTable(rows[0], rows[1:])
+	}
+	return markdownTable(nil, rows)
+}
+
+func firstNonBlank(lines []string) int {
+	for i, l := range lines {
+		if !isBlank(l) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isDescriptionItem(line string) bool {
+	m := adocDescription.FindStringSubmatch(line)
+	// avoid mistaking things like "C:: drive" or URLs for terms
+	return m != nil && !strings.Contains(m[1], "://") && !strings.HasSuffix(m[1], ":") &&
+		!adocBlockMacro.MatchString(line)
+}
+
+// list converts a list starting at lines[i]. Nesting is determined by the
+// markers: a marker which isn't used by an enclosing list starts a nested
+// list. It returns the list and the index of the line following it.
+func (c *adocConverter) list(lines []string, i int) (string, int) {
+	var (
+		stack  []string // markers of the enclosing lists
+		indent []int    // indentation of the enclosing lists' content
+		nums   []int    // item numbers of ordered lists
+		items  []string
+	)
+
+	for i < len(lines) {
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j >= len(lines) {
+			break
+		}
+		line := strings.TrimSpace(lines[j])
+
+		var marker, text string
+		switch {
+		case adocListItem.MatchString(line):
+			m := adocListItem.FindStringSubmatch(line)
+			marker, text = m[1], m[2]
+			if _, err := strconv.Atoi(strings.TrimSuffix(marker, ".")); err == nil {
+				marker = "."
+			}
+		case isDescriptionItem(line):
+			m := adocDescription.FindStringSubmatch(line)
+			marker, text = m[2], "**"+m[1]+":**"
+			if m[3] != "" {
+				text += " " + m[3]
+			}
+		default:
+			return strings.Join(items, "\n"), i
+		}
+
+		// find the list the item belongs to
+		level := -1
+		for k, s := range stack {
+			if s == marker {
+				level = k
+			}
+		}
+		if level < 0 && j > i && len(stack) > 0 {
+			// a different kind of list after a blank line starts a new list
+			return strings.Join(items, "\n"), i
+		}
+		if level < 0 {
+			stack = append(stack, marker)
+			prev := 0
+			if len(indent) > 0 {
+				prev = indent[len(indent)-1]
+			}
+			width := 2
+			if marker[0] == '.' {
+				width = 3
+			}
+			indent = append(indent, prev+width)
+			nums = append(nums, 1)
+			level = len(stack) - 1
+		}
+		stack, indent, nums = stack[:level+1], indent[:level+1], nums[:level+1]
+
+		// continuation lines and attached blocks
+		i = j + 1
+		body := []string{text}
+		for i < len(lines) && !isBlank(lines[i]) && !adocListItem.MatchString(strings.TrimSpace(lines[i])) &&
+			!isDescriptionItem(strings.TrimSpace(lines[i])) {
+			if lines[i] == "+" {
+				var block []string
+				block, i = attachedBlock(lines, i+1)
+				body = append(body, "")
+				body = append(body, block...)
+				continue
+			}
+			body = append(body, strings.TrimSpace(lines[i]))
+			i++
+		}
+
+		md := "- "
+		if marker[0] == '.' {
+			md = strconv.Itoa(nums[level]) + ". "
+			nums[level]++
+		}
+		if strings.HasPrefix(body[0], "[*] ") {
+			body[0] = "[x]" + body[0][3:]
+		}
+
+		content := c.itemContent(body)
+		prefix := 0
+		if level > 0 {
+			prefix = indent[level-1]
+		}
+		items = append(items, indentLines(listItem(md, content), strings.Repeat(" ", prefix)))
+	}
+	return strings.Join(items, "\n"), i
+}
+
+// itemContent converts a list item's lines: its text, and the blocks
+// attached to it.
+func (c *adocConverter) itemContent(body []string) string {
+	var text []string
+	k := 0
+	for ; k < len(body) && body[k] != ""; k++ {
+		text = append(text, body[k])
+	}
+	md := c.paragraph(strings.Join(text, "\n"))
+	if k < len(body) {
+		if blocks := c.blocks(body[k:]); len(blocks) > 0 {
+			md += "\n\n" + strings.Join(blocks, "\n\n")
+		}
+	}
+	return md
+}
+
+// attachedBlock returns the block attached to a list item with a "+" line,
+// and the index of the line following it.
+func attachedBlock(lines []string, i int) ([]string, int) {
+	start := i
+	for i < len(lines) && (adocBlockAttrs.MatchString(lines[i]) || adocBlockTitle.MatchString(lines[i])) {
+		i++
+	}
+	if i < len(lines) && adocDelimiter.MatchString(lines[i]) {
+		delim := lines[i]
+		i++
+		for i < len(lines) && lines[i] != delim {
+			i++
+		}
+		end := min(i+1, len(lines))
+		return lines[start:end], end
+	}
+	for i < len(lines) && !isBlank(lines[i]) && lines[i] != "+" &&
+		!adocListItem.MatchString(strings.TrimSpace(lines[i])) {
+		i++
+	}
+	return lines[start:i], i
+}
+
+// inline converts AsciiDoc inline markup. Code spans are converted first, so
+// their contents aren't touched.
+func (c *adocConverter) inline(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '`')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+1:], '`')
+		if end < 0 {
+			break
+		}
+		b.WriteString(c.inlineMarkup(s[:start]))
+		code := s[start+1 : start+1+end]
+		if strings.HasPrefix(code, "+") && strings.HasSuffix(code, "+") && len(code) > 1 {
+			code = code[1 : len(code)-1]
+		}
+		b.WriteString(codeSpan(code))
+		s = s[start+1+end+1:]
+	}
+	b.WriteString(c.inlineMarkup(s))
+	return b.String()
+}
+
+func (c *adocConverter) inlineMarkup(s string) string {
+	s = adocAttrRef.ReplaceAllStringFunc(s, func(match string) string {
+		if v, ok := c.attributes[match[1:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+
+	s = adocImage.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocImage.FindStringSubmatch(match)
+		alt, _, _ := strings.Cut(m[2], ",")
+		return "![" + alt + "](" + linkDestination(m[1]) + ")"
+	})
+	s = adocURL.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocURL.FindStringSubmatch(match)
+		return markdownLink(m[2], m[1])
+	})
+	s = adocLink.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocLink.FindStringSubmatch(match)
+		return markdownLink(m[2], m[1])
+	})
+	s = adocXref.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocXref.FindStringSubmatch(match)
+		if m[2] != "" {
+			return m[2]
+		}
+		return m[1]
+	})
+	s = adocXrefMacro.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocXrefMacro.FindStringSubmatch(match)
+		if m[2] != "" {
+			return m[2]
+		}
+		return m[1]
+	})
+
+	s = adocKbd.ReplaceAllStringFunc(s, func(match string) string {
+		return codeSpan(adocKbd.FindStringSubmatch(match)[1])
+	})
+	s = adocButton.ReplaceAllString(s, "**$1**")
+	s = adocMenu.ReplaceAllStringFunc(s, func(match string) string {
+		m := adocMenu.FindStringSubmatch(match)
+		items := append([]string{m[1]}, strings.Split(m[2], ">")...)
+		for i, item := range items {
+			items[i] = strings.TrimSpace(item)
+		}
+		return "**" + strings.Join(items, " › ") + "**"
+	})
+	s = adocFootnote.ReplaceAllString(s, " ($1)")
+
+	s = adocPassthrough.ReplaceAllString(s, "$1")
+	s = adocStrong.ReplaceAllString(s, "$1**$2**$3")
+	s = adocEmphasis.ReplaceAllString(s, "*$1*")
+	s = adocHighlight.ReplaceAllString(s, "$1$2$3")
+	s = adocSuperscript.ReplaceAllString(s, "$1")
+	return adocSubscript.ReplaceAllString(s, "$1")
+}
+
+// markdownLink builds a link from AsciiDoc link text, which may contain
+// attributes like a window target.
+func markdownLink(text, url string) string {
+	text, _, _ = strings.Cut(text, ",")
+	text = strings.Trim(strings.TrimSuffix(strings.TrimSpace(text), "^"), `"`)
+	if text == "" {
+		return url
+	}
+	return "[" + text + "](" + linkDestination(url) + ")"
+}
@@ -0,0 +1,114 @@
+package convert
+
+import "testing"
+
+func TestAsciiDoc(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "section titles",
+			in:   "= Title\n\n== Section\n\n=== Sub\n",
+			want: "# Title\n\n## Section\n\n### Sub\n",
+		},
+		{
+			name: "inline markup",
+			in:   "Some *strong*, _emphasis_, `code` and #marked# text.\n",
+			want: "Some **strong**, _emphasis_, `code` and marked text.\n",
+		},
+		{
+			name: "attributes",
+			in:   ":name: Glow\n:url: https://example.com\n\n{name} lives at {url}[home].\n",
+			want: "Glow lives at [home](https://example.com).\n",
+		},
+		{
+			name: "links and cross references",
+			in:   "See link:docs/a.html[the docs], <<setup,Setup>> and xref:b.adoc[B].\n",
+			want: "See [the docs](docs/a.html), Setup and B.\n",
+		},
+		{
+			name: "source block",
+			in:   "[source,go]\n----\nfmt.Println(1) // <1>\n----\n",
+			want: "```go\nfmt.Println(1)\n```\n",
+		},
+		{
+			name: "block title",
+			in:   ".Output\n....\nhello\n....\n",
+			want: "**Output**\n\n```\nhello\n```\n",
+		},
+		{
+			name: "admonitions",
+			in:   "TIP: Use it.\n\n[WARNING]\n====\nCareful.\n====\n",
+			want: "> **Tip:** Use it.\n\n> **Warning:** Careful.\n",
+		},
+		{
+			name: "nested lists",
+			in:   "* one\n** nested\n* two\n\n. first\n.. sub\n. second\n",
+			want: "- one\n  - nested\n- two\n\n1. first\n   1. sub\n2. second\n",
+		},
+		{
+			name: "empty list item",
+			in:   "* {empty}\n* two\n",
+			want: "-\n- two\n",
+		},
+		{
+			name: "checklist",
+			in:   "* [x] done\n* [ ] todo\n",
+			want: "- [x] done\n- [ ] todo\n",
+		},
+		{
+			name: "list continuation",
+			in:   "* item\n+\n----\ncode\n----\n",
+			want: "- item\n\n  ```\n  code\n  ```\n",
+		},
+		{
+			name: "description list",
+			in:   "CPU:: The brain\nRAM:: Memory\n",
+			want: "- **CPU:** The brain\n- **RAM:** Memory\n",
+		},
+		{
+			name: "table with implicit header",
+			in:   "|===\n|Name |Value\n\n|a |1\n|===\n",
+			want: "| Name | Value |\n| --- | --- |\n| a | 1 |\n",
+		},
+		{
+			name: "table with one cell per line",
+			in:   "[cols=\"1,1\",options=\"header\"]\n|===\n|Name\n|Value\n|a\n|1\n|===\n",
+			want: "| Name | Value |\n| --- | --- |\n| a | 1 |\n",
+		},
+		{
+			name: "quote",
+			in:   "[quote, Someone]\n____\nWords.\n____\n",
+			want: "> Words.\n>\n> — Someone\n",
+		},
+		{
+			name: "comments and breaks",
+			in:   "// comment\n////\nhidden\n////\nText.\n\n'''\n",
+			want: "Text.\n\n---\n",
+		},
+		{
+			name: "hard line breaks",
+			in:   "one +\ntwo\n",
+			want: "one\\\ntwo\n",
+		},
+		{
+			name: "images",
+			in:   "image::logo.png[Logo, 200]\n",
+			want: "![Logo](logo.png)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AsciiDoc([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("AsciiDoc() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AsciiDoc() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package convert turns documents in other formats into markdown, so they
+// can be rendered with glamour like any other markdown document.
+package convert
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Converter converts the contents of a document into markdown.
+type Converter func(b []byte) (string, error)
+
+// format describes a document format we can convert into markdown.
+type format struct {
+	name         string
+	extensions   []string
+	contentTypes []string
+	// match recognizes files by name when their extension varies, like
+	// the section suffix of man pages
+	match   func(filename string) bool
+	convert Converter
+}
+
+var formats = []format{
+	{
+		name:         "HTML",
+		extensions:   []string{".html", ".htm", ".xhtml"},
+		contentTypes: []string{"text/html", "application/xhtml+xml"},
+		convert:      HTML,
+	},
+	{
+		name:         "reStructuredText",
+		extensions:   []string{".rst", ".rest"},
+		contentTypes: []string{"text/x-rst", "text/prs.fallenstein.rst"},
+		convert:      RST,
+	},
+	{
+		name:         "AsciiDoc",
+		extensions:   []string{".adoc", ".asciidoc"},
+		contentTypes: []string{"text/asciidoc", "text/x-asciidoc"},
+		convert:      AsciiDoc,
+	},
+	{
+		name:         "Org",
+		extensions:   []string{".org"},
+		contentTypes: []string{"text/org", "text/x-org"},
+		convert:      Org,
+	},
+	{
+		name:         "Word",
+		extensions:   []string{".docx"},
+		contentTypes: []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		convert:      DOCX,
+	},
+	{
+		name:         "OpenDocument",
+		extensions:   []string{".odt"},
+		contentTypes: []string{"application/vnd.oasis.opendocument.text"},
+		convert:      ODT,
+	},
+	{
+		name:         "Mermaid",
+		extensions:   []string{".mmd", ".mermaid"},
+		contentTypes: []string{"text/vnd.mermaid"},
+		convert:      Mermaid,
+	},
+	{
+		name:         "Man page",
+		contentTypes: []string{"text/troff", "application/x-troff-man", "text/x-troff-man"},
+		match:        isManPage,
+		convert:      Roff,
+	},
+}
+
+func formatForFile(filename string) *format {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return nil
+	}
+	for i, f := range formats {
+		for _, v := range f.extensions {
+			if strings.EqualFold(ext, v) {
+				return &formats[i]
+			}
+		}
+		if f.match != nil && f.match(filename) {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// ForFile returns the converter for a file based on its extension, or nil if
+// the file doesn't need to be converted.
+func ForFile(filename string) Converter {
+	if f := formatForFile(filename); f != nil {
+		return f.convert
+	}
+	return nil
+}
+
+// FormatName returns the name of a file's format, like "HTML", or an empty
+// string if the file doesn't need to be converted.
+func FormatName(filename string) string {
+	if f := formatForFile(filename); f != nil {
+		return f.name
+	}
+	return ""
+}
+
+// ForContentType returns the converter for a MIME type, such as the
+// Content-Type of an HTTP response, or nil if there is none.
+func ForContentType(contentType string) Converter {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	for _, f := range formats {
+		for _, v := range f.contentTypes {
+			if strings.EqualFold(mediaType, v) {
+				return f.convert
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package convert
+
+import "testing"
+
+func TestForFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     bool
+	}{
+		{"index.html", true},
+		{"INDEX.HTM", true},
+		{"page.xhtml", true},
+		{"guide.rst", true},
+		{"guide.adoc", true},
+		{"guide.asciidoc", true},
+		{"spec.docx", true},
+		{"spec.odt", true},
+		{"ls.1", true},
+		{"printf.3.gz", true},
+		{"login.mermaid", true},
+		{"README.md", false},
+		{"main.go", false},
+		{"Makefile", false},
+		{"KEYS.asc", false},
+	}
+	for _, tt := range tests {
+		if got := ForFile(tt.filename) != nil; got != tt.want {
+			t.Errorf("ForFile(%q) != nil = %v, want %v", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"text/html", true},
+		{"text/html; charset=utf-8", true},
+		{"application/xhtml+xml", true},
+		{"text/x-rst", true},
+		{"text/asciidoc", true},
+		{"text/troff", true},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", true},
+		{"text/plain; charset=utf-8", false},
+		{"text/markdown", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ForContentType(tt.contentType) != nil; got != tt.want {
+			t.Errorf("ForContentType(%q) != nil = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestFormatName(t *testing.T) {
+	tests := map[string]string{
+		"index.html": "HTML",
+		"guide.RST":  "reStructuredText",
+		"guide.adoc": "AsciiDoc",
+		"spec.docx":  "Word",
+		"spec.odt":   "OpenDocument",
+		"ls.1.gz":    "Man page",
+		"flow.mmd":   "Mermaid",
+		"README.md":  "",
+		"Makefile":   "",
+	}
+	for filename, want := range tests {
+		if got := FormatName(filename); got != want {
+			t.Errorf("FormatName(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package convert
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DOCX converts a Word document into markdown. Paragraphs, headings, lists,
+// tables, links and bold, italic and struck out text are converted.
+func DOCX(b []byte) (string, error) {
+	zr, err := openArchive(b)
+	if err != nil {
+		return "", err
+	}
+	doc, err := readArchiveXML(zr, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	c := docxConverter{
+		styles:     map[string]docxStyle{},
+		numFormats: map[string][]string{},
+		links:      map[string]string{},
+	}
+	// styles, numbering and links are optional
+	if n, err := readArchiveXML(zr, "word/styles.xml"); err == nil {
+		c.readStyles(n)
+	} else if !errors.Is(err, errMissingFile) {
+		return "", err
+	}
+	if n, err := readArchiveXML(zr, "word/numbering.xml"); err == nil {
+		c.readNumbering(n)
+	} else if !errors.Is(err, errMissingFile) {
+		return "", err
+	}
+	if n, err := readArchiveXML(zr, "word/_rels/document.xml.rels"); err == nil {
+		c.readRelationships(n)
+	} else if !errors.Is(err, errMissingFile) {
+		return "", err
+	}
+
+	body := doc.path("document", "body")
+	if body == nil {
+		return "", errors.New("unable to read document: no document body")
+	}
+	return joinOfficeBlocks(c.blocks(body)), nil
+}
+
+type docxConverter struct {
+	// paragraph and character styles by id
+	styles map[string]docxStyle
+	// number formats of the list levels, by numbering id
+	numFormats map[string][]string
+	// link targets by relationship id
+	links map[string]string
+}
+
+type docxStyle struct {
+	name string
+	// heading level given by the style's outline level
+	outlineLevel int
+	// numbering of list styles
+	numID string
+}
+
+// docxCodeFonts are monospace fonts, text set in them is shown as code.
+var docxCodeFonts = []string{"courier", "consolas", "menlo", "monaco", "mono", "source code"}
+
+func (c *docxConverter) readStyles(root *xmlNode) {
+	for _, s := range root.all("style") {
+		style := docxStyle{name: s.path("name").attr("val")}
+		if lvl := s.path("pPr", "outlineLvl"); lvl != nil {
+			if v, err := strconv.Atoi(lvl.attr("val")); err == nil && v < 6 {
+				style.outlineLevel = v + 1
+			}
+		}
+		style.numID = s.path("pPr", "numPr", "numId").attr("val")
+		c.styles[s.attr("styleId")] = style
+	}
+}
+
+func (c *docxConverter) readNumbering(root *xmlNode) {
+	abstract := map[string][]string{}
+	for _, a := range root.all("abstractNum") {
+		var formats []string
+		for _, lvl := range a.all("lvl") {
+			i, err := strconv.Atoi(lvl.attr("ilvl"))
+			if err != nil || i < 0 || i > 8 {
+				continue
+			}
+			for len(formats) <= i {
+				formats = append(formats, "")
+			}
+			formats[i] = lvl.path("numFmt").attr("val")
+		}
+		abstract[a.attr("abstractNumId")] = formats
+	}
+	for _, n := range root.all("num") {
+		c.numFormats[n.attr("numId")] = abstract[n.path("abstractNumId").attr("val")]
+	}
+}
+
+func (c *docxConverter) readRelationships(root *xmlNode) {
+	for _, r := range root.all("Relationship") {
+		if strings.HasSuffix(r.attr("Type"), "/hyperlink") {
+			c.links[r.attr("Id")] = r.attr("Target")
+		}
+	}
+}
+
+func (c *docxConverter) blocks(n *xmlNode) []officeBlock {
+	var blocks []officeBlock
+	for _, child := range n.children {
+		switch child.name {
+		case "p":
+			blocks = append(blocks, c.paragraph(child))
+		case "tbl":
+			blocks = append(blocks, officeBlock{markdown: c.table(child)})
+		case "sdt", "sdtContent", "customXml", "ins":
+			// content controls and tracked insertions
+			blocks = append(blocks, c.blocks(child)...)
+		}
+	}
+	return blocks
+}
+
+func (c *docxConverter) paragraph(p *xmlNode) officeBlock {
+	pPr := p.child("pPr")
+	styleID := pPr.path("pStyle").attr("val")
+	style := c.styles[styleID]
+	name := style.name
+	if name == "" {
+		name = styleID
+	}
+
+	level := officeHeadingLevel(name)
+	if style.outlineLevel > 0 {
+		level = style.outlineLevel
+	}
+	if lvl := pPr.path("outlineLvl"); lvl != nil {
+		if v, err := strconv.Atoi(lvl.attr("val")); err == nil && v < 6 {
+			level = v + 1
+		}
+	}
+
+	runs := c.runs(p, textRun{})
+	if level > 0 {
+		// headings are often bold, which is redundant
+		for i := range runs {
+			runs[i].bold = false
+		}
+		if md := runsToMarkdown(runs); md != "" {
+			return officeBlock{markdown: strings.Repeat("#", level) + " " + strings.ReplaceAll(md, "\\\n", " ")}
+		}
+		return officeBlock{}
+	}
+
+	block := officeBlock{markdown: runsToMarkdown(runs), style: officeBlockStyle(name)}
+	if block.style == "code" {
+		var text strings.Builder
+		for _, r := range runs {
+			text.WriteString(r.text)
+		}
+		block.code = text.String()
+	}
+
+	// list items are numbered paragraphs
+	numID, ilvl := style.numID, "0"
+	if numPr := pPr.path("numPr"); numPr != nil {
+		if id := numPr.path("numId").attr("val"); id != "" {
+			numID = id
+		}
+		ilvl = numPr.path("ilvl").attr("val")
+	}
+	lowerName := strings.ToLower(name)
+	switch {
+	case numID != "" && numID != "0":
+		block.listItem = true
+		block.list = numID
+		block.listLevel, _ = strconv.Atoi(ilvl)
+		if formats := c.numFormats[numID]; block.listLevel < len(formats) {
+			f := formats[block.listLevel]
+			block.ordered = f != "" && f != "bullet" && f != "none"
+		}
+	case strings.HasPrefix(lowerName, "list bullet"), strings.HasPrefix(lowerName, "list number"):
+		block.listItem = true
+		block.list = lowerName
+		block.ordered = strings.HasPrefix(lowerName, "list number")
+	}
+	if block.listItem && block.markdown == "" {
+		return officeBlock{}
+	}
+	return block
+}
+
+// runs collects the formatted text of a paragraph.
+func (c *docxConverter) runs(n *xmlNode, format textRun) []textRun {
+	var runs []textRun
+	for _, child := range n.children {
+		switch child.name {
+		case "r":
+			runs = append(runs, c.run(child, format)...)
+		case "hyperlink":
+			f := format
+			if target, ok := c.links[child.attr("id")]; ok {
+				f.link = target
+			}
+			runs = append(runs, c.runs(child, f)...)
+		case "ins", "smartTag", "fldSimple", "customXml", "sdt", "sdtContent":
+			runs = append(runs, c.runs(child, format)...)
+		}
+	}
+	return runs
+}
+
+func (c *docxConverter) run(r *xmlNode, format textRun) []textRun {
+	if rPr := r.child("rPr"); rPr != nil {
+		format.bold = format.bold || docxToggle(rPr.child("b"))
+		format.italic = format.italic || docxToggle(rPr.child("i"))
+		format.strike = format.strike || docxToggle(rPr.child("strike")) || docxToggle(rPr.child("dstrike"))
+
+		style := c.styles[rPr.path("rStyle").attr("val")].name
+		font := strings.ToLower(rPr.path("rFonts").attr("ascii"))
+		switch {
+		case strings.Contains(strings.ToLower(style), "code"), strings.Contains(strings.ToLower(style), "verbatim"):
+			format.code = true
+		case font != "":
+			for _, f := range docxCodeFonts {
+				if strings.Contains(font, f) {
+					format.code = true
+				}
+			}
+		}
+	}
+
+	var runs []textRun
+	for _, child := range r.children {
+		t := format
+		switch child.name {
+		case "t":
+			t.text = child.textContent()
+		case "tab":
+			t.text = " "
+		case "br", "cr":
+			if child.attr("type") == "page" {
+				continue
+			}
+			t.text = "\n"
+		case "noBreakHyphen":
+			t.text = "-"
+		default:
+			continue
+		}
+		runs = append(runs, t)
+	}
+	return runs
+}
+
+// docxToggle returns whether a formatting property like <w:b/> is on.
+func docxToggle(n *xmlNode) bool {
+	if n == nil {
+		return false
+	}
+	switch strings.ToLower(n.attr("val")) {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+func (c *docxConverter) table(tbl *xmlNode) string {
+	var rows [][]string
+	for _, tr := range tbl.all("tr") {
+		var row []string
+		for _, tc := range tr.all("tc") {
+			var paragraphs []string
+			for _, b := range c.blocks(tc) {
+				if md := strings.TrimSpace(b.markdown); md != "" {
+					paragraphs = append(paragraphs, strings.ReplaceAll(md, "\n", " "))
+				}
+			}
+			row = append(row, strings.Join(paragraphs, " "))
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return markdownTable(rows[0], rows[1:])
+}
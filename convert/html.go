@@ -0,0 +1,490 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTML converts an HTML document into markdown. Headings, paragraphs,
+// lists, tables, links, images, code and emphasis are kept; scripts, styles
+// and other non-content elements are dropped.
+func HTML(b []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse html: %w", err)
+	}
+	return htmlToMarkdown(doc), nil
+}
+
+// HTMLBody converts an HTML document into markdown like HTML, but doesn't
+// use the document's title as a heading. It's meant for documents that are
+// part of a larger whole, like the chapters of a book.
+func HTMLBody(b []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse html: %w", err)
+	}
+	c := htmlConverter{}
+	return strings.TrimSpace(strings.Join(c.blocks(doc), "\n\n")) + "\n", nil
+}
+
+// htmlToMarkdown converts a parsed HTML node and its descendants into
+// markdown.
+func htmlToMarkdown(n *html.Node) string {
+	c := htmlConverter{}
+	body := strings.Join(c.blocks(n), "\n\n")
+
+	// Use the document title as a heading if the page doesn't have one.
+	if title := findTitle(n); title != "" && !c.hasH1 {
+		body = "# " + escapeMarkdown(title) + "\n\n" + body
+	}
+	return strings.TrimSpace(body) + "\n"
+}
+
+type htmlConverter struct {
+	hasH1 bool
+}
+
+// skippedElements are never rendered, nor are their children.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Canvas:   true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+}
+
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true,
+	atom.Blockquote: true, atom.Body: true, atom.Center: true,
+	atom.Dd: true, atom.Details: true, atom.Dialog: true, atom.Div: true,
+	atom.Dl: true, atom.Dt: true, atom.Fieldset: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true,
+	atom.Hgroup: true, atom.Hr: true, atom.Html: true, atom.Li: true,
+	atom.Main: true, atom.Nav: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Ul: true,
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockElements[n.DataAtom]
+}
+
+func skipped(n *html.Node) bool {
+	return n.Type == html.CommentNode ||
+		n.Type == html.DoctypeNode ||
+		(n.Type == html.ElementNode && skippedElements[n.DataAtom]) ||
+		(n.Type == html.ElementNode && hasAttr(n, "hidden"))
+}
+
+// blocks converts the children of n into markdown blocks. Runs of inline
+// content between block elements become paragraphs.
+func (c *htmlConverter) blocks(n *html.Node) []string {
+	var (
+		blocks []string
+		inline strings.Builder
+	)
+	flush := func() {
+		if s := cleanInline(inline.String()); s != "" {
+			blocks = append(blocks, s)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if skipped(child) {
+			continue
+		}
+		if child.Type == html.DocumentNode || isBlock(child) {
+			flush()
+			blocks = append(blocks, c.block(child)...)
+			continue
+		}
+		inline.WriteString(c.inline(child))
+	}
+	flush()
+	return blocks
+}
+
+// block converts a block element into one or more markdown blocks.
+func (c *htmlConverter) block(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		if level == 1 {
+			c.hasH1 = true
+		}
+		text := strings.ReplaceAll(cleanInline(c.inlineChildren(n)), "\n", " ")
+		if text == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", level) + " " + text}
+
+	case atom.P, atom.Dt, atom.Summary, atom.Figcaption:
+		text := cleanInline(c.inlineChildren(n))
+		if text == "" {
+			return nil
+		}
+		if n.DataAtom == atom.Dt || n.DataAtom == atom.Summary {
+			text = "**" + text + "**"
+		}
+		return []string{text}
+
+	case atom.Dd:
+		return indentBlocks(c.blocks(n), "    ")
+
+	case atom.Hr:
+		return []string{"---"}
+
+	case atom.Pre:
+		return []string{codeFence(textContent(n), codeLanguage(n))}
+
+	case atom.Blockquote:
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{blockquote(inner)}
+
+	case atom.Ul, atom.Ol:
+		if s := c.list(n); s != "" {
+			return []string{s}
+		}
+		return nil
+
+	case atom.Table:
+		if s := c.table(n); s != "" {
+			return []string{s}
+		}
+		return nil
+
+	case atom.Li:
+		// a list item outside of a list
+		return c.blocks(n)
+	}
+
+	return c.blocks(n)
+}
+
+func (c *htmlConverter) list(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	num := 1
+	if start := attr(n, "start"); start != "" {
+		_, _ = fmt.Sscanf(start, "%d", &num)
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || skipped(li) {
+			continue
+		}
+		if li.DataAtom == atom.Ul || li.DataAtom == atom.Ol {
+			// a nested list which isn't wrapped in a list item
+			if s := c.list(li); s != "" {
+				items = append(items, indentLines(s, "  "))
+			}
+			continue
+		}
+		if li.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+
+		content := joinItemBlocks(c.blocks(li))
+		if content == "" {
+			continue
+		}
+		items = append(items, listItem(marker, content))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *htmlConverter) table(n *html.Node) string {
+	var rows [][]string
+	header := false
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || skipped(child) {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode {
+						continue
+					}
+					if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+						continue
+					}
+					if cell.DataAtom == atom.Th && len(rows) == 0 {
+						header = true
+					}
+					text := cleanInline(c.inlineChildren(cell))
+					text = strings.ReplaceAll(text, "\\\n", " ")
+					text = strings.ReplaceAll(text, "\n", " ")
+					row = append(row, text)
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			case atom.Table:
+				// nested tables are flattened into their cells' text
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	if !header {
+		return markdownTable(nil, rows)
+	}
+	return markdownTable(rows[0], rows[1:])
+}
+
+func (c *htmlConverter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+// inline converts an inline node into markdown.
+func (c *htmlConverter) inline(n *html.Node) string {
+	if skipped(n) {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return escapeMarkdown(collapseSpace(n.Data))
+	}
+	if n.Type != html.ElementNode {
+		return c.inlineChildren(n)
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\\\n"
+
+	case atom.Strong, atom.B:
+		return wrapInline(c.inlineChildren(n), "**")
+
+	case atom.Em, atom.I, atom.Cite, atom.Dfn, atom.Var:
+		return wrapInline(c.inlineChildren(n), "*")
+
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(c.inlineChildren(n), "~~")
+
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return codeSpan(collapseSpace(textContent(n)))
+
+	case atom.A:
+		text := strings.TrimSpace(c.inlineChildren(n))
+		href := strings.TrimSpace(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		if text == "" {
+			text = escapeMarkdown(href)
+		}
+		return "[" + text + "](" + linkDestination(href) + ")"
+
+	case atom.Img:
+		src := strings.TrimSpace(attr(n, "src"))
+		if src == "" {
+			return ""
+		}
+		return "![" + escapeMarkdown(collapseSpace(attr(n, "alt"))) + "](" + linkDestination(src) + ")"
+
+	case atom.Sup:
+		return "^" + c.inlineChildren(n)
+	}
+
+	if isBlock(n) {
+		// block elements nested in inline content
+		return " " + c.inlineChildren(n) + " "
+	}
+	return c.inlineChildren(n)
+}
+
+// wrapInline wraps s in a markdown emphasis delimiter, keeping surrounding
+// whitespace outside of the delimiters so they stay valid.
+func wrapInline(s, delim string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + delim + trimmed + delim + trail
+}
+
+func codeSpan(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// codeLanguage returns the language of a pre element, as given by the
+// common "language-xyz" or "lang-xyz" classes on it or its code element.
+func codeLanguage(n *html.Node) string {
+	candidates := []*html.Node{n}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Code {
+			candidates = append(candidates, child)
+		}
+	}
+	for _, c := range candidates {
+		for _, class := range strings.Fields(attr(c, "class")) {
+			for _, prefix := range []string{"language-", "lang-", "highlight-source-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok {
+					return lang
+				}
+			}
+		}
+		if lang := attr(c, "data-lang"); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+func findTitle(n *html.Node) string {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Title {
+		return strings.TrimSpace(collapseSpace(textContent(n)))
+	}
+	if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+		return ""
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if t := findTitle(child); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// textContent returns the raw text of n and its descendants.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Br {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpace collapses runs of whitespace into a single space, like
+// browsers do for normal text.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+		default:
+			b.WriteRune(r)
+			space = false
+		}
+	}
+	return b.String()
+}
+
+// cleanInline tidies up converted inline content: it removes the spaces
+// left around line breaks and at the start and end of the text.
+func cleanInline(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(collapseSpace(l))
+	}
+	return strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), "\\")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+// escapeMarkdown escapes characters that would otherwise be interpreted as
+// markdown.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// linkDestination makes sure a URL can be used as a link destination.
+func linkDestination(u string) string {
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
@@ -0,0 +1,122 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "title becomes heading",
+			in:   "<html><head><title>My Page</title></head><body><p>hello</p></body></html>",
+			want: "# My Page\n\nhello\n",
+		},
+		{
+			name: "title ignored when document has h1",
+			in:   "<title>Page</title><h1>Real</h1><h2>Sub</h2>",
+			want: "# Real\n\n## Sub\n",
+		},
+		{
+			name: "scripts and styles are stripped",
+			in:   "<style>p { color: red }</style><script>alert(1)</script><p>text</p><noscript>no</noscript>",
+			want: "text\n",
+		},
+		{
+			name: "emphasis and code",
+			in:   "<p>Some <b>bold</b>, <em>italic</em>, <del>gone</del> and <code>x := 1</code>.</p>",
+			want: "Some **bold**, *italic*, ~~gone~~ and `x := 1`.\n",
+		},
+		{
+			name: "links and images",
+			in:   `<p><a href="https://example.com">site</a> <a href="/a b">spaced</a> <img src="i.png" alt="pic"></p>`,
+			want: "[site](https://example.com) [spaced](</a b>) ![pic](i.png)\n",
+		},
+		{
+			name: "whitespace is collapsed",
+			in:   "<p>  lots   of\n\n  space </p>",
+			want: "lots of space\n",
+		},
+		{
+			name: "line breaks",
+			in:   "<p>one<br>two</p>",
+			want: "one\\\ntwo\n",
+		},
+		{
+			name: "markdown characters are escaped",
+			in:   "<p>*not* _emphasis_ [link]</p>",
+			want: "\\*not\\* \\_emphasis\\_ \\[link\\]\n",
+		},
+		{
+			name: "nested lists",
+			in:   "<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>",
+			want: "- one\n- two\n  - nested\n",
+		},
+		{
+			name: "ordered list start",
+			in:   `<ol start="3"><li>three</li><li>four</li></ol>`,
+			want: "3. three\n4. four\n",
+		},
+		{
+			name: "code block with language",
+			in:   "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"*hi*\")\n}</code></pre>",
+			want: "```go\nfunc main() {\n\tfmt.Println(\"*hi*\")\n}\n```\n",
+		},
+		{
+			name: "table with header",
+			in:   "<table><thead><tr><th>A</th><th>B</th></tr></thead><tbody><tr><td>1</td><td>a|b</td></tr></tbody></table>",
+			want: "| A | B |\n| --- | --- |\n| 1 | a\\|b |\n",
+		},
+		{
+			name: "table without header",
+			in:   "<table><tr><td>1</td><td>2</td></tr></table>",
+			want: "|  |  |\n| --- | --- |\n| 1 | 2 |\n",
+		},
+		{
+			name: "blockquote",
+			in:   "<blockquote><p>one</p><p>two</p></blockquote>",
+			want: "> one\n>\n> two\n",
+		},
+		{
+			name: "inline content between blocks",
+			in:   "<div>loose <i>text</i><p>para</p>more</div>",
+			want: "loose *text*\n\npara\n\nmore\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HTML([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("HTML() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HTML() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLHiddenElements(t *testing.T) {
+	got, err := HTML([]byte(`<p>shown</p><div hidden><p>secret</p></div><form><input value="x"><button>Go</button></form>`))
+	if err != nil {
+		t.Fatalf("HTML() error: %v", err)
+	}
+	if strings.Contains(got, "secret") || strings.Contains(got, "Go") {
+		t.Errorf("HTML() kept hidden content: %q", got)
+	}
+}
+
+func TestHTMLBody(t *testing.T) {
+	got, err := HTMLBody([]byte(`<html><head><title>Book</title></head><body><h2>Chapter</h2><p>Text</p></body></html>`))
+	if err != nil {
+		t.Fatalf("HTMLBody() error: %v", err)
+	}
+	if want := "## Chapter\n\nText\n"; got != want {
+		t.Errorf("HTMLBody() = %q, want %q", got, want)
+	}
+}
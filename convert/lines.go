@@ -0,0 +1,89 @@
+package convert
+
+import "strings"
+
+// splitLines splits text into lines, normalizing line endings and
+// expanding tabs to the given tab width.
+func splitLines(s string, tabWidth int) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimPrefix(s, "\ufeff")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(expandTabs(l, tabWidth), " ")
+	}
+	return lines
+}
+
+func expandTabs(s string, tabWidth int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// indentOf returns the number of leading spaces of a line.
+func indentOf(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// dedent removes the common indentation of the non-blank lines, as well as
+// leading and trailing blank lines.
+func dedent(lines []string) []string {
+	for len(lines) > 0 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+
+	common := -1
+	for _, l := range lines {
+		if isBlank(l) {
+			continue
+		}
+		if n := indentOf(l); common < 0 || n < common {
+			common = n
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if isBlank(l) {
+			continue
+		}
+		out[i] = l[common:]
+	}
+	return out
+}
+
+// indentedBlock returns the lines starting at start which are blank or
+// indented by more than indent, ignoring trailing blank lines.
+func indentedBlock(lines []string, start, indent int) []string {
+	end := start
+	for i := start; i < len(lines); i++ {
+		if isBlank(lines[i]) {
+			continue
+		}
+		if indentOf(lines[i]) <= indent {
+			break
+		}
+		end = i + 1
+	}
+	return lines[start:end]
+}
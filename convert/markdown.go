@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// codeFence wraps code in a fenced code block.
+func codeFence(code, language string) string {
+	code = strings.Trim(code, "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// markdownTable builds a markdown table. Markdown tables need a header row,
+// so an empty one is used if header is nil.
+func markdownTable(header []string, rows [][]string) string {
+	cols := len(header)
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	row := func(cells []string) string {
+		out := make([]string, cols)
+		for i := range out {
+			if i < len(cells) {
+				out[i] = strings.ReplaceAll(cells[i], "|", "\\|")
+			}
+		}
+		return "| " + strings.Join(out, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(row(header))
+	b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+	for _, r := range rows {
+		b.WriteString(row(r))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// blockquote turns markdown into a block quote.
+func blockquote(md string) string {
+	lines := strings.Split(md, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("> "+l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinItemBlocks joins the blocks of a list item. Nested lists directly
+// follow the preceding text, other blocks are separated by a blank line.
+func joinItemBlocks(blocks []string) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			if listMarker.MatchString(block) {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(block)
+	}
+	return b.String()
+}
+
+var listMarker = regexp.MustCompile(`^(- |\d+\. )`)
+
+// listItem builds a list item, indenting the content's continuation lines
+// so they belong to the item.
+func listItem(marker, content string) string {
+	if content == "" {
+		return strings.TrimRight(marker, " ")
+	}
+	return marker + indentLines(content, strings.Repeat(" ", len(marker)))[len(marker):]
+}
+
+// indentLines indents every line but empty ones with prefix.
+func indentLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func indentBlocks(blocks []string, prefix string) []string {
+	for i, b := range blocks {
+		blocks[i] = indentLines(b, prefix)
+	}
+	return blocks
+}
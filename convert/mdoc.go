@@ -0,0 +1,572 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The mdoc(7) macros describe the meaning of words rather than their
+// font. Most of them can be called from the arguments of other macros, as
+// in ".Op Fl a Ar file".
+
+type mdocState struct {
+	// the prologue, which is shown once it's complete
+	title, section, date, os string
+	prologue, headerDone     bool
+
+	// name of the documented utility, set by the first .Nm
+	name string
+	// current section, like "SYNOPSIS"
+	sh string
+	// open lists and displays, and how many lists were opened
+	lists    []*mdocList
+	nLists   int
+	literals []bool
+	// whether a multi-line item tag, started with .Xo, is being read
+	tagUntilXc bool
+	// whether the arguments of a function block are being read
+	inFunction bool
+	fnArgs     int
+	// whether spacing between words is off, and whether the first word
+	// since it was turned off has been written
+	noSpacing, spaced bool
+}
+
+type mdocList struct {
+	// identifies the list, so adjacent lists aren't merged
+	id   string
+	kind string
+	// rows of a column list
+	rows [][]string
+}
+
+// mdocPiece is a formatted word of a macro line.
+type mdocPiece struct {
+	text string
+	// attached to the previous piece, like closing punctuation
+	attach bool
+	// whether the next piece is attached, like after an opening parenthesis
+	open bool
+	// punctuation, which isn't formatted
+	delim bool
+}
+
+var mdocCallable = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`Ac Ad An Ao Ap Aq Ar At Bc Bo Bq Brc Bro Brq
+		Bsx Bx Cd Cm Dc Do Dq Dv Dx Ec Em En Eo Er Es Ev Fa Fc Fl Fn Fr Ft Fx
+		Ic In Li Lk Ms Mt Nm No Ns Nx Oc Oo Op Ox Pa Pc Pf Po Pq Qc Ql Qo Qq Sc
+		So Sq St Sx Sy Ta Tn Ux Va Vt Xc Xo Xr %A %B %C %D %I %J %N %O %P %Q %R
+		%T %U %V`) {
+		mdocCallable[name] = true
+	}
+}
+
+var (
+	mdocClosing = map[string]bool{".": true, ",": true, ";": true, ":": true, "?": true, "!": true, ")": true, "]": true}
+	mdocOpening = map[string]bool{"(": true, "[": true}
+)
+
+// mdocEnclosures are the macros that enclose the rest of the line.
+var mdocEnclosures = map[string][2]string{
+	"Aq": {"⟨", "⟩"}, "Bq": {"[", "]"}, "Brq": {"{", "}"}, "Dq": {"“", "”"},
+	"Op": {"[", "]"}, "Pq": {"(", ")"}, "Qq": {`"`, `"`}, "Sq": {"‘", "’"},
+}
+
+// mdocDelimiters are the macros that open or close an enclosure spanning
+// several lines.
+var mdocDelimiters = map[string]struct {
+	text string
+	open bool
+}{
+	"Ao": {"⟨", true}, "Ac": {"⟩", false}, "Bo": {"[", true}, "Bc": {"]", false},
+	"Bro": {"{", true}, "Brc": {"}", false}, "Do": {"“", true}, "Dc": {"”", false},
+	"Oo": {"[", true}, "Oc": {"]", false}, "Po": {"(", true}, "Pc": {")", false},
+	"Qo": {`"`, true}, "Qc": {`"`, false}, "So": {"‘", true}, "Sc": {"’", false},
+}
+
+var mdocSystems = map[string]string{
+	"Bsx": "BSD/OS", "Bx": "BSD", "Dx": "DragonFly", "Fx": "FreeBSD",
+	"Nx": "NetBSD", "Ox": "OpenBSD", "Ux": "UNIX", "At": "AT&T UNIX",
+}
+
+var mdocStandards = map[string]string{
+	"-ansiC": "ANSI X3.159-1989 (“ANSI C89”)", "-isoC": "ISO/IEC 9899:1990 (“ISO C90”)",
+	"-isoC-99": "ISO/IEC 9899:1999 (“ISO C99”)", "-isoC-2011": "ISO/IEC 9899:2011 (“ISO C11”)",
+	"-p1003.1": "IEEE Std 1003.1 (“POSIX.1”)", "-p1003.1-2001": "IEEE Std 1003.1-2001 (“POSIX.1”)",
+	"-p1003.1-2008": "IEEE Std 1003.1-2008 (“POSIX.1”)", "-p1003.2": "IEEE Std 1003.2 (“POSIX.2”)",
+	"-xpg4": "X/Open Portability Guide Issue 4 (“XPG4”)", "-susv2": "Version 2 of the Single UNIX Specification (“SUSv2”)",
+	"-susv3": "Version 3 of the Single UNIX Specification (“SUSv3”)", "-susv4": "Version 4 of the Single UNIX Specification (“SUSv4”)",
+}
+
+// mdocHeader adds the title of an mdoc page, once its prologue is complete.
+func (c *roffConverter) mdocHeader() {
+	m := &c.mdoc
+	if !m.prologue || m.headerDone {
+		return
+	}
+	m.headerDone = true
+	if m.title != "" {
+		title := escapeMarkdown(c.plain(m.title))
+		if m.section != "" {
+			title += "(" + escapeMarkdown(c.plain(m.section)) + ")"
+		}
+		c.blocks = append(c.blocks, officeBlock{markdown: "# " + title})
+	}
+	var byline []string
+	for _, s := range []string{m.os, m.date} {
+		if s = strings.TrimSpace(c.plain(s)); s != "" {
+			byline = append(byline, escapeMarkdown(s))
+		}
+	}
+	if len(byline) > 0 {
+		c.blocks = append(c.blocks, officeBlock{markdown: "*" + strings.Join(byline, " · ") + "*"})
+	}
+}
+
+// mdocRequest handles an mdoc macro and returns whether name is one.
+func (c *roffConverter) mdocRequest(name, rest string, args []string) bool {
+	m := &c.mdoc
+	switch name {
+	case "Dd":
+		date := strings.Join(args, " ")
+		date = strings.TrimPrefix(date, "$Mdocdate: ")
+		m.date = strings.TrimSpace(strings.TrimSuffix(date, "$"))
+		m.prologue = true
+	case "Dt":
+		if len(args) > 0 {
+			m.title = args[0]
+		}
+		if len(args) > 1 {
+			m.section = args[1]
+		}
+		m.prologue = true
+	case "Os":
+		m.os = strings.Join(args, " ")
+		m.prologue = true
+		c.mdocHeader()
+
+	case "Sh", "Ss":
+		c.mdocHeader()
+		m.lists, m.literals, m.tagUntilXc = nil, nil, false
+		level := 2
+		if name == "Ss" {
+			level = 3
+		} else {
+			m.sh = strings.ToUpper(strings.Join(args, " "))
+		}
+		c.heading(level, joinMdoc(c.mdocArgs(args)))
+
+	case "Pp", "Lp":
+		c.flushParagraph()
+
+	case "Nd":
+		c.write("— "+joinMdoc(c.mdocArgs(args)), false)
+
+	case "Bl":
+		c.flushParagraph()
+		c.endItem()
+		kind := "item"
+		for _, a := range args {
+			if k, ok := strings.CutPrefix(a, "-"); ok && k != "compact" && k != "width" && k != "offset" {
+				kind = k
+				break
+			}
+		}
+		m.nLists++
+		m.lists = append(m.lists, &mdocList{id: strconv.Itoa(m.nLists), kind: kind})
+	case "El":
+		if len(m.lists) == 0 {
+			return true
+		}
+		c.endItem()
+		list := m.lists[len(m.lists)-1]
+		m.lists = m.lists[:len(m.lists)-1]
+		if list.kind == "column" && len(list.rows) > 0 {
+			c.addBlock(markdownTable(list.rows[0], list.rows[1:]), true)
+		}
+	case "It":
+		c.mdocItem(rest, args)
+	case "Xc":
+		if m.tagUntilXc {
+			m.tagUntilXc = false
+			c.captureLine()
+		}
+
+	case "Bd":
+		c.flushParagraph()
+		literal := false
+		for _, a := range args {
+			literal = literal || a == "-literal" || a == "-unfilled"
+		}
+		m.literals = append(m.literals, literal)
+		c.noFill = literal
+	case "Ed":
+		c.flushCode()
+		c.flushParagraph()
+		if len(m.literals) > 0 {
+			m.literals = m.literals[:len(m.literals)-1]
+		}
+		c.noFill = len(m.literals) > 0 && m.literals[len(m.literals)-1]
+	case "D1":
+		c.flushParagraph()
+		c.addBlock(blockquote(joinMdoc(c.mdocArgs(args))), false)
+	case "Dl":
+		c.flushParagraph()
+		c.addBlock(codeFence(c.plain(strings.Join(args, " ")), ""), true)
+
+	case "Bf":
+		if len(args) > 0 {
+			switch args[0] {
+			case "-emphasis", "Em":
+				c.font = textRun{italic: true}
+			case "-symbolic", "Sy":
+				c.font = textRun{bold: true}
+			case "-literal", "Li":
+				c.font = textRun{code: true}
+			}
+		}
+	case "Ef":
+		c.font = textRun{}
+
+	case "Fo":
+		if len(args) > 0 {
+			c.write(wrapInline(escapeMarkdown(c.plain(args[0])), "**")+"(", false)
+			c.join = true
+			m.inFunction, m.fnArgs = true, 0
+		}
+	case "Fc":
+		c.write(")", true)
+		m.inFunction = false
+
+	case "Ex":
+		names := mdocNames(c, args, "-std")
+		c.write("The "+names+" utility exits 0 on success, and >0 if an error occurs.", false)
+	case "Rv":
+		names := mdocNames(c, args, "-std")
+		c.write("The "+names+"() function returns the value 0 if successful; otherwise the value -1 is returned "+
+			"and the global variable *errno* is set to indicate the error.", false)
+
+	case "Rs", "Re":
+		c.flushParagraph()
+
+	case "Sm":
+		m.noSpacing = len(args) == 0 && !m.noSpacing || len(args) > 0 && args[0] == "off"
+		m.spaced = false
+
+	case "Db", "Bk", "Ek", "Bt", "Ud":
+		// debugging and keeps don't matter here
+
+	default:
+		if _, ok := mdocDelimiters[name]; !ok && !mdocCallable[name] {
+			return false
+		}
+		if c.noFill {
+			c.code = append(c.code, c.plain(strings.Join(args, " ")))
+			return true
+		}
+		if name == "Nm" && m.sh == "SYNOPSIS" && len(c.para) > 0 {
+			// each form of the command starts a new line
+			c.flushParagraph()
+		}
+		if name == "Fa" && m.inFunction {
+			if m.fnArgs > 0 {
+				c.write(",", true)
+			}
+			m.fnArgs++
+			c.write(joinMdoc(c.mdocMacro(name, args)), m.fnArgs == 1)
+			c.join = false
+			return true
+		}
+		pieces := c.mdocMacro(name, args)
+		if len(pieces) == 0 {
+			return true
+		}
+		attach := pieces[0].attach
+		if m.noSpacing {
+			for i := range pieces {
+				pieces[i].attach = true
+			}
+			attach, m.spaced = m.spaced, true
+		}
+		c.write(joinMdoc(pieces), attach)
+		c.join = pieces[len(pieces)-1].open
+	}
+	return true
+}
+
+// mdocNames formats the utility or function names of .Ex and .Rv, which
+// default to the page's name.
+func mdocNames(c *roffConverter, args []string, flag string) string {
+	var names []string
+	for _, a := range args {
+		if a != flag {
+			names = append(names, wrapInline(escapeMarkdown(c.plain(a)), "**"))
+		}
+	}
+	if len(names) == 0 && c.mdoc.name != "" {
+		names = append(names, wrapInline(escapeMarkdown(c.plain(c.mdoc.name)), "**"))
+	}
+	return strings.Join(names, ", ")
+}
+
+// mdocItem starts an item of the innermost list.
+func (c *roffConverter) mdocItem(rest string, args []string) {
+	m := &c.mdoc
+	if len(m.lists) == 0 {
+		return
+	}
+	list := m.lists[len(m.lists)-1]
+	level := len(m.lists) - 1
+
+	switch list.kind {
+	case "column":
+		// cells are separated by Ta or tabs
+		c.flushParagraph()
+		var row []string
+		var cell []string
+		for _, a := range parseArgs(strings.ReplaceAll(rest, "\t", " Ta ")) {
+			if a == "Ta" {
+				row = append(row, joinMdoc(c.mdocArgs(cell)))
+				cell = nil
+				continue
+			}
+			cell = append(cell, a)
+		}
+		row = append(row, joinMdoc(c.mdocArgs(cell)))
+		list.rows = append(list.rows, row)
+
+	case "bullet", "dash", "hyphen", "item", "enum":
+		c.startItem(level, list.kind == "enum")
+		c.item.list = list.id
+		c.write(joinMdoc(c.mdocArgs(args)), false)
+
+	default:
+		// tagged lists: tag, hang, ohang, inset and diag
+		c.startItem(level, false)
+		c.item.list = list.id
+		for i, a := range args {
+			if a == "Xo" {
+				// the tag continues on the following lines until .Xc
+				c.write(joinMdoc(c.mdocArgs(args[:i])), false)
+				m.tagUntilXc = true
+				c.inTag = true
+				return
+			}
+		}
+		tag := joinMdoc(c.mdocArgs(args))
+		if list.kind == "diag" {
+			tag = wrapInline(tag, "**")
+		}
+		c.itemTag = tag
+	}
+}
+
+// joinMdoc joins formatted words, attaching punctuation.
+func joinMdoc(pieces []mdocPiece) string {
+	var s string
+	open := false
+	for i, p := range pieces {
+		switch {
+		case i == 0:
+			s = p.text
+		case p.attach || open:
+			s = joinAttached(s, p.text)
+		default:
+			s += " " + p.text
+		}
+		open = p.open
+	}
+	return s
+}
+
+// mdocArgs formats the arguments of a macro, which may call other macros.
+func (c *roffConverter) mdocArgs(args []string) []mdocPiece {
+	if len(args) == 0 {
+		return nil
+	}
+	if mdocCallable[args[0]] || mdocDelimiters[args[0]].text != "" {
+		return c.mdocMacro(args[0], args[1:])
+	}
+	n := 1
+	for n < len(args) && !mdocCallable[args[n]] {
+		n++
+	}
+	return append(c.mdocWords(args[:n], escapeMarkdown), c.mdocArgs(args[n:])...)
+}
+
+// mdocWords formats words, leaving punctuation unformatted.
+func (c *roffConverter) mdocWords(words []string, format func(string) string) []mdocPiece {
+	pieces := make([]mdocPiece, 0, len(words))
+	for _, w := range words {
+		switch {
+		case mdocClosing[w]:
+			pieces = append(pieces, mdocPiece{text: escapeMarkdown(w), attach: true, delim: true})
+		case mdocOpening[w]:
+			pieces = append(pieces, mdocPiece{text: escapeMarkdown(w), open: true, delim: true})
+		default:
+			if text := c.plain(w); text != "" {
+				pieces = append(pieces, mdocPiece{text: format(text)})
+			}
+		}
+	}
+	return pieces
+}
+
+func mdocBold(s string) string   { return wrapInline(escapeMarkdown(s), "**") }
+func mdocItalic(s string) string { return wrapInline(escapeMarkdown(s), "*") }
+
+// hasWords returns whether words hold more than punctuation.
+func hasWords(words []string) bool {
+	for _, w := range words {
+		if !mdocClosing[w] && !mdocOpening[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// mdocMacro formats a macro call and the rest of its line.
+func (c *roffConverter) mdocMacro(name string, args []string) []mdocPiece {
+	// the macro's own arguments end at the next callable macro
+	n := 0
+	for n < len(args) && !mdocCallable[args[n]] {
+		n++
+	}
+	own, rest := args[:n], args[n:]
+
+	if q, ok := mdocEnclosures[name]; ok {
+		inner := c.mdocArgs(args)
+		// trailing punctuation goes after the enclosure
+		end := len(inner)
+		for end > 0 && inner[end-1].delim && inner[end-1].attach {
+			end--
+		}
+		text := escapeMarkdown(q[0]) + joinMdoc(inner[:end]) + escapeMarkdown(q[1])
+		return append([]mdocPiece{{text: text}}, inner[end:]...)
+	}
+	if d, ok := mdocDelimiters[name]; ok {
+		piece := mdocPiece{text: escapeMarkdown(d.text), open: d.open, attach: !d.open}
+		return append([]mdocPiece{piece}, c.mdocArgs(args)...)
+	}
+
+	var pieces []mdocPiece
+	switch name {
+	case "Fl":
+		pieces = c.mdocWords(own, func(s string) string { return mdocBold("-" + s) })
+		if !hasWords(own) {
+			pieces = append([]mdocPiece{{text: mdocBold("-")}}, pieces...)
+		}
+	case "Ar":
+		pieces = c.mdocWords(own, mdocItalic)
+		if !hasWords(own) {
+			pieces = append([]mdocPiece{{text: mdocItalic("file ...")}}, pieces...)
+		}
+	case "Nm":
+		if hasWords(own) && c.mdoc.name == "" {
+			c.mdoc.name = own[0]
+		}
+		pieces = c.mdocWords(own, mdocBold)
+		if !hasWords(own) && c.mdoc.name != "" {
+			pieces = append([]mdocPiece{{text: mdocBold(c.plain(c.mdoc.name))}}, pieces...)
+		}
+	case "Cm", "Ic", "Sy", "Cd", "Fd":
+		pieces = c.mdocWords(own, mdocBold)
+	case "Em", "Pa", "Va", "Fa", "Ft", "Vt", "Sx", "Ad", "%T", "%B", "%J":
+		pieces = c.mdocWords(own, mdocItalic)
+	case "Ev", "Dv", "Er", "Li":
+		pieces = c.mdocWords(own, codeSpan)
+	case "Ql":
+		i := 0
+		for i < len(own) && !mdocClosing[own[i]] {
+			i++
+		}
+		if i > 0 {
+			pieces = append(pieces, mdocPiece{text: codeSpan(c.plain(strings.Join(own[:i], " ")))})
+		}
+		pieces = append(pieces, c.mdocWords(own[i:], escapeMarkdown)...)
+	case "Xr":
+		if len(own) > 0 {
+			text := mdocBold(c.plain(own[0]))
+			if len(own) > 1 && !mdocClosing[own[1]] {
+				text += "(" + escapeMarkdown(c.plain(own[1])) + ")"
+				own = own[1:]
+			}
+			pieces = append([]mdocPiece{{text: text}}, c.mdocWords(own[1:], escapeMarkdown)...)
+		}
+	case "Fn":
+		if len(own) > 0 {
+			var params []string
+			i := 1
+			for ; i < len(own) && !mdocClosing[own[i]]; i++ {
+				params = append(params, mdocItalic(c.plain(own[i])))
+			}
+			text := mdocBold(c.plain(own[0])) + "(" + strings.Join(params, ", ") + ")"
+			pieces = append([]mdocPiece{{text: text}}, c.mdocWords(own[i:], escapeMarkdown)...)
+		}
+	case "In":
+		if len(own) > 0 {
+			pieces = append([]mdocPiece{{text: mdocBold("#include <" + c.plain(own[0]) + ">")}},
+				c.mdocWords(own[1:], escapeMarkdown)...)
+		}
+	case "Lk", "Mt":
+		if len(own) > 0 {
+			url := c.plain(own[0])
+			i := 1
+			for i < len(own) && !mdocClosing[own[i]] {
+				i++
+			}
+			text := escapeMarkdown(url)
+			if i > 1 {
+				text = escapeMarkdown(c.plain(strings.Join(own[1:i], " ")))
+			}
+			if name == "Mt" {
+				url = "mailto:" + url
+			}
+			pieces = append([]mdocPiece{{text: "[" + text + "](" + linkDestination(url) + ")"}},
+				c.mdocWords(own[i:], escapeMarkdown)...)
+		}
+	case "Ns":
+		pieces = c.mdocArgs(args)
+		if len(pieces) > 0 {
+			pieces[0].attach = true
+		}
+		return pieces
+	case "Ap":
+		pieces = c.mdocArgs(args)
+		return append([]mdocPiece{{text: "'", attach: true, open: true}}, pieces...)
+	case "Pf":
+		if len(args) == 0 {
+			return nil
+		}
+		pieces = c.mdocArgs(args[1:])
+		prefix := mdocPiece{text: escapeMarkdown(c.plain(args[0])), open: true}
+		return append([]mdocPiece{prefix}, pieces...)
+	case "St":
+		if len(own) > 0 {
+			standard, ok := mdocStandards[own[0]]
+			if !ok {
+				standard = c.plain(own[0])
+			}
+			pieces = append([]mdocPiece{{text: escapeMarkdown(standard)}}, c.mdocWords(own[1:], escapeMarkdown)...)
+		}
+	case "Bsx", "Bx", "Dx", "Fx", "Nx", "Ox", "Ux", "At":
+		text := mdocSystems[name]
+		if hasWords(own) && !mdocClosing[own[0]] {
+			if name == "Bx" {
+				text = c.plain(own[0]) + text
+			} else {
+				text += " " + c.plain(own[0])
+			}
+			own = own[1:]
+		}
+		pieces = append([]mdocPiece{{text: escapeMarkdown(text)}}, c.mdocWords(own, escapeMarkdown)...)
+	case "Ta", "Xo", "Xc", "Es", "En", "Eo", "Ec", "Fr":
+		pieces = c.mdocWords(own, escapeMarkdown)
+	default:
+		// Ms, No, Tn, An and references
+		pieces = c.mdocWords(own, escapeMarkdown)
+	}
+	return append(pieces, c.mdocArgs(rest)...)
+}
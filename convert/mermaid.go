@@ -0,0 +1,7 @@
+package convert
+
+// Mermaid wraps a Mermaid diagram in a code block, where it's drawn when the
+// document is rendered.
+func Mermaid(b []byte) (string, error) {
+	return codeFence(string(b), "mermaid"), nil
+}
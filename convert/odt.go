@@ -0,0 +1,330 @@
+package convert
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// maxRepeatedCells limits how often a repeated table cell is repeated, as
+// documents may repeat empty cells to the end of the row.
+const maxRepeatedCells = 64
+
+// ODT converts an OpenDocument text document into markdown. Paragraphs,
+// headings, lists, tables, links and bold, italic and struck out text are
+// converted.
+func ODT(b []byte) (string, error) {
+	zr, err := openArchive(b)
+	if err != nil {
+		return "", err
+	}
+	content, err := readArchiveXML(zr, "content.xml")
+	if err != nil {
+		return "", err
+	}
+
+	c := odtConverter{
+		styles:     map[string]odtStyle{},
+		listStyles: map[string][]bool{},
+	}
+	// common styles are optional, automatic styles are in the content
+	if n, err := readArchiveXML(zr, "styles.xml"); err == nil {
+		c.readStyles(n)
+	} else if !errors.Is(err, errMissingFile) {
+		return "", err
+	}
+	c.readStyles(content)
+
+	text := content.path("document-content", "body", "text")
+	if text == nil {
+		return "", errors.New("unable to read document: no text body")
+	}
+	return joinOfficeBlocks(c.blocks(text, -1, "")), nil
+}
+
+type odtConverter struct {
+	// paragraph and text styles by name
+	styles map[string]odtStyle
+	// whether the levels of list styles are numbered, by style name
+	listStyles map[string][]bool
+	// number of lists, which identifies them
+	lists int
+}
+
+type odtStyle struct {
+	parent      string
+	displayName string
+	format      textRun
+}
+
+// odtCodeFonts are monospace fonts, text set in them is shown as code.
+var odtCodeFonts = []string{"courier", "mono", "consolas", "menlo", "source code"}
+
+func (c *odtConverter) readStyles(root *xmlNode) {
+	for _, s := range root.all("style") {
+		style := odtStyle{
+			parent:      s.attr("parent-style-name"),
+			displayName: s.attr("display-name"),
+		}
+		if props := s.child("text-properties"); props != nil {
+			weight := props.attr("font-weight")
+			style.format.bold = weight == "bold" || weight >= "600" && weight <= "900"
+			style.format.italic = props.attr("font-style") == "italic" || props.attr("font-style") == "oblique"
+			strike := props.attr("text-line-through-style")
+			style.format.strike = strike != "" && strike != "none"
+			font := strings.ToLower(props.attr("font-name"))
+			for _, f := range odtCodeFonts {
+				if font != "" && strings.Contains(font, f) {
+					style.format.code = true
+				}
+			}
+		}
+		c.styles[s.attr("name")] = style
+	}
+
+	for _, l := range root.all("list-style") {
+		var levels []bool
+		for _, lvl := range l.children {
+			if lvl.name != "list-level-style-number" && lvl.name != "list-level-style-bullet" {
+				continue
+			}
+			i, err := strconv.Atoi(lvl.attr("level"))
+			if err != nil || i < 1 || i > 10 {
+				continue
+			}
+			for len(levels) < i {
+				levels = append(levels, false)
+			}
+			levels[i-1] = lvl.name == "list-level-style-number" && lvl.attr("num-format") != ""
+		}
+		c.listStyles[l.attr("name")] = levels
+	}
+}
+
+// styleNames returns the names of a style and the styles it inherits
+// from, using display names where they're set.
+func (c *odtConverter) styleNames(name string) []string {
+	var names []string
+	for i := 0; name != "" && i < 10; i++ {
+		s, ok := c.styles[name]
+		if s.displayName != "" {
+			names = append(names, s.displayName)
+		} else {
+			names = append(names, name)
+		}
+		if !ok {
+			break
+		}
+		name = s.parent
+	}
+	return names
+}
+
+// format returns the text formatting of a style, including what it
+// inherits.
+func (c *odtConverter) format(name string) textRun {
+	var f textRun
+	for i := 0; name != "" && i < 10; i++ {
+		s, ok := c.styles[name]
+		if !ok {
+			break
+		}
+		f.bold = f.bold || s.format.bold
+		f.italic = f.italic || s.format.italic
+		f.strike = f.strike || s.format.strike
+		f.code = f.code || s.format.code
+		name = s.parent
+	}
+	return f
+}
+
+// blocks converts block content. Within lists, level is the nesting level
+// and listStyle the style of the enclosing list.
+func (c *odtConverter) blocks(n *xmlNode, level int, listStyle string) []officeBlock {
+	var blocks []officeBlock
+	for _, child := range n.children {
+		switch child.name {
+		case "h":
+			lvl, err := strconv.Atoi(child.attr("outline-level"))
+			if err != nil || lvl < 1 {
+				lvl = 1
+			}
+			runs := c.runs(child, textRun{})
+			for i := range runs {
+				runs[i].bold = false
+			}
+			if md := runsToMarkdown(runs); md != "" {
+				blocks = append(blocks, officeBlock{markdown: strings.Repeat("#", min(lvl, 6)) + " " + strings.ReplaceAll(md, "\\\n", " ")})
+			}
+
+		case "p":
+			blocks = append(blocks, c.paragraph(child))
+
+		case "list":
+			style := child.attr("style-name")
+			if style == "" {
+				style = listStyle
+			}
+			blocks = append(blocks, c.list(child, level+1, style)...)
+
+		case "table":
+			blocks = append(blocks, officeBlock{markdown: c.table(child)})
+
+		case "section":
+			blocks = append(blocks, c.blocks(child, level, listStyle)...)
+		}
+	}
+	return blocks
+}
+
+func (c *odtConverter) paragraph(p *xmlNode) officeBlock {
+	styleName := p.attr("style-name")
+	var blockStyle string
+	for _, name := range c.styleNames(styleName) {
+		if level := officeHeadingLevel(name); level > 0 {
+			runs := c.runs(p, textRun{})
+			if md := runsToMarkdown(runs); md != "" {
+				return officeBlock{markdown: strings.Repeat("#", level) + " " + md}
+			}
+			return officeBlock{}
+		}
+		if blockStyle == "" {
+			blockStyle = officeBlockStyle(strings.ReplaceAll(name, "_20_", " "))
+		}
+	}
+
+	runs := c.runs(p, c.format(styleName))
+	block := officeBlock{markdown: runsToMarkdown(runs), style: blockStyle}
+	if blockStyle == "code" {
+		var text strings.Builder
+		for _, r := range runs {
+			text.WriteString(r.text)
+		}
+		block.code = text.String()
+	}
+	return block
+}
+
+func (c *odtConverter) list(l *xmlNode, level int, style string) []officeBlock {
+	c.lists++
+	id := strconv.Itoa(c.lists)
+	ordered := false
+	if levels := c.listStyles[style]; level < len(levels) {
+		ordered = levels[level]
+	}
+
+	var blocks []officeBlock
+	for _, item := range l.children {
+		if item.name != "list-item" && item.name != "list-header" {
+			continue
+		}
+		var paragraphs []string
+		for _, b := range c.blocks(item, level, style) {
+			if b.listItem {
+				// a nested list ends the item's text
+				if len(paragraphs) > 0 {
+					blocks = append(blocks, officeBlock{markdown: strings.Join(paragraphs, "\n\n"), list: id, listItem: true, listLevel: level, ordered: ordered})
+					paragraphs = nil
+				}
+				blocks = append(blocks, b)
+				continue
+			}
+			if b.markdown != "" {
+				paragraphs = append(paragraphs, b.markdown)
+			}
+		}
+		if len(paragraphs) > 0 {
+			blocks = append(blocks, officeBlock{markdown: strings.Join(paragraphs, "\n\n"), list: id, listItem: true, listLevel: level, ordered: ordered})
+		}
+	}
+	return blocks
+}
+
+// runs collects the formatted text of a paragraph.
+func (c *odtConverter) runs(n *xmlNode, format textRun) []textRun {
+	var runs []textRun
+	for _, child := range n.children {
+		switch child.name {
+		case "":
+			// whitespace is collapsed, spaces are given by <text:s>
+			t := format
+			t.text = collapseSpace(child.text)
+			runs = append(runs, t)
+
+		case "s":
+			count, err := strconv.Atoi(child.attr("c"))
+			if err != nil || count < 1 {
+				count = 1
+			}
+			t := format
+			t.text = strings.Repeat(" ", min(count, maxRepeatedCells))
+			runs = append(runs, t)
+
+		case "tab":
+			t := format
+			t.text = " "
+			runs = append(runs, t)
+
+		case "line-break":
+			t := format
+			t.text = "\n"
+			runs = append(runs, t)
+
+		case "span":
+			f := c.format(child.attr("style-name"))
+			f.bold = f.bold || format.bold
+			f.italic = f.italic || format.italic
+			f.strike = f.strike || format.strike
+			f.code = f.code || format.code
+			f.link = format.link
+			runs = append(runs, c.runs(child, f)...)
+
+		case "a":
+			f := format
+			f.link = child.attr("href")
+			runs = append(runs, c.runs(child, f)...)
+
+		case "note", "annotation", "frame", "bookmark", "bookmark-start", "bookmark-end", "soft-page-break":
+			// footnotes, comments and images aren't shown
+
+		default:
+			runs = append(runs, c.runs(child, format)...)
+		}
+	}
+	return runs
+}
+
+func (c *odtConverter) table(t *xmlNode) string {
+	var rows [][]string
+	for _, tr := range t.all("table-row") {
+		var row []string
+		for _, tc := range tr.children {
+			if tc.name != "table-cell" && tc.name != "covered-table-cell" {
+				continue
+			}
+			var paragraphs []string
+			for _, b := range c.blocks(tc, -1, "") {
+				if md := strings.TrimSpace(b.markdown); md != "" {
+					paragraphs = append(paragraphs, strings.ReplaceAll(md, "\n", " "))
+				}
+			}
+			cell := strings.Join(paragraphs, " ")
+			repeat, err := strconv.Atoi(tc.attr("number-columns-repeated"))
+			if err != nil || repeat < 1 {
+				repeat = 1
+			}
+			for range min(repeat, maxRepeatedCells) {
+				row = append(row, cell)
+			}
+		}
+		// trailing empty cells are often just repeated to the end of the row
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return markdownTable(rows[0], rows[1:])
+}
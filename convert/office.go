@@ -0,0 +1,320 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Word processor documents, like DOCX and ODT, are zip archives of XML
+// files. They're read into a simple tree, as their content is mixed: text,
+// formatting and structure are interleaved.
+
+// xmlNode is an element or, if name is empty, a text node.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     string
+}
+
+// maxArchiveFileSize limits how much is read from a file in an archive, so
+// a malicious document can't exhaust memory.
+const maxArchiveFileSize = 64 << 20
+
+var errMissingFile = errors.New("missing file")
+
+// openArchive opens the zip archive of a document.
+func openArchive(b []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read document: %w", err)
+	}
+	return zr, nil
+}
+
+// readArchiveXML parses an XML file in a document's archive. It returns
+// errMissingFile if there's no such file.
+func readArchiveXML(zr *zip.Reader, name string) (*xmlNode, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", name, err)
+		}
+		defer r.Close() //nolint:errcheck
+		n, err := parseXML(io.LimitReader(r, maxArchiveFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", name, err)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s: %w", name, errMissingFile)
+}
+
+// parseXML reads an XML document into a tree. Names are stripped of their
+// namespaces, which is good enough for the documents we read.
+func parseXML(r io.Reader) (*xmlNode, error) {
+	root := &xmlNode{name: "#document"}
+	stack := []*xmlNode{root}
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &xmlNode{text: string(t)})
+		}
+	}
+}
+
+// attr returns the value of an attribute by its local name. Like the other
+// accessors, it can be called on nil nodes.
+func (n *xmlNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with the given name.
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// path follows a path of child elements, returning nil if there's none.
+func (n *xmlNode) path(names ...string) *xmlNode {
+	for _, name := range names {
+		n = n.child(name)
+	}
+	return n
+}
+
+// all returns the descendants with the given name, in document order. It
+// doesn't descend into matching elements.
+func (n *xmlNode) all(name string) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.children {
+		if c.name == name {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, c.all(name)...)
+	}
+	return found
+}
+
+// textContent returns the text of n and its descendants.
+func (n *xmlNode) textContent() string {
+	if n.name == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+// textRun is a piece of text with uniform formatting.
+type textRun struct {
+	text   string
+	bold   bool
+	italic bool
+	strike bool
+	code   bool
+	link   string
+}
+
+func (r textRun) sameFormat(o textRun) bool {
+	return r.bold == o.bold && r.italic == o.italic && r.strike == o.strike &&
+		r.code == o.code && r.link == o.link
+}
+
+// runsToMarkdown converts formatted text into markdown. Adjacent runs with
+// the same formatting are merged first, as documents often split text into
+// many runs.
+func runsToMarkdown(runs []textRun) string {
+	var merged []textRun
+	for _, r := range runs {
+		if r.text == "" {
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].sameFormat(r) {
+			merged[n-1].text += r.text
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	var b strings.Builder
+	for _, r := range merged {
+		var s string
+		if r.code {
+			s = codeSpan(r.text)
+		} else {
+			s = escapeMarkdown(r.text)
+			// line breaks within paragraphs are hard breaks
+			s = strings.ReplaceAll(s, "\n", "\\\n")
+		}
+		if r.strike {
+			s = wrapInline(s, "~~")
+		}
+		if r.italic {
+			s = wrapInline(s, "*")
+		}
+		if r.bold {
+			s = wrapInline(s, "**")
+		}
+		if r.link != "" && strings.TrimSpace(s) != "" {
+			s = "[" + strings.TrimSpace(s) + "](" + linkDestination(r.link) + ")"
+		}
+		b.WriteString(s)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// officeHeadingLevel returns the heading level of a paragraph style name,
+// like "Heading 2" or "Title", or 0 if it's not a heading.
+func officeHeadingLevel(style string) int {
+	style = strings.ToLower(strings.NewReplacer("_20_", " ", "_", " ").Replace(style))
+	switch {
+	case style == "title":
+		return 1
+	case style == "subtitle":
+		return 2
+	case strings.HasPrefix(style, "heading "):
+		var level int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(style, "heading "), "%d", &level); err == nil && level > 0 {
+			return min(level, 6)
+		}
+	}
+	return 0
+}
+
+// officeBlockStyle returns how paragraphs with a style are rendered, based
+// on the style's name: "code" for preformatted text, "quote" for quotes.
+func officeBlockStyle(style string) string {
+	style = strings.ToLower(style)
+	switch {
+	case strings.Contains(style, "code"), strings.Contains(style, "preformatted"):
+		return "code"
+	case strings.Contains(style, "quot"):
+		return "quote"
+	}
+	return ""
+}
+
+// officeBlock is a converted paragraph, which is grouped with its
+// neighbours into lists, code blocks and quotes.
+type officeBlock struct {
+	markdown string
+	// code is the raw text of preformatted paragraphs
+	code  string
+	style string
+	// list items; list identifies the list an item belongs to
+	list      string
+	listLevel int
+	listItem  bool
+	ordered   bool
+}
+
+// joinOfficeBlocks joins converted paragraphs into markdown. Consecutive
+// list items make up a list, consecutive code and quote paragraphs a block.
+func joinOfficeBlocks(blocks []officeBlock) string {
+	var out []string
+	for i := 0; i < len(blocks); i++ {
+		b := blocks[i]
+		switch {
+		case b.listItem:
+			var (
+				items  []string
+				nums   []int
+				widths []int
+			)
+			for ; i < len(blocks) && blocks[i].listItem; i++ {
+				item := blocks[i]
+				if item.listLevel == 0 && item.list != b.list {
+					// another list follows directly
+					break
+				}
+				level := max(0, min(item.listLevel, len(nums)))
+				if level == len(nums) {
+					nums, widths = append(nums, 0), append(widths, 0)
+				}
+				nums, widths = nums[:level+1], widths[:level+1]
+				nums[level]++
+				marker := "- "
+				if item.ordered {
+					marker = strconv.Itoa(nums[level]) + ". "
+				}
+				widths[level] = len(marker)
+
+				// nested lists are indented to the content of their parent
+				indent := 0
+				for _, w := range widths[:level] {
+					indent += w
+				}
+				items = append(items, indentLines(listItem(marker, item.markdown), strings.Repeat(" ", indent)))
+			}
+			i--
+			out = append(out, strings.Join(items, "\n"))
+
+		case b.style == "code":
+			var lines []string
+			for ; i < len(blocks) && blocks[i].style == "code" && !blocks[i].listItem; i++ {
+				lines = append(lines, blocks[i].code)
+			}
+			i--
+			out = append(out, codeFence(strings.Join(lines, "\n"), ""))
+
+		case b.style == "quote":
+			var quoted []string
+			for ; i < len(blocks) && blocks[i].style == "quote" && !blocks[i].listItem; i++ {
+				quoted = append(quoted, blocks[i].markdown)
+			}
+			i--
+			out = append(out, blockquote(strings.Join(quoted, "\n\n")))
+
+		case b.markdown != "":
+			out = append(out, b.markdown)
+		}
+	}
+	return strings.Join(out, "\n\n") + "\n"
+}
@@ -0,0 +1,156 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// zipArchive builds a zip archive of the given files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const docxNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestDOCX(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document ` + docxNamespaces + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>Product Spec</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="berschrift1"/></w:pPr><w:r><w:t>Overview</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Some </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>bold</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> text</w:t></w:r><w:r><w:t xml:space="preserve">, </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>italic</w:t></w:r><w:r><w:t xml:space="preserve">, </w:t></w:r><w:r><w:rPr><w:b w:val="0"/><w:strike/></w:rPr><w:t>gone</w:t></w:r><w:r><w:t xml:space="preserve"> and </w:t></w:r><w:r><w:rPr><w:rFonts w:ascii="Courier New"/></w:rPr><w:t>code</w:t></w:r><w:r><w:t>.</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">See </w:t></w:r><w:hyperlink r:id="rId5"><w:r><w:t>the site</w:t></w:r></w:hyperlink><w:r><w:t xml:space="preserve"> and *stars*.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>first</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>nested</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>second</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>one</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>two</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Value</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>a|b</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:pPr><w:pStyle w:val="Quote"/></w:pPr><w:r><w:t>Quoted</w:t></w:r><w:r><w:br/></w:r><w:r><w:t>text</w:t></w:r></w:p>
+<w:p/>
+</w:body></w:document>`
+
+	styles := `<w:styles ` + docxNamespaces + `>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/></w:style>
+<w:style w:type="paragraph" w:styleId="berschrift1"><w:name w:val="heading 1"/></w:style>
+<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/></w:style>
+</w:styles>`
+
+	numbering := `<w:numbering ` + docxNamespaces + `>
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>
+</w:numbering>`
+
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/>
+</Relationships>`
+
+	b := zipArchive(t, map[string]string{
+		"word/document.xml":            document,
+		"word/styles.xml":              styles,
+		"word/numbering.xml":           numbering,
+		"word/_rels/document.xml.rels": rels,
+	})
+	got, err := DOCX(b)
+	if err != nil {
+		t.Fatalf("DOCX() error: %v", err)
+	}
+
+	want := "# Product Spec\n\n" +
+		"# Overview\n\n" +
+		"Some **bold text**, *italic*, ~~gone~~ and `code`.\n\n" +
+		"See [the site](https://example.com) and \\*stars\\*.\n\n" +
+		"1. first\n   - nested\n2. second\n\n" +
+		"- one\n- two\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a\\|b | 1 |\n\n" +
+		"> Quoted\\\n> text\n"
+	if got != want {
+		t.Errorf("DOCX() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestDOCXErrors(t *testing.T) {
+	if _, err := DOCX([]byte("not a zip")); err == nil {
+		t.Error("expected error for invalid archive")
+	}
+	if _, err := DOCX(zipArchive(t, map[string]string{"other.xml": "<a/>"})); err == nil {
+		t.Error("expected error for missing document")
+	}
+}
+
+const odtNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" ` +
+	`xmlns:xlink="http://www.w3.org/1999/xlink"`
+
+func TestODT(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content ` + odtNamespaces + `>
+<office:automatic-styles>
+<style:style style:name="T1" style:family="text"><style:text-properties fo:font-weight="bold"/></style:style>
+<style:style style:name="T2" style:family="text"><style:text-properties fo:font-style="italic"/></style:style>
+<style:style style:name="P1" style:family="paragraph" style:parent-style-name="Preformatted_20_Text"/>
+<text:list-style style:name="L1"><text:list-level-style-number text:level="1" style:num-format="1"/><text:list-level-style-bullet text:level="2"/></text:list-style>
+</office:automatic-styles>
+<office:body><office:text>
+<text:sequence-decls/>
+<text:p text:style-name="Title">Spec</text:p>
+<text:h text:outline-level="2">Details</text:h>
+<text:p>Some <text:span text:style-name="T1">bold</text:span>,<text:s/><text:span text:style-name="T2">italic</text:span> and <text:a xlink:href="https://example.com">a link</text:a>.<text:note><text:note-body><text:p>hidden</text:p></text:note-body></text:note></text:p>
+<text:list text:style-name="L1">
+<text:list-item><text:p>first</text:p>
+<text:list><text:list-item><text:p>nested</text:p></text:list-item></text:list>
+</text:list-item>
+<text:list-item><text:p>second</text:p></text:list-item>
+</text:list>
+<text:p text:style-name="P1">x := 1</text:p>
+<text:p text:style-name="P1">y := 2</text:p>
+<table:table>
+<table:table-header-rows><table:table-row><table:table-cell><text:p>Name</text:p></table:table-cell><table:table-cell><text:p>Value</text:p></table:table-cell><table:table-cell table:number-columns-repeated="100"/></table:table-row></table:table-header-rows>
+<table:table-row><table:table-cell><text:p>a</text:p></table:table-cell><table:table-cell><text:p>1</text:p></table:table-cell></table:table-row>
+</table:table>
+</office:text></office:body>
+</office:document-content>`
+
+	styles := `<office:document-styles ` + odtNamespaces + `><office:styles>
+<style:style style:name="Preformatted_20_Text" style:display-name="Preformatted Text" style:family="paragraph"/>
+</office:styles></office:document-styles>`
+
+	got, err := ODT(zipArchive(t, map[string]string{"content.xml": content, "styles.xml": styles}))
+	if err != nil {
+		t.Fatalf("ODT() error: %v", err)
+	}
+
+	want := "# Spec\n\n" +
+		"## Details\n\n" +
+		"Some **bold**, *italic* and [a link](https://example.com).\n\n" +
+		"1. first\n   - nested\n2. second\n\n" +
+		"```\nx := 1\ny := 2\n```\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a | 1 |\n"
+	if got != want {
+		t.Errorf("ODT() =\n%q\nwant\n%q", got, want)
+	}
+}
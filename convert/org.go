@@ -0,0 +1,557 @@
+package convert
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Org converts an org-mode document into markdown. Headlines, TODO
+// keywords, lists, blocks, tables, drawers, links and inline markup are
+// converted. TODO states are shown as task checkboxes.
+func Org(b []byte) (string, error) {
+	lines := splitLines(string(b), 8)
+	c := orgConverter{
+		todo: map[string]bool{"TODO": false, "DONE": true},
+	}
+	c.collectSettings(lines)
+	return strings.Join(c.blocks(lines), "\n\n") + "\n", nil
+}
+
+type orgConverter struct {
+	// TODO keywords, and whether they're done states
+	todo map[string]bool
+	// headline levels are shifted when the document has a title
+	levelOffset int
+}
+
+var (
+	orgKeyword     = regexp.MustCompile(`^#\+(\w+):\s*(.*)$`)
+	orgHeadline    = regexp.MustCompile(`^(\*+) +(.*?)\s*$`)
+	orgTags        = regexp.MustCompile(`\s+(:[\w@#%:]+:)$`)
+	orgPriority    = regexp.MustCompile(`^\[#([A-Z0-9])\] *`)
+	orgPlanning    = regexp.MustCompile(`^\s*(SCHEDULED|DEADLINE|CLOSED):`)
+	orgTimestamp   = regexp.MustCompile(`[<\[](\d{4}-\d{2}-\d{2}[^>\]]*)[>\]]`)
+	orgDrawer      = regexp.MustCompile(`^\s*:([\w-]+):\s*$`)
+	orgDrawerEnd   = regexp.MustCompile(`(?i)^\s*:END:\s*$`)
+	orgProperty    = regexp.MustCompile(`^\s*:([^:\s]+):\s*(.*)$`)
+	orgBlockBegin  = regexp.MustCompile(`(?i)^\s*#\+BEGIN_(\w+)(?:\s+(.*))?$`)
+	orgFixedWidth  = regexp.MustCompile(`^\s*:( |$)`)
+	orgRule        = regexp.MustCompile(`^\s*-{5,}\s*$`)
+	orgTableRow    = regexp.MustCompile(`^\s*\|`)
+	orgTableRule   = regexp.MustCompile(`^\s*\|[-+]+\|?\s*$`)
+	orgListItem    = regexp.MustCompile(`^(\s*)([-+]|\s+\*|\d+[.)]|[a-zA-Z][.)]) +(.*)$`)
+	orgListMarker  = regexp.MustCompile(`^\s*(?:[-+*]|\d+[.)]|[a-zA-Z][.)]) +`)
+	orgCheckbox    = regexp.MustCompile(`^\[([ xX-])\] +`)
+	orgDescription = regexp.MustCompile(`^(.*?) +:: *(.*)$`)
+	orgFootnoteDef = regexp.MustCompile(`^\[fn:([\w-]+)\] +(.*)$`)
+
+	orgLink        = regexp.MustCompile(`\[\[([^\]]+)\](?:\[([^\]]+)\])?\]`)
+	orgFootnoteRef = regexp.MustCompile(`\[fn:([\w-]*)(?::[^\]]*)?\]`)
+	orgVerbatim    = regexp.MustCompile(`(^|[\s\-({'"])[=~]([^\s=~](?:[^=~]*[^\s=~])?)[=~]($|[\s\-.,:!?;'")}\[])`)
+	orgMacro       = regexp.MustCompile(`\{\{\{[^}]*\}\}\}`)
+	orgLineBreak   = regexp.MustCompile(`\\\\\s*$`)
+)
+
+// orgEmphasis maps org emphasis markers to their markdown equivalents.
+var orgEmphasis = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{orgMarkup(`\*`), "$1**$2**$3"},
+	{orgMarkup(`/`), "$1*$2*$3"},
+	{orgMarkup(`\+`), "$1~~$2~~$3"},
+	{orgMarkup(`_`), "${1}_${2}_${3}"},
+}
+
+// orgMarkup returns the pattern for text surrounded by an emphasis marker,
+// which must be preceded and followed by whitespace or punctuation.
+func orgMarkup(marker string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[\s\-({'"])` + marker + `([^\s` + marker + `](?:[^` + marker + `]*[^\s` + marker + `])?)` +
+		marker + `($|[\s\-.,:!?;'")}\[])`)
+}
+
+// orgAdmonitions are special blocks rendered like admonitions.
+var orgAdmonitions = map[string]string{
+	"NOTE": "Note", "TIP": "Tip", "IMPORTANT": "Important",
+	"WARNING": "Warning", "CAUTION": "Caution",
+}
+
+// collectSettings reads the in-buffer settings which affect the whole
+// document, like custom TODO keywords.
+func (c *orgConverter) collectSettings(lines []string) {
+	for _, l := range lines {
+		m := orgKeyword.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		switch strings.ToUpper(m[1]) {
+		case "TODO", "SEQ_TODO", "TYP_TODO":
+			c.todoKeywords(m[2])
+		case "TITLE":
+			c.levelOffset = 1
+		}
+	}
+}
+
+// todoKeywords parses a TODO sequence like "TODO NEXT | DONE CANCELED".
+// Without a "|", the last keyword is the done state.
+func (c *orgConverter) todoKeywords(seq string) {
+	fields := strings.Fields(seq)
+	bar := -1
+	for i, f := range fields {
+		if f == "|" {
+			bar = i
+		}
+	}
+	for i, f := range fields {
+		if f == "|" {
+			continue
+		}
+		// keywords may define fast access keys, like "TODO(t)"
+		f, _, _ = strings.Cut(f, "(")
+		done := i > bar && bar >= 0 || bar < 0 && i == len(fields)-1
+		c.todo[f] = done
+	}
+}
+
+func (c *orgConverter) blocks(lines []string) []string {
+	var (
+		out     []string
+		title   string
+		byline  []string
+		caption string
+	)
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		if caption != "" {
+			out = append(out, "**"+caption+"**")
+			caption = ""
+		}
+		out = append(out, s)
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		// comments
+		case trimmed == "#" || strings.HasPrefix(trimmed, "# "):
+			i++
+
+		case orgBlockBegin.MatchString(line):
+			m := orgBlockBegin.FindStringSubmatch(line)
+			end := i + 1
+			for end < len(lines) && !strings.EqualFold(strings.TrimSpace(lines[end]), "#+END_"+m[1]) {
+				end++
+			}
+			add(c.block(strings.ToUpper(m[1]), m[2], lines[i+1:min(end, len(lines))]))
+			i = end + 1
+
+		case orgKeyword.MatchString(line):
+			m := orgKeyword.FindStringSubmatch(line)
+			switch strings.ToUpper(m[1]) {
+			case "TITLE":
+				title = c.inline(m[2])
+			case "AUTHOR", "DATE":
+				if v := strings.TrimSpace(m[2]); v != "" {
+					byline = append(byline, c.inline(v))
+				}
+			case "CAPTION":
+				caption = c.inline(m[2])
+			}
+			i++
+
+		case orgHeadline.MatchString(line):
+			add(c.headline(line))
+			i++
+
+		case orgPlanning.MatchString(line):
+			add("*" + orgTimestamp.ReplaceAllString(trimmed, "$1") + "*")
+			i++
+
+		case orgDrawer.MatchString(line):
+			end := i + 1
+			for end < len(lines) && !orgDrawerEnd.MatchString(lines[end]) {
+				end++
+			}
+			if end == len(lines) || orgDrawerEnd.MatchString(line) {
+				// not a drawer after all
+				i++
+				break
+			}
+			if strings.EqualFold(orgDrawer.FindStringSubmatch(line)[1], "PROPERTIES") {
+				add(c.properties(lines[i+1 : end]))
+			}
+			// other drawers, like LOGBOOK, are hidden
+			i = end + 1
+
+		case orgRule.MatchString(line):
+			add("---")
+			i++
+
+		case orgFixedWidth.MatchString(line):
+			j := i
+			var code []string
+			for j < len(lines) && orgFixedWidth.MatchString(lines[j]) {
+				l := strings.TrimPrefix(strings.TrimSpace(lines[j]), ":")
+				code = append(code, strings.TrimPrefix(l, " "))
+				j++
+			}
+			add(codeFence(strings.Join(code, "\n"), ""))
+			i = j
+
+		case orgTableRow.MatchString(line):
+			j := i
+			for j < len(lines) && orgTableRow.MatchString(lines[j]) {
+				j++
+			}
+			add(c.table(lines[i:j]))
+			// formulas aren't shown
+			for j < len(lines) && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(lines[j])), "#+TBLFM:") {
+				j++
+			}
+			i = j
+
+		case orgListItem.MatchString(line):
+			var list string
+			list, i = c.list(lines, i)
+			add(list)
+
+		case orgFootnoteDef.MatchString(line):
+			m := orgFootnoteDef.FindStringSubmatch(line)
+			j := c.paragraphEnd(lines, i)
+			text := strings.Join(append([]string{m[2]}, lines[i+1:j]...), "\n")
+			add("[" + m[1] + "] " + c.paragraph(text))
+			i = j
+
+		default:
+			j := c.paragraphEnd(lines, i)
+			add(c.paragraph(strings.Join(lines[i:j], "\n")))
+			i = j
+		}
+	}
+
+	if title != "" || len(byline) > 0 {
+		var front []string
+		if title != "" {
+			front = append(front, "# "+title)
+		}
+		if len(byline) > 0 {
+			front = append(front, "*"+strings.Join(byline, " · ")+"*")
+		}
+		out = append(front, out...)
+	}
+	return out
+}
+
+// paragraphEnd returns the index of the line following the paragraph
+// starting at lines[i].
+func (c *orgConverter) paragraphEnd(lines []string, i int) int {
+	j := i + 1
+	for j < len(lines) {
+		l := lines[j]
+		if isBlank(l) || orgHeadline.MatchString(l) || orgBlockBegin.MatchString(l) ||
+			orgKeyword.MatchString(l) || orgTableRow.MatchString(l) || orgListItem.MatchString(l) ||
+			orgDrawer.MatchString(l) || orgFixedWidth.MatchString(l) {
+			break
+		}
+		j++
+	}
+	return j
+}
+
+// headline converts a headline. TODO keywords become checkboxes, and tags
+// are shown as code.
+func (c *orgConverter) headline(line string) string {
+	m := orgHeadline.FindStringSubmatch(line)
+	level := min(len(m[1])+c.levelOffset, 6)
+	text := m[2]
+
+	var tags string
+	if t := orgTags.FindStringSubmatch(text); t != nil {
+		tags = t[1]
+		text = strings.TrimSuffix(text, t[0])
+	} else if orgTags.MatchString(" " + text) {
+		// a headline with nothing but tags
+		tags, text = text, ""
+	}
+
+	var checkbox, keyword string
+	if kw, rest, _ := strings.Cut(text, " "); kw != "" {
+		if done, ok := c.todo[kw]; ok {
+			checkbox = "[ ] "
+			if done {
+				checkbox = "[x] "
+			}
+			if kw != "TODO" && kw != "DONE" {
+				keyword = "**" + kw + "** "
+			}
+			text = rest
+		}
+	}
+	if p := orgPriority.FindStringSubmatch(text); p != nil {
+		text = strings.TrimPrefix(text, p[0])
+		keyword += "`#" + p[1] + "` "
+	}
+
+	h := strings.Repeat("#", level) + " " + checkbox + keyword + c.inline(strings.TrimSpace(text))
+	if tags != "" {
+		h += " " + codeSpan(tags)
+	}
+	return strings.TrimRight(h, " ")
+}
+
+// properties renders the contents of a property drawer as a table.
+func (c *orgConverter) properties(lines []string) string {
+	var rows [][]string
+	for _, l := range lines {
+		if m := orgProperty.FindStringSubmatch(l); m != nil {
+			rows = append(rows, []string{m[1], c.inline(strings.TrimSpace(m[2]))})
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return markdownTable([]string{"Property", "Value"}, rows)
+}
+
+func (c *orgConverter) block(kind, args string, content []string) string {
+	switch kind {
+	case "SRC":
+		lang, _, _ := strings.Cut(strings.TrimSpace(args), " ")
+		return codeFence(strings.Join(unescapeOrgBlock(dedent(content)), "\n"), lang)
+
+	case "EXAMPLE":
+		return codeFence(strings.Join(unescapeOrgBlock(dedent(content)), "\n"), "")
+
+	case "EXPORT":
+		if format, _, _ := strings.Cut(strings.TrimSpace(args), " "); strings.EqualFold(format, "html") {
+			md, _ := HTML([]byte(strings.Join(content, "\n")))
+			return strings.TrimSpace(md)
+		}
+		return ""
+
+	case "COMMENT":
+		return ""
+
+	case "QUOTE":
+		return blockquote(strings.Join(c.blocks(dedent(content)), "\n\n"))
+
+	case "VERSE":
+		lines := dedent(content)
+		for i, l := range lines {
+			lines[i] = c.inline(l)
+		}
+		return blockquote(strings.Join(lines, "\\\n"))
+	}
+
+	inner := strings.Join(c.blocks(dedent(content)), "\n\n")
+	if label, ok := orgAdmonitions[kind]; ok {
+		return blockquote("**" + label + ":** " + inner)
+	}
+	// CENTER and custom blocks
+	return inner
+}
+
+// unescapeOrgBlock removes the commas escaping lines in blocks which would
+// otherwise be read as headlines or keywords.
+func unescapeOrgBlock(lines []string) []string {
+	for i, l := range lines {
+		trimmed := strings.TrimLeft(l, " ")
+		if strings.HasPrefix(trimmed, ",*") || strings.HasPrefix(trimmed, ",#+") {
+			lines[i] = l[:len(l)-len(trimmed)] + trimmed[1:]
+		}
+	}
+	return lines
+}
+
+// table converts a table. A rule after the first row makes it the header.
+func (c *orgConverter) table(lines []string) string {
+	var (
+		rows   [][]string
+		header bool
+	)
+	for _, l := range lines {
+		if orgTableRule.MatchString(l) {
+			if len(rows) == 1 {
+				header = true
+			}
+			continue
+		}
+		l = strings.TrimSpace(l)
+		l = strings.TrimSuffix(strings.TrimPrefix(l, "|"), "|")
+		cells := strings.Split(l, "|")
+		for i, cell := range cells {
+			cells[i] = c.inline(strings.TrimSpace(cell))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	if header {
+		return markdownTable(rows[0], rows[1:])
+	}
+	return markdownTable(nil, rows)
+}
+
+// list converts a list starting at lines[i]. Items belong to the list while
+// they're indented the same as its first item, and their content is
+// indented deeper than their marker. It returns the list and the index of
+// the line following it.
+func (c *orgConverter) list(lines []string, i int) (string, int) {
+	var (
+		items   []string
+		ordered bool
+		num     = 1
+	)
+	indent := indentOf(lines[i])
+	for n := 0; ; n++ {
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		// two blank lines end a list
+		if j >= len(lines) || j-i > 1 {
+			break
+		}
+
+		line := lines[j]
+		m := orgListItem.FindStringSubmatch(line)
+		if m == nil || indentOf(line) != indent {
+			break
+		}
+		isOrdered := m[2][len(m[2])-1] == '.' || m[2][len(m[2])-1] == ')'
+		if n == 0 {
+			ordered = isOrdered
+			if v, err := strconv.Atoi(m[2][:len(m[2])-1]); err == nil && ordered {
+				num = v
+			}
+		} else if isOrdered != ordered {
+			break
+		}
+
+		width := len(orgListMarker.FindString(line))
+		body := indentedBlock(lines, j+1, indent)
+		i = j + 1 + len(body)
+
+		text := m[3]
+		checkbox := ""
+		if cb := orgCheckbox.FindStringSubmatch(text); cb != nil {
+			checkbox = "[ ] "
+			if cb[1] == "x" || cb[1] == "X" {
+				checkbox = "[x] "
+			}
+			text = strings.TrimPrefix(text, cb[0])
+		}
+		if d := orgDescription.FindStringSubmatch(text); d != nil && !ordered {
+			text = "**" + d[1] + ":** " + d[2]
+		}
+
+		item := append([]string{strings.Repeat(" ", width) + text}, body...)
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		items = append(items, listItem(marker, checkbox+joinItemBlocks(c.blocks(dedent(item)))))
+	}
+	return strings.Join(items, "\n"), i
+}
+
+// paragraph converts the inline markup of a paragraph. Lines ending with
+// "\\" are hard line breaks.
+func (c *orgConverter) paragraph(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if orgLineBreak.MatchString(l) {
+			lines[i] = c.inline(orgLineBreak.ReplaceAllString(l, "")) + "\\"
+		} else {
+			lines[i] = c.inline(l)
+		}
+	}
+	return strings.TrimSuffix(strings.Join(lines, "\n"), "\\")
+}
+
+// inline converts org inline markup. Verbatim and code are converted first,
+// so their contents aren't touched.
+func (c *orgConverter) inline(s string) string {
+	var b strings.Builder
+	for {
+		// the leading and trailing context of the markers are part of the
+		// match, and the trailing context may lead the next match
+		m := orgVerbatim.FindStringSubmatchIndex(s)
+		if m == nil {
+			break
+		}
+		b.WriteString(c.inlineMarkup(s[:m[3]]))
+		b.WriteString(codeSpan(s[m[4]:m[5]]))
+		s = s[m[6]:]
+	}
+	b.WriteString(c.inlineMarkup(s))
+	return b.String()
+}
+
+func (c *orgConverter) inlineMarkup(s string) string {
+	s = orgMacro.ReplaceAllString(s, "")
+	s = orgFootnoteRef.ReplaceAllString(s, "[$1]")
+
+	// links are converted last, as their targets mustn't be touched
+	var links []string
+	s = orgLink.ReplaceAllStringFunc(s, func(match string) string {
+		m := orgLink.FindStringSubmatch(match)
+		links = append(links, c.link(m[1], m[2]))
+		return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+	})
+
+	for _, e := range orgEmphasis {
+		// twice, as adjacent matches share their context
+		s = e.re.ReplaceAllString(s, e.repl)
+		s = e.re.ReplaceAllString(s, e.repl)
+	}
+
+	for i, l := range links {
+		s = strings.Replace(s, "\x00"+strconv.Itoa(i)+"\x00", l, 1)
+	}
+	return s
+}
+
+// orgImageExtensions are the extensions of files which are shown as images
+// when they're linked without a description.
+var orgImageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp"}
+
+func (c *orgConverter) link(target, desc string) string {
+	desc = c.inlineMarkup(desc)
+	target = strings.TrimPrefix(target, "file:")
+
+	switch {
+	// internal links to headlines, custom ids and targets
+	case strings.HasPrefix(target, "*"), strings.HasPrefix(target, "#"), !strings.Contains(target, ":") &&
+		!strings.Contains(target, "/") && !strings.Contains(target, "."):
+		if desc != "" {
+			return desc
+		}
+		return strings.TrimLeft(target, "*#")
+	}
+
+	if desc == "" {
+		for _, ext := range orgImageExtensions {
+			if strings.HasSuffix(strings.ToLower(target), ext) {
+				return "![](" + linkDestination(target) + ")"
+			}
+		}
+		if strings.Contains(target, "://") {
+			return "<" + target + ">"
+		}
+		desc = target
+	}
+	return "[" + desc + "](" + linkDestination(target) + ")"
+}
@@ -0,0 +1,94 @@
+package convert
+
+import "testing"
+
+func TestOrg(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "headlines",
+			in:   "* One\n** Two\n*** Three :tag:\n",
+			want: "# One\n\n## Two\n\n### Three `:tag:`\n",
+		},
+		{
+			name: "title shifts headlines",
+			in:   "#+TITLE: Notes\n#+AUTHOR: Me\n\n* One\n",
+			want: "# Notes\n\n*Me*\n\n## One\n",
+		},
+		{
+			name: "todo keywords",
+			in:   "* TODO Write\n* DONE Ship\n* [#A] Plain\n",
+			want: "# [ ] Write\n\n# [x] Ship\n\n# `#A` Plain\n",
+		},
+		{
+			name: "custom todo keywords",
+			in:   "#+TODO: TODO(t) NEXT | DONE CANCELED\n* NEXT Review\n* CANCELED Idea\n",
+			want: "# [ ] **NEXT** Review\n\n# [x] **CANCELED** Idea\n",
+		},
+		{
+			name: "property drawer",
+			in:   "* Task\n  :PROPERTIES:\n  :ID: 42\n  :END:\n  :LOGBOOK:\n  - note\n  :END:\n",
+			want: "# Task\n\n| Property | Value |\n| --- | --- |\n| ID | 42 |\n",
+		},
+		{
+			name: "planning",
+			in:   "* Task\n  DEADLINE: <2024-05-01 Wed>\n",
+			want: "# Task\n\n*DEADLINE: 2024-05-01 Wed*\n",
+		},
+		{
+			name: "source block",
+			in:   "#+BEGIN_SRC python :results output\nprint(1)\n,* escaped\n#+END_SRC\n",
+			want: "```python\nprint(1)\n* escaped\n```\n",
+		},
+		{
+			name: "lowercase blocks",
+			in:   "#+begin_quote\nWise *words*.\n#+end_quote\n\n#+begin_example\nx\n#+end_example\n",
+			want: "> Wise **words**.\n\n```\nx\n```\n",
+		},
+		{
+			name: "inline markup",
+			in:   "*bold* /italic/ =verb= ~code~ +gone+ _under_ and a/b/c.\n",
+			want: "**bold** *italic* `verb` `code` ~~gone~~ _under_ and a/b/c.\n",
+		},
+		{
+			name: "links",
+			in:   "[[https://orgmode.org][Org]], [[https://x.org]], [[*Heading][see]] and [[file:img.png]].\n",
+			want: "[Org](https://orgmode.org), <https://x.org>, see and ![](img.png).\n",
+		},
+		{
+			name: "lists and checkboxes",
+			in:   "- one\n- [ ] open\n- [X] done\n  + nested\n- term :: definition\n\n1. first\n2) second\n",
+			want: "- one\n- [ ] open\n- [x] done\n  - nested\n- **term:** definition\n\n1. first\n2. second\n",
+		},
+		{
+			name: "empty list items",
+			in:   "- # a comment\n- #+BEGIN_COMMENT\n  hidden\n  #+END_COMMENT\n- three\n",
+			want: "-\n-\n- three\n",
+		},
+		{
+			name: "table",
+			in:   "| a | b |\n|---+---|\n| 1 | 2 |\n#+TBLFM: $2=$1\n",
+			want: "| a | b |\n| --- | --- |\n| 1 | 2 |\n",
+		},
+		{
+			name: "fixed width and comments",
+			in:   "# comment\n: fixed\n: width\n",
+			want: "```\nfixed\nwidth\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Org([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Org() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Org() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
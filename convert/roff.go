@@ -0,0 +1,1086 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Man pages are written in roff, using either the man(7) or the mdoc(7)
+// macros. Only what's needed to read them in a terminal is interpreted:
+// fonts, paragraphs, sections, lists, displays and tables. Other requests,
+// like spacing and indentation, are ignored.
+
+// maxMacroDepth limits how deeply macros and conditionals are nested, so
+// recursive definitions can't loop forever.
+const maxMacroDepth = 16
+
+// manPageExtension matches the extensions of man pages: a section, with
+// one of the suffixes distributions use for subsections, like "3p" or
+// "7ssl". Other suffixes are left out, since extensions like ".7z" or ".3gp"
+// belong to archives and media files.
+var manPageExtension = regexp.MustCompile(`^\.([1-9](p|x|m|t|o|pm|ssl|perl|tcl|tk|type|const|head|curses|ncurses|readline|edit|posix|bsd)?|man|mdoc)$`)
+
+// isManPage returns whether a file is a man page, like "ls.1" or a gzipped
+// "printf.3.gz". The n and l sections aren't recognized by their extension,
+// which lex sources share, but pages of the manpath are read as roff
+// regardless.
+func isManPage(filename string) bool {
+	filename = strings.ToLower(filename)
+	filename = strings.TrimSuffix(filename, ".gz")
+	return manPageExtension.MatchString(filepath.Ext(filename))
+}
+
+// Roff converts a man page into markdown. Both the man and the mdoc macros
+// are understood. Gzipped pages are decompressed first.
+func Roff(b []byte) (string, error) {
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", fmt.Errorf("unable to decompress man page: %w", err)
+		}
+		b, err = io.ReadAll(io.LimitReader(zr, maxArchiveFileSize))
+		if err != nil {
+			return "", fmt.Errorf("unable to decompress man page: %w", err)
+		}
+	}
+
+	c := &roffConverter{
+		strings: map[string]string{
+			"R": "®", "Tm": "™", "lq": "“", "rq": "”", "S": "",
+			// mdoc
+			"Ge": "≥", "Le": "≤", "Gt": ">", "Lt": "<", "Ne": "≠", "Pm": "±",
+			"If": "∞", "Pi": "π", "Na": "NaN", "Am": "&", "Ba": "|", "Lq": "“",
+			"Rq": "”", "q": "\"",
+		},
+		macros: map[string][]string{},
+	}
+	c.process(joinContinuedLines(string(b)), 0)
+	c.flushCode()
+	c.endItem()
+	c.mdocHeader()
+	return joinOfficeBlocks(c.blocks), nil
+}
+
+// joinContinuedLines splits roff source into lines, joining lines that end
+// in an escaped newline.
+func joinContinuedLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var lines []string
+	var cont strings.Builder
+	for _, l := range strings.Split(s, "\n") {
+		trailing := len(l) - len(strings.TrimRight(l, `\`))
+		if trailing%2 == 1 {
+			cont.WriteString(l[:len(l)-1])
+			continue
+		}
+		cont.WriteString(l)
+		lines = append(lines, cont.String())
+		cont.Reset()
+	}
+	if cont.Len() > 0 {
+		lines = append(lines, cont.String())
+	}
+	return lines
+}
+
+type roffConverter struct {
+	blocks []officeBlock
+
+	// markdown of the paragraph being filled
+	para []string
+	// whether the next text is joined to the paragraph without a space
+	join bool
+	// font set with \f escapes, which lasts across lines
+	font, prevFont textRun
+	// font of the next text line, set by font macros without arguments
+	nextFont *textRun
+
+	// list item being built and its content
+	item      *officeBlock
+	itemTag   string
+	itemParts []itemPart
+	// whether the next line is the tag of the item, like after .TP
+	inTag bool
+	// heading level of the next line, after .SH without arguments
+	nextHeading int
+	// indentation depth set with .RS
+	depth int
+
+	// lines of no-fill mode, which are shown as code
+	noFill bool
+	code   []string
+
+	// start of a link's text in the paragraph, and its URL
+	linkStart int
+	linkURL   string
+
+	strings       map[string]string
+	macros        map[string][]string
+	lastCondition bool
+
+	// mdoc state
+	mdoc mdocState
+}
+
+type itemPart struct {
+	markdown string
+	code     bool
+}
+
+// isRequest returns whether a line is a request or macro call rather than
+// text.
+func isRequest(line string) bool {
+	return strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'")
+}
+
+// splitRequest returns the name and the arguments of a request line.
+func splitRequest(line string) (string, string) {
+	s := strings.TrimLeft(line[1:], " \t")
+	if strings.HasPrefix(s, `\"`) {
+		return "", ""
+	}
+	end := strings.IndexAny(s, " \t")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end+1:]
+}
+
+// parseArgs splits the arguments of a request. Arguments are separated by
+// spaces, unless quoted; "" is a quote within a quoted argument.
+func parseArgs(s string) []string {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || strings.HasPrefix(s, `\"`) {
+			return args
+		}
+
+		var b strings.Builder
+		i := 0
+		if s[0] == '"' {
+			for i = 1; i < len(s); i++ {
+				if s[i] == '"' {
+					if i+1 < len(s) && s[i+1] == '"' {
+						b.WriteByte('"')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(s[i])
+			}
+		} else {
+			for ; i < len(s) && s[i] != ' ' && s[i] != '\t'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					if s[i+1] == '"' {
+						args = append(args, b.String())
+						return args
+					}
+					b.WriteByte(s[i])
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+		}
+		args = append(args, b.String())
+		s = s[i:]
+	}
+}
+
+func (c *roffConverter) process(lines []string, depth int) {
+	if depth > maxMacroDepth {
+		return
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if !isRequest(line) {
+			c.text(line)
+			c.captureLine()
+			continue
+		}
+
+		name, rest := splitRequest(line)
+		switch name {
+		case "de", "de1", "am", "ig":
+			// macro definitions and ignored blocks end at ".." or the
+			// given end macro
+			args := parseArgs(rest)
+			end := ".."
+			switch {
+			case name == "ig" && len(args) > 0:
+				end = "." + args[0]
+			case name != "ig" && len(args) > 1:
+				end = "." + args[1]
+			}
+			var body []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != end; i++ {
+				body = append(body, lines[i])
+			}
+			if name != "ig" && len(args) > 0 {
+				if name == "am" {
+					body = append(c.macros[args[0]], body...)
+				}
+				c.macros[args[0]] = body
+			}
+
+		case "TS":
+			var table []string
+			for i++; i < len(lines); i++ {
+				if isRequest(lines[i]) {
+					if n, _ := splitRequest(lines[i]); n == "TE" {
+						break
+					}
+				}
+				table = append(table, lines[i])
+			}
+			c.table(table)
+
+		case "if", "ie", "el":
+			var cond bool
+			body := rest
+			switch name {
+			case "if":
+				cond, body = c.condition(rest)
+			case "ie":
+				cond, body = c.condition(rest)
+				c.lastCondition = cond
+			case "el":
+				cond = !c.lastCondition
+			}
+			body = strings.TrimLeft(body, " \t")
+			block := []string{body}
+			if strings.HasPrefix(body, `\{`) {
+				open := strings.Count(body, `\{`) - strings.Count(body, `\}`)
+				for open > 0 && i+1 < len(lines) {
+					i++
+					block = append(block, lines[i])
+					open += strings.Count(lines[i], `\{`) - strings.Count(lines[i], `\}`)
+				}
+			}
+			if cond {
+				c.process(conditionalBlock(block), depth+1)
+			}
+
+		default:
+			c.request(name, rest, depth)
+			c.captureLine()
+		}
+	}
+}
+
+// conditionalBlock removes the braces of a conditional block, as well as
+// the lines that only held braces.
+func conditionalBlock(block []string) []string {
+	braces := strings.NewReplacer(`\{`, "", `\}`, "")
+	var lines []string
+	for _, l := range block {
+		stripped := strings.TrimLeft(braces.Replace(l), " \t")
+		if stripped == "" && l != "" {
+			continue
+		}
+		lines = append(lines, stripped)
+	}
+	return lines
+}
+
+// captureLine turns the output of a line into an item tag or a heading,
+// when the previous line asked for it.
+func (c *roffConverter) captureLine() {
+	if len(c.para) == 0 {
+		return
+	}
+	switch {
+	case c.inTag && !c.mdoc.tagUntilXc:
+		tag := joinPieces(c.para)
+		c.para, c.join, c.inTag = nil, false, false
+		if c.itemTag != "" {
+			c.itemTag += ", " + tag
+		} else {
+			c.itemTag = tag
+		}
+	case c.nextHeading > 0:
+		heading := strings.Repeat("#", c.nextHeading) + " " + joinPieces(c.para)
+		c.para, c.join, c.nextHeading = nil, false, 0
+		c.blocks = append(c.blocks, officeBlock{markdown: heading})
+	}
+}
+
+// text handles a line of text.
+func (c *roffConverter) text(line string) {
+	if c.noFill {
+		c.code = append(c.code, c.plain(line))
+		return
+	}
+	if isBlank(line) {
+		// blank lines separate paragraphs
+		c.flushParagraph()
+		return
+	}
+	if strings.HasPrefix(line, " ") && len(c.para) > 0 {
+		// indented lines start on a new line
+		c.write("\\\n", true)
+		c.join = true
+	}
+
+	font := &c.font
+	if c.nextFont != nil {
+		font = c.nextFont
+		c.nextFont = nil
+	}
+	join := strings.HasSuffix(line, `\c`)
+	c.write(runsToMarkdown(c.runs(strings.TrimSuffix(line, `\c`), font)), false)
+	c.join = join
+}
+
+// write adds markdown to the paragraph being filled.
+func (c *roffConverter) write(md string, attach bool) {
+	if md == "" {
+		return
+	}
+	if (attach || c.join) && len(c.para) > 0 {
+		c.para[len(c.para)-1] = joinAttached(c.para[len(c.para)-1], md)
+	} else {
+		c.para = append(c.para, md)
+	}
+	c.join = false
+}
+
+// joinAttached joins two pieces of markdown without a space. Emphasis
+// markers can't touch, so emphasis of the same kind is merged and
+// underscores are used otherwise.
+func joinAttached(a, b string) string {
+	am, bm := emphasisMarker(a, false), emphasisMarker(b, true)
+	switch {
+	case am == "" || bm == "":
+		return a + b
+	case am == bm:
+		return a[:len(a)-len(am)] + b[len(bm):]
+	case emphasisMarker(b, false) == bm && len(b) > 2*len(bm):
+		under := strings.Repeat("_", len(bm))
+		return a + under + b[len(bm):len(b)-len(bm)] + under
+	}
+	return a + b
+}
+
+// emphasisMarker returns the unescaped asterisks at the start or the end
+// of s.
+func emphasisMarker(s string, start bool) string {
+	var n int
+	if start {
+		n = len(s) - len(strings.TrimLeft(s, "*"))
+	} else {
+		n = len(s) - len(strings.TrimRight(s, "*"))
+		if n < len(s) && s[len(s)-n-1] == '\\' {
+			n = 0
+		}
+	}
+	if n == 0 || n > 2 {
+		return ""
+	}
+	return strings.Repeat("*", n)
+}
+
+func joinPieces(pieces []string) string {
+	return strings.TrimSpace(strings.ReplaceAll(strings.Join(pieces, " "), "\\\n ", "\\\n"))
+}
+
+// flushParagraph ends the paragraph being filled.
+func (c *roffConverter) flushParagraph() {
+	md := joinPieces(c.para)
+	c.para, c.join = nil, false
+	if md != "" {
+		c.addBlock(md, false)
+	}
+}
+
+// flushCode ends a display of no-fill lines.
+func (c *roffConverter) flushCode() {
+	for len(c.code) > 0 && isBlank(c.code[len(c.code)-1]) {
+		c.code = c.code[:len(c.code)-1]
+	}
+	if len(c.code) > 0 {
+		c.addBlock(codeFence(strings.Join(c.code, "\n"), ""), true)
+	}
+	c.code = nil
+}
+
+// addBlock adds a block to the list item being built, or to the document.
+func (c *roffConverter) addBlock(md string, code bool) {
+	if c.item != nil {
+		c.itemParts = append(c.itemParts, itemPart{md, code})
+		return
+	}
+	c.blocks = append(c.blocks, officeBlock{markdown: md})
+}
+
+// startItem starts a list item. Its tag, if any, is set separately.
+func (c *roffConverter) startItem(level int, ordered bool) {
+	c.flushParagraph()
+	c.endItem()
+	c.item = &officeBlock{listItem: true, listLevel: level, ordered: ordered}
+}
+
+// endItem ends the list item being built. Its paragraphs are separated by
+// line breaks, as glamour doesn't separate paragraphs in list items.
+func (c *roffConverter) endItem() {
+	c.flushParagraph()
+	if c.item == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(c.itemTag)
+	prevCode := false
+	for i, p := range c.itemParts {
+		if i > 0 || c.itemTag != "" {
+			if p.code || prevCode {
+				b.WriteString("\n\n")
+			} else {
+				b.WriteString("\\\n")
+			}
+		}
+		b.WriteString(p.markdown)
+		prevCode = p.code
+	}
+	item := *c.item
+	item.markdown = b.String()
+	c.item, c.itemTag, c.itemParts, c.inTag = nil, "", nil, false
+	if item.markdown != "" {
+		c.blocks = append(c.blocks, item)
+	}
+}
+
+// heading adds a section heading, which ends any list.
+func (c *roffConverter) heading(level int, md string) {
+	c.flushParagraph()
+	c.flushCode()
+	c.endItem()
+	c.depth = 0
+	c.noFill = false
+	if md == "" {
+		c.nextHeading = level
+		return
+	}
+	c.blocks = append(c.blocks, officeBlock{markdown: strings.Repeat("#", level) + " " + md})
+}
+
+// argsMarkdown formats the arguments of a macro as text.
+func (c *roffConverter) argsMarkdown(args []string, font textRun) string {
+	return runsToMarkdown(c.runs(strings.Join(args, " "), &font))
+}
+
+// request handles a request or macro call.
+func (c *roffConverter) request(name, rest string, depth int) {
+	args := parseArgs(rest)
+	if c.mdocRequest(name, rest, args) {
+		return
+	}
+
+	switch name {
+	case "TH":
+		c.flushParagraph()
+		title := ""
+		if len(args) > 0 {
+			title = c.argsMarkdown(args[:1], textRun{})
+			if len(args) > 1 {
+				title += "(" + c.argsMarkdown(args[1:2], textRun{}) + ")"
+			}
+		}
+		if title != "" {
+			c.blocks = append(c.blocks, officeBlock{markdown: "# " + title})
+		}
+		// the manual, its source and the date
+		var byline []string
+		for _, i := range []int{4, 3, 2} {
+			if i < len(args) {
+				if md := c.argsMarkdown(args[i:i+1], textRun{}); md != "" {
+					byline = append(byline, md)
+				}
+			}
+		}
+		if len(byline) > 0 {
+			c.blocks = append(c.blocks, officeBlock{markdown: "*" + strings.Join(byline, " · ") + "*"})
+		}
+
+	case "SH":
+		c.heading(2, c.argsMarkdown(args, textRun{}))
+	case "SS":
+		c.heading(3, c.argsMarkdown(args, textRun{}))
+
+	case "PP", "P", "LP", "HP":
+		c.flushParagraph()
+		if c.depth == 0 {
+			c.endItem()
+		}
+
+	case "TP":
+		c.startItem(c.depth, false)
+		c.inTag = true
+	case "TQ":
+		c.flushParagraph()
+		c.inTag = true
+
+	case "IP":
+		tag := ""
+		if len(args) > 0 {
+			tag = strings.TrimSpace(c.plain(args[0]))
+		}
+		switch {
+		case tag == "":
+			// a paragraph belonging to the current item, if any
+			c.flushParagraph()
+		case strings.Contains(roffBullets, tag):
+			c.startItem(c.depth, false)
+		case isOrdinal(tag):
+			c.startItem(c.depth, true)
+		default:
+			c.startItem(c.depth, false)
+			c.itemTag = c.argsMarkdown(args[:1], textRun{})
+		}
+
+	case "RS":
+		c.flushParagraph()
+		c.depth++
+	case "RE":
+		c.flushParagraph()
+		c.depth = max(0, c.depth-1)
+		if c.item != nil && c.item.listLevel > c.depth {
+			c.endItem()
+		}
+
+	case "B", "I", "SB", "SM":
+		var font textRun
+		font.bold = name == "B" || name == "SB"
+		font.italic = name == "I"
+		if len(args) == 0 {
+			c.nextFont = &font
+			return
+		}
+		if c.noFill {
+			c.code = append(c.code, c.plain(strings.Join(args, " ")))
+			return
+		}
+		c.write(c.argsMarkdown(args, font), false)
+
+	case "BI", "BR", "IB", "IR", "RB", "RI":
+		fonts := [2]textRun{roffFont(name[:1]), roffFont(name[1:])}
+		if c.noFill {
+			c.code = append(c.code, c.plain(strings.Join(args, "")))
+			return
+		}
+		var runs []textRun
+		for i, a := range args {
+			font := fonts[i%2]
+			runs = append(runs, c.runs(a, &font)...)
+		}
+		c.write(runsToMarkdown(runs), false)
+
+	case "nf", "EX":
+		c.flushParagraph()
+		c.noFill = true
+	case "fi", "EE":
+		c.flushCode()
+		c.noFill = false
+
+	case "br":
+		if c.noFill {
+			return
+		}
+		if len(c.para) > 0 {
+			c.write("\\\n", true)
+			c.join = true
+		}
+	case "sp":
+		if c.noFill {
+			c.code = append(c.code, "")
+			return
+		}
+		c.flushParagraph()
+
+	case "UR", "MT":
+		c.linkStart = len(c.para)
+		if len(args) > 0 {
+			c.linkURL = args[0]
+			if name == "MT" {
+				c.linkURL = "mailto:" + args[0]
+			}
+		}
+	case "UE", "ME":
+		if c.linkURL == "" {
+			return
+		}
+		text := joinPieces(c.para[min(c.linkStart, len(c.para)):])
+		if text == "" {
+			text = escapeMarkdown(strings.TrimPrefix(c.linkURL, "mailto:"))
+		}
+		c.para = append(c.para[:min(c.linkStart, len(c.para))], "["+text+"]("+linkDestination(c.linkURL)+")")
+		c.linkURL = ""
+		if len(args) > 0 {
+			c.write(c.argsMarkdown(args, textRun{}), true)
+		}
+
+	case "SY":
+		c.flushParagraph()
+		c.write(c.argsMarkdown(args, textRun{bold: true}), false)
+	case "OP":
+		if len(args) > 0 {
+			opt := c.argsMarkdown(args[:1], textRun{bold: true})
+			if len(args) > 1 {
+				opt += " " + c.argsMarkdown(args[1:], textRun{italic: true})
+			}
+			c.write("\\["+opt+"\\]", false)
+		}
+	case "YS":
+		c.flushParagraph()
+
+	case "ft":
+		if len(args) == 0 || args[0] == "P" {
+			c.font, c.prevFont = c.prevFont, c.font
+			return
+		}
+		c.font, c.prevFont = roffFont(args[0]), c.font
+
+	case "ds", "as":
+		if len(args) == 0 {
+			return
+		}
+		_, value, _ := strings.Cut(strings.TrimLeft(rest, " \t"), args[0])
+		value = strings.TrimPrefix(strings.TrimLeft(value, " \t"), `"`)
+		if name == "as" {
+			value = c.strings[args[0]] + value
+		}
+		c.strings[args[0]] = value
+
+	default:
+		// user defined macros
+		if body, ok := c.macros[name]; ok {
+			c.process(expandMacro(body, args), depth+1)
+		}
+	}
+}
+
+// roffBullets are the tags of bullet list items.
+const roffBullets = "•*-o·○◦▪■–—"
+
+func isOrdinal(s string) bool {
+	s = strings.TrimRight(s, ".)")
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// expandMacro substitutes the arguments of a macro call into its body.
+func expandMacro(body, args []string) []string {
+	lines := make([]string, len(body))
+	for i, l := range body {
+		l = macroArg.ReplaceAllStringFunc(l, func(s string) string {
+			switch name := strings.Trim(s[strings.LastIndex(s, "$")+1:], "[]"); name {
+			case "*", "@":
+				return strings.Join(args, " ")
+			default:
+				n, err := strconv.Atoi(name)
+				if err != nil || n < 1 || n > len(args) {
+					return ""
+				}
+				return args[n-1]
+			}
+		})
+		lines[i] = strings.ReplaceAll(l, `\\`, `\`)
+	}
+	return lines
+}
+
+var macroArg = regexp.MustCompile(`\\\\?\$(\d|\*|@|\[\d+\])`)
+
+// condition evaluates the condition of an .if or .ie request as a terminal
+// formatter would, and returns the rest of the line.
+func (c *roffConverter) condition(s string) (bool, string) {
+	s = strings.TrimLeft(s, " \t")
+	negate := strings.HasPrefix(s, "!")
+	s = strings.TrimPrefix(s, "!")
+	if s == "" {
+		return false, ""
+	}
+
+	var result bool
+	isName := len(s) == 1 || !isAlphanumeric(s[1])
+	switch {
+	case strings.ContainsRune("ntoe", rune(s[0])) && isName:
+		// nroff and odd pages
+		result = s[0] == 'n' || s[0] == 'o'
+		s = s[1:]
+	case strings.ContainsRune("drcmFSv", rune(s[0])) && len(s) > 1 && (s[1] == ' ' || s[1] == '\t'):
+		name, rest, _ := strings.Cut(strings.TrimLeft(s[1:], " \t"), " ")
+		_, isString := c.strings[name]
+		_, isMacro := c.macros[name]
+		result = s[0] == 'd' && (isString || isMacro)
+		s = rest
+	case !isAlphanumeric(s[0]) && s[0] != '(' && s[0] != '\\' && s[0] != '-' && s[0] != '+':
+		// string comparison, like 'a'b'
+		delim := s[:1]
+		parts := strings.SplitN(s[1:], delim, 3)
+		if len(parts) < 3 {
+			return false, ""
+		}
+		result = c.plain(parts[0]) == c.plain(parts[1])
+		s = parts[2]
+	default:
+		expr, rest, _ := strings.Cut(s, " ")
+		result = evalRoffNumber(expr) > 0
+		s = rest
+	}
+	return result != negate, s
+}
+
+func isAlphanumeric(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+var numberRegister = regexp.MustCompile(`\\n(\(..|\[[^\]]*\]|.)`)
+
+// evalRoffNumber evaluates a numeric expression. roff evaluates operators
+// from left to right, without precedence. Of the registers only .g, which
+// tells whether the formatter is groff, is set.
+func evalRoffNumber(expr string) int {
+	expr = numberRegister.ReplaceAllStringFunc(expr, func(s string) string {
+		if strings.Trim(s[2:], "([]") == ".g" {
+			return "1"
+		}
+		return "0"
+	})
+	v, _ := evalRoffExpr(expr)
+	return v
+}
+
+func evalRoffExpr(s string) (int, string) {
+	v, s := evalRoffTerm(s)
+	for s != "" {
+		var op string
+		for _, o := range []string{"<=", ">=", "==", "<", ">", "=", "+", "-", "*", "/", "%", "&", ":"} {
+			if strings.HasPrefix(s, o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return v, s
+		}
+		var w int
+		w, s = evalRoffTerm(s[len(op):])
+		v = roffOperation(v, op, w)
+	}
+	return v, s
+}
+
+func roffOperation(v int, op string, w int) int {
+	b := func(ok bool) int {
+		if ok {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case "<=":
+		return b(v <= w)
+	case ">=":
+		return b(v >= w)
+	case "==", "=":
+		return b(v == w)
+	case "<":
+		return b(v < w)
+	case ">":
+		return b(v > w)
+	case "+":
+		return v + w
+	case "-":
+		return v - w
+	case "*":
+		return v * w
+	case "/", "%":
+		if w == 0 {
+			return 0
+		}
+		if op == "/" {
+			return v / w
+		}
+		return v % w
+	case "&":
+		return b(v > 0 && w > 0)
+	default:
+		return b(v > 0 || w > 0)
+	}
+}
+
+func evalRoffTerm(s string) (int, string) {
+	if strings.HasPrefix(s, "(") {
+		v, rest := evalRoffExpr(s[1:])
+		return v, strings.TrimPrefix(rest, ")")
+	}
+	sign := 1
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+	s = strings.TrimPrefix(s, "+")
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	v, _ := strconv.ParseFloat(s[:i], 64)
+	// units like 1v or 2n
+	for i < len(s) && strings.ContainsRune("icpPmnvuMsf", rune(s[i])) {
+		i++
+	}
+	return sign * int(v), s[i:]
+}
+
+// roffFont returns the formatting of a font, like "B" or "CW".
+func roffFont(name string) textRun {
+	switch strings.ToUpper(name) {
+	case "B", "3", "BD":
+		return textRun{bold: true}
+	case "I", "2", "IT":
+		return textRun{italic: true}
+	case "BI", "4":
+		return textRun{bold: true, italic: true}
+	case "C", "CW", "CR", "CB", "CI", "CBI", "CO", "CS", "L":
+		return textRun{code: true}
+	}
+	return textRun{}
+}
+
+var roffString = regexp.MustCompile(`\\\*(\(..|\[[^\]]*\]|.)`)
+
+// expandStrings substitutes defined strings, like \*(lq.
+func (c *roffConverter) expandStrings(s string) string {
+	for range 4 {
+		if !strings.Contains(s, `\*`) {
+			break
+		}
+		s = roffString.ReplaceAllStringFunc(s, func(m string) string {
+			name := m[2:]
+			switch {
+			case strings.HasPrefix(name, "("):
+				name = name[1:]
+			case strings.HasPrefix(name, "["):
+				name = strings.Fields(strings.Trim(name, "[]") + " ")[0]
+			}
+			return c.strings[name]
+		})
+	}
+	return s
+}
+
+// runs resolves the escapes of roff text into runs of formatted text. The
+// font, which font escapes change, is updated.
+func (c *roffConverter) runs(s string, font *textRun) []textRun {
+	s = c.expandStrings(s)
+
+	var (
+		runs []textRun
+		b    strings.Builder
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			t := *font
+			t.text = b.String()
+			runs = append(runs, t)
+			b.Reset()
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			break
+		}
+		i++
+		switch esc := s[i]; esc {
+		case '"', '#':
+			// comments
+			flush()
+			return runs
+		case 'f':
+			name, n := escapeName(s[i+1:])
+			i += n
+			flush()
+			prev := *font
+			if name == "P" || name == "" {
+				*font = c.prevFont
+			} else {
+				*font = roffFont(name)
+			}
+			c.prevFont = prev
+		case '(', '[':
+			name, n := escapeName(s[i:])
+			i += n - 1
+			b.WriteString(roffSpecial(name))
+		case 'C':
+			name, n := delimitedArg(s[i+1:])
+			i += n
+			b.WriteString(roffSpecial(name))
+		case 'N':
+			arg, n := delimitedArg(s[i+1:])
+			i += n
+			if code, err := strconv.Atoi(arg); err == nil && code > 0 {
+				b.WriteRune(rune(code))
+			}
+		case 'e', '\\', 'E':
+			b.WriteByte('\\')
+		case '-':
+			b.WriteByte('-')
+		case ' ', '0', '~':
+			b.WriteByte(' ')
+		case '.', '\'', '`':
+			b.WriteByte(esc)
+		case 't':
+			b.WriteByte('\t')
+		case 'n', 'm', 'M', 'g', 'Y', 'V', '*':
+			_, n := escapeName(s[i+1:])
+			i += n
+		case 's':
+			i += sizeEscapeLength(s[i+1:])
+		case 'h', 'v', 'w', 'l', 'L', 'D', 'X', 'o', 'b', 'Z', 'x', 'R', 'S', 'A', 'B', 'H':
+			_, n := delimitedArg(s[i+1:])
+			i += n
+		case 'k', 'z':
+			if esc == 'k' {
+				i++
+			}
+		default:
+			// \& \| \^ \c \) \, \/ \: \p and other zero-width escapes
+		}
+	}
+	flush()
+	return runs
+}
+
+// plain resolves the escapes of roff text into plain text.
+func (c *roffConverter) plain(s string) string {
+	var font textRun
+	saved := c.prevFont
+	var b strings.Builder
+	for _, r := range c.runs(s, &font) {
+		b.WriteString(r.text)
+	}
+	c.prevFont = saved
+	return b.String()
+}
+
+// escapeName reads the name of an escape like \fB, \f(CW or \f[BI] and
+// returns it with the number of bytes read.
+func escapeName(s string) (string, int) {
+	switch {
+	case s == "":
+		return "", 0
+	case s[0] == '(':
+		if len(s) < 3 {
+			return "", len(s)
+		}
+		return s[1:3], 3
+	case s[0] == '[':
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return s[1:], len(s)
+		}
+		return s[1:end], end + 1
+	}
+	return s[:1], 1
+}
+
+// delimitedArg reads the argument of an escape like \w'text' and returns it
+// with the number of bytes read.
+func delimitedArg(s string) (string, int) {
+	if s == "" {
+		return "", 0
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return s[1:], len(s)
+	}
+	return s[1 : end+1], end + 2
+}
+
+// sizeEscapeLength returns the length of the argument of a size escape,
+// like \s-1, \s+(12 or \s[10].
+func sizeEscapeLength(s string) int {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '(':
+		return min(len(s), i+3)
+	case i < len(s) && (s[i] == '[' || s[i] == '\''):
+		_, n := delimitedArg(strings.Replace(s[i:], "[", "]", 1))
+		return i + n
+	}
+	if i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+		if i < len(s) && s[i-1] >= '1' && s[i-1] <= '3' && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	return i
+}
+
+var roffSpecials = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "−", "pl": "+", "eq": "=",
+	"bu": "•", "lq": "“", "rq": "”", "oq": "‘", "cq": "’", "aq": "'",
+	"dq": "\"", "Fo": "«", "Fc": "»", "fo": "‹", "fc": "›", "co": "©",
+	"rg": "®", "tm": "™", "->": "→", "<-": "←", "<>": "↔", "=>": "⇒",
+	"lA": "⇐", "rA": "⇒", "hA": "⇔", "ua": "↑", "da": "↓", "<=": "≤",
+	">=": "≥", "!=": "≠", "==": "≡", "~=": "≅", "~~": "≈", "+-": "±",
+	"mu": "×", "di": "÷", "de": "°", "sc": "§", "ps": "¶", "dg": "†",
+	"dd": "‡", "ga": "`", "aa": "´", "ha": "^", "ti": "~", "rs": "\\",
+	"sl": "/", "ba": "|", "br": "│", "bv": "│", "ul": "_", "ru": "_",
+	"fm": "′", "sd": "″", "sq": "□", "ci": "○", "ct": "¢", "Po": "£",
+	"Eu": "€", "eu": "€", "Ye": "¥", "ss": "ß", "OK": "✓", "la": "⟨",
+	"ra": "⟩", "lB": "[", "rB": "]", "lC": "{", "rC": "}", "r!": "¡",
+	"r?": "¿", "pc": "·", "es": "∅", "if": "∞", "mo": "∈", "no": "¬",
+	"AN": "∧", "OR": "∨", "12": "½", "14": "¼", "34": "¾", "S1": "¹",
+	"S2": "²", "S3": "³", "at": "@", "sh": "#", "Do": "$", "**": "∗",
+	"md": "⋅", "tf": "∴", "ss ": "ß", "ae": "æ", "AE": "Æ", "oe": "œ",
+	"OE": "Œ", "/o": "ø", "/O": "Ø", "lh": "☜", "rh": "☞", "ho": "˛",
+}
+
+var roffAccents = map[byte]string{
+	'\'': "́", '`': "̀", '^': "̂", ':': "̈",
+	'~': "̃", ',': "̧", 'o': "̊", 'v': "̌",
+}
+
+// roffSpecial returns a special character, like \(em, \[u2014] or \['e].
+func roffSpecial(name string) string {
+	if s, ok := roffSpecials[name]; ok {
+		return s
+	}
+	if accent, ok := roffAccents[name[0]]; ok && len(name) == 2 {
+		return norm.NFC.String(name[1:] + accent)
+	}
+	if hex, ok := strings.CutPrefix(name, "u"); ok && len(hex) >= 4 {
+		var b strings.Builder
+		for _, part := range strings.Split(hex, "_") {
+			code, err := strconv.ParseUint(part, 16, 32)
+			if err != nil {
+				return ""
+			}
+			b.WriteRune(rune(code))
+		}
+		return norm.NFC.String(b.String())
+	}
+	if code, ok := strings.CutPrefix(name, "char"); ok {
+		if n, err := strconv.Atoi(code); err == nil {
+			return string(rune(n))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestRoff(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "man title and sections",
+			in:   ".TH LS 1 2024-01-02 \"GNU coreutils\" \"User Commands\"\n.SH NAME\nls \\- list files\n.SS Options\n",
+			want: "# LS(1)\n\n*User Commands · GNU coreutils · 2024-01-02*\n\n## NAME\n\nls - list files\n\n### Options\n",
+		},
+		{
+			name: "fonts",
+			in:   ".B bold words\n.BR ls (1),\nsee \\fIfile\\fP and \\f(CWcode\\fR.\n.I\nnext line\n",
+			want: "**bold words** **ls**(1), see *file* and `code`. *next line*\n",
+		},
+		{
+			name: "paragraphs and breaks",
+			in:   "one\ntwo\n.PP\nthree\n.br\nfour\n\nfive\n",
+			want: "one two\n\nthree\\\nfour\n\nfive\n",
+		},
+		{
+			name: "tagged paragraphs",
+			in:   ".TP\n.BR \\-a \", \" \\-\\-all\ndo not ignore\n.TP\n\\fB\\-l\\fR\nlong\n.IP\nmore\n.PP\nafter\n",
+			want: "- **-a**, **--all**\\\n  do not ignore\n- **-l**\\\n  long\\\n  more\n\nafter\n",
+		},
+		{
+			name: "indented and bullet lists",
+			in:   ".IP \\(bu 2\nfirst\n.IP \\(bu\nsecond\n.RS\n.IP 1.\nnested\n.RE\n.PP\nend\n",
+			want: "- first\n- second\n  1. nested\n\nend\n",
+		},
+		{
+			name: "no-fill display",
+			in:   "Example:\n.PP\n.nf\n.RS\n$ ls \\-l\n\n\\fBtotal\\fR 0\n.RE\n.fi\n",
+			want: "Example:\n\n```\n$ ls -l\n\ntotal 0\n```\n",
+		},
+		{
+			name: "special characters and escapes",
+			in:   "\\(lqquoted\\(rq \\(em \\[u00E9]t\\['e] a\\ b \\e \\*(Tm x\\c\ny \\s-1small\\s0 \\\" comment\n",
+			want: "“quoted” — été a b \\\\ ™ xy small\n",
+		},
+		{
+			name: "strings, macros and conditionals",
+			in:   ".ds V 1.2\n.de Hi\nHello \\\\$1!\n..\n.Hi world\nversion \\*V\n.ie n terminal\n.el print\n.if t \\{\\\ntypeset\n.\\}\n.if !'a'b' different\n.if \\n(.g>0 groff\n",
+			want: "Hello world! version 1.2 terminal different groff\n",
+		},
+		{
+			name: "links",
+			in:   ".UR https://example.com\nthe site\n.UE .\n.MT me@example.com\n.ME\n",
+			want: "[the site](https://example.com). [me@example.com](mailto:me@example.com)\n",
+		},
+		{
+			name: "table",
+			in:   ".TS\ntab(;);\nl l.\nName;Value\n_\n\\fBa\\fR;1|2\nT{\nlong\ntext\nT};3\n.TE\n",
+			want: "| Name | Value |\n| --- | --- |\n| **a** | 1\\|2 |\n| long text | 3 |\n",
+		},
+		{
+			name: "mdoc",
+			in: ".Dd $Mdocdate: March 1 2024 $\n.Dt TOOL 1\n.Os\n.Sh NAME\n.Nm tool\n.Nd do things\n.Sh SYNOPSIS\n.Nm\n.Op Fl v\n.Ar file ...\n" +
+				".Nm\n.Fl h\n.Sh DESCRIPTION\nThe\n.Nm\nutility, see\n.Xr ls 1 .\n.Bl -tag -width Ds\n.It Fl v\nBe verbose.\n.It Fl o Ar out\nWrite to\n.Pa out .\n.El\n" +
+				".Bl -bullet\n.It\none\n.It\ntwo\n.El\n.Bd -literal\n$ tool \\-v\n.Ed\n.Pp\nSee\n.Dq quoted\nand\n.Ql code ,\nset\n.Ev HOME\nand\n.Lk https://example.com site .\n.Ex -std\n",
+			want: "# TOOL(1)\n\n*March 1 2024*\n\n## NAME\n\n**tool** — do things\n\n## SYNOPSIS\n\n**tool** \\[**-v**\\] *file* *...*\n\n**tool** **-h**\n\n## DESCRIPTION\n\nThe **tool** utility, see **ls**(1).\n\n- **-v**\\\n  Be verbose.\n- **-o** *out*\\\n  Write to *out*.\n\n- one\n- two\n\n```\n$ tool -v\n```\n\nSee “quoted” and `code`, set `HOME` and [site](https://example.com). The **tool** utility exits 0 on success, and >0 if an error occurs.\n",
+		},
+		{
+			name: "mdoc column list",
+			in:   ".Bl -column Name Value\n.It Sy Name Ta Sy Value\n.It a\tb\n.El\n",
+			want: "| **Name** | **Value** |\n| --- | --- |\n| a | b |\n",
+		},
+		{
+			name: "mdoc spacing",
+			in:   ".Sm off\n.Oo Ar user @ Oc Ar host\n.Sm on\nand\n.Fl f Ns Ar x\n",
+			want: "\\[*user@*\\]*host* and **-f**_x_\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Roff([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Roff() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Roff() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoffGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(".TH X 7\n.SH NAME\nx\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Roff(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Roff() error: %v", err)
+	}
+	if want := "# X(7)\n\n## NAME\n\nx\n"; got != want {
+		t.Errorf("Roff() = %q, want %q", got, want)
+	}
+	if _, err := Roff([]byte{0x1f, 0x8b, 0}); err == nil {
+		t.Error("expected an error for a corrupt gzip stream")
+	}
+}
+
+func TestIsManPage(t *testing.T) {
+	for name, want := range map[string]bool{
+		"ls.1":           true,
+		"ls.1.gz":        true,
+		"printf.3p.gz":   true,
+		"ssl.7ssl":       true,
+		"perlfunc.1perl": true,
+		"backup.7z":      false,
+		"video.3gp":      false,
+		"model.3mf":      false,
+		"tcl.n":          false,
+		"scanner.l":      false,
+		"page.man":       true,
+		"page.mdoc":      true,
+		"libfoo.so.12":   false,
+		"notes.gz":       false,
+		"README.md":      false,
+	} {
+		if got := isManPage(name); got != want {
+			t.Errorf("isManPage(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,704 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RST converts a reStructuredText document into markdown. Sections, lists,
+// literal and code blocks, admonitions, images, tables, hyperlinks and
+// inline markup are converted; directives without a markdown equivalent,
+// like tables of contents, are dropped.
+func RST(b []byte) (string, error) {
+	c := rstConverter{
+		targets:       map[string]string{},
+		substitutions: map[string]string{},
+		headingLevels: map[string]int{},
+	}
+	lines := splitLines(string(b), 8)
+	c.collectDefinitions(lines)
+	return strings.Join(c.blocks(lines), "\n\n") + "\n", nil
+}
+
+type rstConverter struct {
+	// hyperlink targets by normalized reference name
+	targets map[string]string
+	// substitution definitions as markdown
+	substitutions map[string]string
+	// heading levels by adornment style, in order of appearance
+	headingLevels map[string]int
+	// default language of literal blocks
+	highlight string
+}
+
+var (
+	rstTarget       = regexp.MustCompile("^\\.\\. _(`[^`]+`|[^:]+):\\s*(.*)$")
+	rstSubstitution = regexp.MustCompile(`^\.\. \|([^|]+)\|\s+([\w-]+)::\s*(.*)$`)
+	rstDirective    = regexp.MustCompile(`^\.\.\s+([\w:.+-]+)::\s*(.*)$`)
+	rstFootnote     = regexp.MustCompile(`^\.\.\s+\[([^\]]+)\]\s*(.*)$`)
+	rstOption       = regexp.MustCompile(`^:([\w-]+):\s*(.*)$`)
+	rstField        = regexp.MustCompile(`^:([^:\s][^:]*):(?:\s+(.*))?$`)
+	rstBullet       = regexp.MustCompile(`^[-*+] +\S`)
+	rstEnumerated   = regexp.MustCompile(`^\(?(\d+|#)[.)] +\S`)
+	rstListMarker   = regexp.MustCompile(`^(?:[-*+]|\(?(?:\d+|#)[.)]) +`)
+	rstGridBorder   = regexp.MustCompile(`^\+([-=]+\+)+$`)
+	rstSimpleBorder = regexp.MustCompile(`^=+( +=+)+$`)
+	rstDashes       = regexp.MustCompile(`^[- ]+$`)
+
+	rstInterpreted  = regexp.MustCompile("(?::([\\w:+.-]+):)?`([^`]+)`(__?)?")
+	rstEmbeddedURI  = regexp.MustCompile(`^(?s)(.*?)\s*<([^<>]+)>$`)
+	rstSubstRef     = regexp.MustCompile(`\|([^|\s][^|]*)\|(__?)?`)
+	rstFootnoteRef  = regexp.MustCompile(`\[(#?[\w-]*|\*)\]_`)
+	rstReferenceRef = regexp.MustCompile(`([\w][\w.-]*)__?(\W|$)`)
+)
+
+// rstCodeRoles are interpreted text roles rendered as code.
+var rstCodeRoles = map[string]bool{
+	"code": true, "literal": true, "file": true, "command": true,
+	"program": true, "kbd": true, "samp": true, "envvar": true,
+	"option": true, "math": true, "regexp": true, "mimetype": true,
+	"func": true, "meth": true, "class": true, "mod": true, "attr": true,
+	"exc": true, "data": true, "const": true, "obj": true,
+	"py:func": true, "py:meth": true, "py:class": true, "py:mod": true,
+}
+
+// rstAdmonitions maps admonition directives to their labels.
+var rstAdmonitions = map[string]string{
+	"attention": "Attention", "caution": "Caution", "danger": "Danger",
+	"error": "Error", "hint": "Hint", "important": "Important",
+	"note": "Note", "tip": "Tip", "warning": "Warning",
+	"seealso": "See also", "versionadded": "New in version",
+	"versionchanged": "Changed in version", "deprecated": "Deprecated since version",
+	"todo": "Todo",
+}
+
+// rstSkippedDirectives don't produce any output.
+var rstSkippedDirectives = map[string]bool{
+	"contents": true, "toctree": true, "sectnum": true, "include": true,
+	"literalinclude": true, "raw": true, "index": true, "meta": true,
+	"title": true, "header": true, "footer": true, "role": true,
+	"default-role": true, "target-notes": true, "autosummary": true,
+}
+
+// collectDefinitions gathers hyperlink targets and substitution
+// definitions, which may be referenced before they're defined.
+func (c *rstConverter) collectDefinitions(lines []string) {
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if m := rstTarget.FindStringSubmatch(l); m != nil {
+			uri := m[2]
+			if uri == "" && i+1 < len(lines) && indentOf(lines[i+1]) > 0 {
+				// the URI continues on the next line
+				uri = strings.TrimSpace(lines[i+1])
+			}
+			if uri != "" {
+				c.targets[rstRefName(m[1])] = uri
+			}
+			continue
+		}
+		if m := rstSubstitution.FindStringSubmatch(l); m != nil {
+			switch m[2] {
+			case "image":
+				c.substitutions[m[1]] = "![" + m[1] + "](" + linkDestination(m[3]) + ")"
+			case "replace":
+				c.substitutions[m[1]] = c.inline(m[3])
+			default:
+				c.substitutions[m[1]] = m[3]
+			}
+		}
+	}
+}
+
+// rstRefName normalizes a reference name: names are case-insensitive and
+// whitespace-insensitive.
+func rstRefName(s string) string {
+	s = strings.Trim(s, "`")
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// isAdornment returns whether a line is a section adornment or transition:
+// a line of one repeated punctuation character.
+func isAdornment(s string) bool {
+	if len(s) < 2 || s == "::" {
+		return false
+	}
+	ch := s[0]
+	if !strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", rune(ch)) {
+		return false
+	}
+	return strings.Count(s, string(ch)) == len(s)
+}
+
+func (c *rstConverter) blocks(lines []string) []string {
+	var out []string
+	add := func(s string) {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		// indented text is a block quote
+		case indentOf(line) > 0:
+			block := indentedBlock(lines, i, 0)
+			i += len(block)
+			add(blockquote(strings.Join(c.blocks(dedent(block)), "\n\n")))
+
+		// section title with an overline
+		case isAdornment(line) && i+2 < len(lines) && !isBlank(lines[i+1]) &&
+			isAdornment(lines[i+2]) && lines[i+2][0] == line[0]:
+			add(c.heading("overline"+line[:1], lines[i+1]))
+			i += 3
+
+		// section title
+		case i+1 < len(lines) && isAdornment(lines[i+1]) && !isAdornment(line) &&
+			utf8.RuneCountInString(lines[i+1]) >= min(utf8.RuneCountInString(line), 3):
+			add(c.heading(lines[i+1][:1], line))
+			i += 2
+
+		// transition
+		case isAdornment(line) && len(line) >= 4:
+			add("---")
+			i++
+
+		// explicit markup: directives, comments, targets and footnotes
+		case line == ".." || strings.HasPrefix(line, ".. "):
+			body := indentedBlock(lines, i+1, 0)
+			add(c.explicit(line, body))
+			i += 1 + len(body)
+
+		// anonymous hyperlink targets
+		case strings.HasPrefix(line, "__ "):
+			i += 1 + len(indentedBlock(lines, i+1, 0))
+
+		case rstGridBorder.MatchString(line):
+			j := i + 1
+			for j < len(lines) && (strings.HasPrefix(lines[j], "+") || strings.HasPrefix(lines[j], "|")) {
+				j++
+			}
+			add(c.gridTable(lines[i:j]))
+			i = j
+
+		case rstSimpleBorder.MatchString(line):
+			j := i + 1
+			for j < len(lines) && !(rstSimpleBorder.MatchString(lines[j]) && (j+1 == len(lines) || isBlank(lines[j+1]))) {
+				j++
+			}
+			j = min(j+1, len(lines))
+			add(c.simpleTable(lines[i:j]))
+			i = j
+
+		case rstBullet.MatchString(line) || rstEnumerated.MatchString(line):
+			var list string
+			list, i = c.list(lines, i)
+			add(list)
+
+		case rstField.MatchString(line):
+			var fields string
+			fields, i = c.fieldList(lines, i)
+			add(fields)
+
+		// doctest blocks
+		case strings.HasPrefix(trimmed, ">>>"):
+			j := i
+			for j < len(lines) && !isBlank(lines[j]) {
+				j++
+			}
+			add(codeFence(strings.Join(lines[i:j], "\n"), "python"))
+			i = j
+
+		default:
+			j := i + 1
+			for j < len(lines) && !isBlank(lines[j]) && indentOf(lines[j]) == 0 {
+				j++
+			}
+
+			// a definition list item: a term directly followed by an
+			// indented definition
+			if j == i+1 && j < len(lines) && !isBlank(lines[j]) {
+				def := indentedBlock(lines, j, 0)
+				add("**" + c.inline(trimmed) + "**")
+				add(blockquote(strings.Join(c.blocks(dedent(def)), "\n\n")))
+				i = j + len(def)
+				continue
+			}
+
+			para := strings.Join(lines[i:j], "\n")
+			i = j
+
+			// a paragraph ending with "::" introduces a literal block
+			literal := strings.HasSuffix(para, "::")
+			if literal {
+				switch {
+				case para == "::":
+					para = ""
+				case strings.HasSuffix(para, " ::"):
+					para = strings.TrimSuffix(para, " ::")
+				default:
+					para = strings.TrimSuffix(para, ":")
+				}
+			}
+			if para != "" {
+				add(c.inline(para))
+			}
+			if literal {
+				k := i
+				for k < len(lines) && isBlank(lines[k]) {
+					k++
+				}
+				if k < len(lines) && indentOf(lines[k]) > 0 {
+					block := indentedBlock(lines, k, 0)
+					add(codeFence(strings.Join(dedent(block), "\n"), c.highlight))
+					i = k + len(block)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (c *rstConverter) heading(style, title string) string {
+	level, ok := c.headingLevels[style]
+	if !ok {
+		level = len(c.headingLevels) + 1
+		c.headingLevels[style] = level
+	}
+	return strings.Repeat("#", min(level, 6)) + " " + c.inline(strings.TrimSpace(title))
+}
+
+// explicit converts an explicit markup block starting with "..".
+func (c *rstConverter) explicit(first string, body []string) string {
+	switch {
+	case rstTarget.MatchString(first), rstSubstitution.MatchString(first):
+		// collected up front
+		return ""
+
+	case rstFootnote.MatchString(first):
+		m := rstFootnote.FindStringSubmatch(first)
+		text := strings.TrimSpace(m[2] + " " + strings.Join(dedent(body), " "))
+		return "[" + strings.TrimPrefix(m[1], "#") + "] " + c.inline(text)
+
+	case rstDirective.MatchString(first):
+		m := rstDirective.FindStringSubmatch(first)
+		return c.directive(strings.ToLower(m[1]), strings.TrimSpace(m[2]), dedent(body))
+	}
+
+	// a comment
+	return ""
+}
+
+// directiveBody splits a directive's body into its options and content.
+func directiveBody(body []string) (map[string]string, []string) {
+	opts := map[string]string{}
+	i := 0
+	for ; i < len(body); i++ {
+		m := rstOption.FindStringSubmatch(body[i])
+		if m == nil {
+			break
+		}
+		opts[m[1]] = m[2]
+	}
+	return opts, dedent(body[i:])
+}
+
+func (c *rstConverter) directive(name, args string, body []string) string {
+	opts, content := directiveBody(body)
+	name = strings.TrimPrefix(name, "rst:")
+
+	if label, ok := rstAdmonitions[name]; ok {
+		if args != "" {
+			switch name {
+			case "versionadded", "versionchanged", "deprecated":
+				label += " " + args
+			default:
+				content = append([]string{args, ""}, content...)
+			}
+		}
+		inner := c.blocks(content)
+		if len(inner) > 0 {
+			inner[0] = "**" + label + ":** " + inner[0]
+		} else {
+			inner = []string{"**" + label + "**"}
+		}
+		return blockquote(strings.Join(inner, "\n\n"))
+	}
+
+	switch name {
+	case "code-block", "code", "sourcecode":
+		lang := args
+		if lang == "" {
+			lang = c.highlight
+		}
+		return codeFence(strings.Join(content, "\n"), lang)
+
+	case "highlight":
+		c.highlight = args
+		return ""
+
+	case "math":
+		if len(content) == 0 {
+			content = []string{args}
+		}
+		return codeFence(strings.Join(content, "\n"), "latex")
+
+	case "admonition", "topic", "sidebar", "rubric":
+		inner := append([]string{"**" + c.inline(args) + "**"}, c.blocks(content)...)
+		if name == "rubric" || name == "topic" {
+			return strings.Join(inner, "\n\n")
+		}
+		return blockquote(strings.Join(inner, "\n\n"))
+
+	case "image", "figure":
+		img := "![" + opts["alt"] + "](" + linkDestination(args) + ")"
+		if target := opts["target"]; target != "" {
+			img = "[" + img + "](" + linkDestination(target) + ")"
+		}
+		if caption := c.blocks(content); len(caption) > 0 {
+			return img + "\n\n" + strings.Join(caption, "\n\n")
+		}
+		return img
+
+	case "csv-table":
+		return c.csvTable(args, opts, content)
+
+	case "list-table":
+		return c.listTable(args, opts, content)
+	}
+
+	if rstSkippedDirectives[name] {
+		return ""
+	}
+	// render the content of other directives, like "container" or "only"
+	return strings.Join(c.blocks(content), "\n\n")
+}
+
+// list converts a bullet or enumerated list starting at lines[i]. It
+// returns the list and the index of the line following it.
+func (c *rstConverter) list(lines []string, i int) (string, int) {
+	var (
+		items   []string
+		ordered bool
+		num     = 1
+	)
+	for n := 0; ; n++ {
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j >= len(lines) {
+			break
+		}
+
+		line := lines[j]
+		enumerated := rstEnumerated.MatchString(line)
+		if !enumerated && !rstBullet.MatchString(line) {
+			break
+		}
+		if n == 0 {
+			ordered = enumerated
+			if m := rstEnumerated.FindStringSubmatch(line); m != nil {
+				if v, err := strconv.Atoi(m[1]); err == nil {
+					num = v
+				}
+			}
+		} else if enumerated != ordered {
+			break
+		}
+
+		// the item's text starts after the marker
+		width := len(rstListMarker.FindString(line))
+		body := indentedBlock(lines, j+1, width-1)
+		item := append([]string{strings.Repeat(" ", width) + line[width:]}, body...)
+		i = j + 1 + len(body)
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		items = append(items, listItem(marker, joinItemBlocks(c.blocks(dedent(item)))))
+	}
+	return strings.Join(items, "\n"), i
+}
+
+// fieldList converts a field list starting at lines[i] into a list of
+// bold field names and their values.
+func (c *rstConverter) fieldList(lines []string, i int) (string, int) {
+	var items []string
+	for i < len(lines) {
+		m := rstField.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		body := indentedBlock(lines, i+1, 0)
+		value := strings.TrimSpace(m[2] + " " + strings.Join(dedent(body), " "))
+		items = append(items, "- **"+c.inline(m[1])+":** "+c.inline(value))
+		i += 1 + len(body)
+	}
+	return strings.Join(items, "\n"), i
+}
+
+func (c *rstConverter) gridTable(lines []string) string {
+	var cols []int
+	for i, r := range []rune(lines[0]) {
+		if r == '+' {
+			cols = append(cols, i)
+		}
+	}
+
+	var (
+		header  []string
+		rows    [][]string
+		current []string
+	)
+	for _, l := range lines[1:] {
+		if rstGridBorder.MatchString(l) {
+			if current != nil {
+				rows = append(rows, current)
+				current = nil
+			}
+			if strings.Contains(l, "=") && header == nil && len(rows) > 0 {
+				header, rows = rows[0], rows[1:]
+			}
+			continue
+		}
+		if current == nil {
+			current = make([]string, len(cols)-1)
+		}
+		r := []rune(l)
+		for k := 0; k+1 < len(cols); k++ {
+			from, to := cols[k]+1, min(cols[k+1], len(r))
+			if from >= to {
+				continue
+			}
+			cell := strings.Trim(string(r[from:to]), " |")
+			if cell != "" {
+				current[k] = strings.TrimSpace(current[k] + " " + cell)
+			}
+		}
+	}
+	if current != nil {
+		rows = append(rows, current)
+	}
+	return markdownTable(c.inlineCells(header), c.inlineRows(rows))
+}
+
+func (c *rstConverter) simpleTable(lines []string) string {
+	var starts []int
+	border := lines[0]
+	for i := range border {
+		if border[i] == '=' && (i == 0 || border[i-1] == ' ') {
+			starts = append(starts, i)
+		}
+	}
+
+	borders := 0
+	for _, l := range lines[1:] {
+		if rstSimpleBorder.MatchString(l) {
+			borders++
+		}
+	}
+
+	var (
+		header []string
+		rows   [][]string
+	)
+	for _, l := range lines[1:] {
+		if rstSimpleBorder.MatchString(l) {
+			if borders > 1 && header == nil && len(rows) > 0 {
+				header, rows = rows[0], rows[1:]
+			}
+			continue
+		}
+		if isBlank(l) || rstDashes.MatchString(l) {
+			continue
+		}
+
+		r := []rune(l)
+		row := make([]string, len(starts))
+		for k, start := range starts {
+			end := len(r)
+			if k+1 < len(starts) {
+				end = min(starts[k+1], len(r))
+			}
+			if start < end {
+				row[k] = strings.TrimSpace(string(r[start:end]))
+			}
+		}
+
+		// lines with an empty first column continue the previous row
+		if row[0] == "" && len(rows) > 0 {
+			prev := rows[len(rows)-1]
+			for k := range row {
+				prev[k] = strings.TrimSpace(prev[k] + " " + row[k])
+			}
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return markdownTable(c.inlineCells(header), c.inlineRows(rows))
+}
+
+func (c *rstConverter) csvTable(title string, opts map[string]string, content []string) string {
+	parse := func(s string) [][]string {
+		r := csv.NewReader(bytes.NewBufferString(s))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		r.TrimLeadingSpace = true
+		rows, _ := r.ReadAll()
+		return rows
+	}
+
+	rows := parse(strings.Join(content, "\n"))
+	var header []string
+	if h := parse(opts["header"]); len(h) > 0 {
+		header = h[0]
+	} else if n, err := strconv.Atoi(opts["header-rows"]); err == nil && n > 0 && len(rows) > 0 {
+		header, rows = rows[0], rows[1:]
+	}
+	return tableWithTitle(c.inline(title), markdownTable(c.inlineCells(header), c.inlineRows(rows)))
+}
+
+func (c *rstConverter) listTable(title string, opts map[string]string, content []string) string {
+	var rows [][]string
+	for _, l := range content {
+		trimmed := strings.TrimSpace(l)
+		switch {
+		case strings.HasPrefix(l, "* - "):
+			rows = append(rows, []string{strings.TrimPrefix(l, "* - ")})
+		case strings.HasPrefix(trimmed, "- ") && len(rows) > 0:
+			rows[len(rows)-1] = append(rows[len(rows)-1], strings.TrimPrefix(trimmed, "- "))
+		case trimmed != "" && len(rows) > 0:
+			// a cell's continuation line
+			row := rows[len(rows)-1]
+			row[len(row)-1] += " " + trimmed
+		}
+	}
+
+	var header []string
+	if n, err := strconv.Atoi(opts["header-rows"]); err == nil && n > 0 && len(rows) > 0 {
+		header, rows = rows[0], rows[1:]
+	}
+	return tableWithTitle(c.inline(title), markdownTable(c.inlineCells(header), c.inlineRows(rows)))
+}
+
+func tableWithTitle(title, table string) string {
+	if title == "" {
+		return table
+	}
+	return fmt.Sprintf("**%s**\n\n%s", title, table)
+}
+
+func (c *rstConverter) inlineCells(cells []string) []string {
+	for i, s := range cells {
+		cells[i] = c.inline(s)
+	}
+	return cells
+}
+
+func (c *rstConverter) inlineRows(rows [][]string) [][]string {
+	for _, r := range rows {
+		c.inlineCells(r)
+	}
+	return rows
+}
+
+// inline converts reStructuredText inline markup. Inline literals are
+// converted first, so their contents aren't touched.
+func (c *rstConverter) inline(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "``")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start+2:], "``")
+		if end < 0 {
+			break
+		}
+		b.WriteString(c.inlineMarkup(s[:start]))
+		b.WriteString(codeSpan(s[start+2 : start+2+end]))
+		s = s[start+2+end+2:]
+	}
+	b.WriteString(c.inlineMarkup(s))
+	return b.String()
+}
+
+func (c *rstConverter) inlineMarkup(s string) string {
+	s = rstInterpreted.ReplaceAllStringFunc(s, func(match string) string {
+		m := rstInterpreted.FindStringSubmatch(match)
+		role, text, ref := m[1], m[2], m[3]
+
+		switch {
+		case role != "":
+			if rstCodeRoles[role] {
+				return codeSpan(text)
+			}
+			// roles like :ref: and :doc: may embed a target
+			if e := rstEmbeddedURI.FindStringSubmatch(text); e != nil && e[1] != "" {
+				return e[1]
+			}
+			return text
+
+		case ref != "":
+			if e := rstEmbeddedURI.FindStringSubmatch(text); e != nil {
+				label, uri := e[1], e[2]
+				if label == "" {
+					label = uri
+				}
+				if strings.HasSuffix(uri, "_") {
+					uri = c.targets[rstRefName(strings.TrimSuffix(uri, "_"))]
+				}
+				if uri == "" {
+					return label
+				}
+				return "[" + label + "](" + linkDestination(uri) + ")"
+			}
+			if uri, ok := c.targets[rstRefName(text)]; ok {
+				return "[" + text + "](" + linkDestination(uri) + ")"
+			}
+			return text
+		}
+
+		// interpreted text without a role is a title reference
+		return "*" + text + "*"
+	})
+
+	s = rstSubstRef.ReplaceAllStringFunc(s, func(match string) string {
+		m := rstSubstRef.FindStringSubmatch(match)
+		sub, ok := c.substitutions[m[1]]
+		if !ok {
+			return match
+		}
+		if m[2] != "" {
+			if uri, ok := c.targets[rstRefName(m[1])]; ok {
+				return "[" + sub + "](" + linkDestination(uri) + ")"
+			}
+		}
+		return sub
+	})
+
+	s = rstFootnoteRef.ReplaceAllString(s, "[$1]")
+
+	return rstReferenceRef.ReplaceAllStringFunc(s, func(match string) string {
+		m := rstReferenceRef.FindStringSubmatch(match)
+		uri, ok := c.targets[rstRefName(m[1])]
+		if !ok {
+			return match
+		}
+		return "[" + m[1] + "](" + linkDestination(uri) + ")" + m[2]
+	})
+}
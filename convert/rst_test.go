@@ -0,0 +1,85 @@
+package convert
+
+import "testing"
+
+func TestRST(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "section titles",
+			in:   "=====\nTitle\n=====\n\nSection\n-------\n\nSub\n~~~\n\nOther\n-------\n",
+			want: "# Title\n\n## Section\n\n### Sub\n\n## Other\n",
+		},
+		{
+			name: "inline markup",
+			in:   "Some *emphasis*, **strong**, ``code`` and `default`.\n",
+			want: "Some *emphasis*, **strong**, `code` and *default*.\n",
+		},
+		{
+			name: "links",
+			in: "See `the site <https://example.com>`_ and Python_.\n\n" +
+				".. _Python: https://python.org\n",
+			want: "See [the site](https://example.com) and [Python](https://python.org).\n",
+		},
+		{
+			name: "roles",
+			in:   "Use :code:`x = 1` and :func:`run`.\n",
+			want: "Use `x = 1` and `run`.\n",
+		},
+		{
+			name: "literal block",
+			in:   "Example::\n\n    x = 1\n    y = 2\n\nAfter.\n",
+			want: "Example:\n\n```\nx = 1\ny = 2\n```\n\nAfter.\n",
+		},
+		{
+			name: "code block directive",
+			in:   ".. code-block:: python\n   :linenos:\n\n   print(1)\n",
+			want: "```python\nprint(1)\n```\n",
+		},
+		{
+			name: "admonition",
+			in:   ".. note::\n\n   Be careful.\n",
+			want: "> **Note:** Be careful.\n",
+		},
+		{
+			name: "lists",
+			in:   "- one\n- two\n\n  - nested\n\n#. first\n#. second\n",
+			want: "- one\n- two\n  - nested\n\n1. first\n2. second\n",
+		},
+		{
+			name: "empty list item",
+			in:   "* .. a comment\n* two\n",
+			want: "-\n- two\n",
+		},
+		{
+			name: "substitution",
+			in:   "Made by |name|.\n\n.. |name| replace:: Glow\n",
+			want: "Made by Glow.\n",
+		},
+		{
+			name: "comments are skipped",
+			in:   ".. a comment\n   spanning lines\n\nText.\n",
+			want: "Text.\n",
+		},
+		{
+			name: "simple table",
+			in:   "=====  =====\nA      B\n=====  =====\n1      2\n3      4\n=====  =====\n",
+			want: "| A | B |\n| --- | --- |\n| 1 | 2 |\n| 3 | 4 |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RST([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("RST() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RST() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+var tblTabOption = regexp.MustCompile(`tab\s*\((.)\)`)
+
+// table converts a tbl(1) table. The first row is used as the header.
+func (c *roffConverter) table(lines []string) {
+	sep := "\t"
+	i := 0
+	// options end with a semicolon, the format with a period
+	if i < len(lines) && strings.HasSuffix(strings.TrimSpace(lines[i]), ";") {
+		if m := tblTabOption.FindStringSubmatch(lines[i]); m != nil {
+			sep = m[1]
+		}
+		i++
+	}
+	for i < len(lines) {
+		l := strings.TrimSpace(lines[i])
+		i++
+		if strings.HasSuffix(l, ".") {
+			break
+		}
+	}
+
+	var rows [][]string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isRequest(line) {
+			if name, _ := splitRequest(line); name == "T&" {
+				// a new format section
+				for i++; i < len(lines) && !strings.HasSuffix(strings.TrimSpace(lines[i]), "."); i++ {
+				}
+			}
+			continue
+		}
+		if t := strings.TrimSpace(line); t == "" || strings.Trim(t, "_=") == "" {
+			// rules
+			continue
+		}
+
+		var row []string
+		rest := line
+		for {
+			cell, after, more := strings.Cut(rest, sep)
+			if strings.TrimSpace(cell) == "T{" {
+				// a text block, which ends at a line starting with T}
+				var block []string
+				for i++; i < len(lines) && !strings.HasPrefix(lines[i], "T}"); i++ {
+					if !isRequest(lines[i]) {
+						block = append(block, lines[i])
+					}
+				}
+				cell = strings.Join(block, " ")
+				after, more = "", false
+				if i < len(lines) {
+					after, more = strings.CutPrefix(strings.TrimPrefix(lines[i], "T}"), sep)
+				}
+			}
+			font := textRun{}
+			md := runsToMarkdown(c.runs(cell, &font))
+			if md == `\\^` || strings.Trim(md, `\_=`) == "" {
+				// spans and rules within cells
+				md = ""
+			}
+			row = append(row, md)
+			if !more {
+				break
+			}
+			rest = after
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	c.flushParagraph()
+	c.addBlock(markdownTable(rows[0], rows[1:]), true)
+}
@@ -0,0 +1,250 @@
+// Package datatree renders structured data files, such as JSON, YAML and
+// TOML, as trees whose objects and arrays can be collapsed.
+package datatree
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Extensions are the file extensions of the formats we can render.
+var Extensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// margin is the left margin of rendered trees, matching glamour's document
+// margin.
+const margin = "  "
+
+// maxNodes limits the size of a tree, so YAML aliases can't expand into an
+// enormous document.
+const maxNodes = 1_000_000
+
+var (
+	keyStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#7D56F4", Dark: "#AD8CFF"})
+	stringStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1C8760", Dark: "#89F0CB"})
+	numberStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B85C00", Dark: "#FFB86C"})
+	boolStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0E7EA8", Dark: "#6AC8F0"})
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
+)
+
+// Kind is the kind of a node: an object, an array or a scalar.
+type Kind int
+
+// Kinds of nodes.
+const (
+	Scalar Kind = iota
+	Object
+	Array
+)
+
+// Type is the type of a scalar.
+type Type int
+
+// Types of scalars. Other is used for values like dates.
+const (
+	String Type = iota
+	Number
+	Bool
+	Null
+	Other
+)
+
+// Node is a value in a data file.
+type Node struct {
+	Kind Kind
+	// Key is the name of an object member, and Index the position of a
+	// member or element in its parent.
+	Key   string
+	Index int
+	// Value is the text of a scalar, which is of the given Type.
+	Value    string
+	Type     Type
+	Children []*Node
+	Parent   *Node
+}
+
+// IsDataFile returns whether a file holds structured data, based on its
+// extension.
+func IsDataFile(filename string) bool {
+	ext := filepath.Ext(filename)
+	for _, v := range Extensions {
+		if strings.EqualFold(ext, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatName returns the name of the format of a data file, like "YAML".
+func FormatName(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return "JSON"
+	case ".yaml", ".yml":
+		return "YAML"
+	case ".toml":
+		return "TOML"
+	}
+	return ""
+}
+
+// Parse parses a data file into a tree, based on its extension. A YAML
+// file with several documents is an array of them.
+func Parse(b []byte, filename string) (*Node, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return parseYAML(b)
+	case ".toml":
+		return parseTOML(b)
+	}
+	return parseJSON(b)
+}
+
+// add appends a member to an object or an element to an array.
+func (n *Node) add(key string, child *Node) {
+	child.Key = key
+	child.Index = len(n.Children)
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// member returns the member of an object with the given key, or nil.
+func (n *Node) member(key string) *Node {
+	for _, c := range n.Children {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Path returns where a node is in its tree, like spec.containers[0].image.
+// Keys that aren't identifiers are quoted, like labels["app.kubernetes.io/name"].
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return ""
+	}
+	parent := n.Parent.Path()
+	switch {
+	case n.Parent.Kind == Array:
+		return parent + "[" + strconv.Itoa(n.Index) + "]"
+	case !identifier.MatchString(n.Key):
+		return parent + "[" + strconv.Quote(n.Key) + "]"
+	case parent == "":
+		return n.Key
+	}
+	return parent + "." + n.Key
+}
+
+// Depth returns how deeply a node is nested. The members of the root are at
+// depth 0.
+func (n *Node) Depth() int {
+	depth := -1
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return max(0, depth)
+}
+
+// Collapsible returns whether a node is an object or an array with members.
+func (n *Node) Collapsible() bool {
+	return n.Kind != Scalar && len(n.Children) > 0
+}
+
+// Visible returns the nodes shown when the nodes at the given paths are
+// collapsed, in order. The root is only shown when it has no members.
+func Visible(root *Node, collapsed map[string]bool) []*Node {
+	if !root.Collapsible() {
+		return []*Node{root}
+	}
+	var nodes []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			nodes = append(nodes, c)
+			if c.Collapsible() && !collapsed[c.Path()] {
+				walk(c)
+			}
+		}
+	}
+	walk(root)
+	return nodes
+}
+
+// Line renders a node as a line of the tree, without a margin.
+func Line(n *Node, collapsed bool) string {
+	var b strings.Builder
+	b.WriteString(strings.Repeat("  ", n.Depth()))
+	switch {
+	case !n.Collapsible():
+		b.WriteString("  ")
+	case collapsed:
+		b.WriteString(dimStyle.Render("▸ "))
+	default:
+		b.WriteString(dimStyle.Render("▾ "))
+	}
+
+	if n.Parent != nil {
+		if n.Parent.Kind == Array {
+			b.WriteString(dimStyle.Render("[" + strconv.Itoa(n.Index) + "]"))
+		} else {
+			b.WriteString(keyStyle.Render(n.Key))
+		}
+		if n.Kind == Scalar {
+			b.WriteString(dimStyle.Render(":"))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString(n.summary())
+	return strings.TrimRight(b.String(), " ")
+}
+
+// summary renders the value of a scalar, or the size of an object or an
+// array.
+func (n *Node) summary() string {
+	switch n.Kind {
+	case Object:
+		switch len(n.Children) {
+		case 0:
+			return dimStyle.Render("{}")
+		case 1:
+			return dimStyle.Render("{1 key}")
+		}
+		return dimStyle.Render("{" + strconv.Itoa(len(n.Children)) + " keys}")
+	case Array:
+		switch len(n.Children) {
+		case 0:
+			return dimStyle.Render("[]")
+		case 1:
+			return dimStyle.Render("[1 item]")
+		}
+		return dimStyle.Render("[" + strconv.Itoa(len(n.Children)) + " items]")
+	}
+
+	switch n.Type {
+	case String:
+		return stringStyle.Render(strconv.Quote(n.Value))
+	case Number:
+		return numberStyle.Render(n.Value)
+	case Bool:
+		return boolStyle.Render(n.Value)
+	case Null:
+		return dimStyle.Render("null")
+	}
+	return n.Value
+}
+
+// Render renders a whole tree, with all its nodes expanded.
+func Render(root *Node) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, n := range Visible(root, nil) {
+		b.WriteString(margin + Line(n, false) + "\n")
+	}
+	return b.String()
+}
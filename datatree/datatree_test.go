@@ -0,0 +1,193 @@
+package datatree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// paths lists the visible nodes of a tree as "path=value" lines, with
+// containers showing their size.
+func paths(root *Node, collapsed map[string]bool) string {
+	var lines []string
+	for _, n := range Visible(root, collapsed) {
+		lines = append(lines, n.Path()+"="+ansi.Strip(n.summary()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		filename string
+		input    string
+		want     string
+	}{
+		"json": {
+			filename: "a.json",
+			input:    `{"b": 1, "a": [true, null, "x"], "c d": {"e": 1.5e3}}`,
+			want: strings.Join([]string{
+				`b=1`,
+				`a=[3 items]`,
+				`a[0]=true`,
+				`a[1]=null`,
+				`a[2]="x"`,
+				`["c d"]={1 key}`,
+				`["c d"].e=1.5e3`,
+			}, "\n"),
+		},
+		"json scalar": {
+			filename: "a.json",
+			input:    `"hi"`,
+			want:     `="hi"`,
+		},
+		"yaml": {
+			filename: "deploy.yaml",
+			input: strings.Join([]string{
+				"spec:",
+				"  containers:",
+				"    - name: web",
+				"      image: nginx:1.27",
+				"      ports: [80, 443]",
+				"  labels: &labels",
+				"    app.kubernetes.io/name: web",
+				"  selector: *labels",
+				"  empty: {}",
+			}, "\n"),
+			want: strings.Join([]string{
+				`spec={4 keys}`,
+				`spec.containers=[1 item]`,
+				`spec.containers[0]={3 keys}`,
+				`spec.containers[0].name="web"`,
+				`spec.containers[0].image="nginx:1.27"`,
+				`spec.containers[0].ports=[2 items]`,
+				`spec.containers[0].ports[0]=80`,
+				`spec.containers[0].ports[1]=443`,
+				`spec.labels={1 key}`,
+				`spec.labels["app.kubernetes.io/name"]="web"`,
+				`spec.selector={1 key}`,
+				`spec.selector["app.kubernetes.io/name"]="web"`,
+				`spec.empty={}`,
+			}, "\n"),
+		},
+		"yaml documents": {
+			filename: "a.yml",
+			input:    "a: 1\n---\nb: ~\n",
+			want: strings.Join([]string{
+				`[0]={1 key}`,
+				`[0].a=1`,
+				`[1]={1 key}`,
+				`[1].b=null`,
+			}, "\n"),
+		},
+		"toml": {
+			filename: "Cargo.toml",
+			input: strings.Join([]string{
+				`name = "glow"`,
+				`tags = ["a", "b"]`,
+				`server.port = 8080`,
+				`[owner]`,
+				`dob = 1979-05-27T07:32:00Z`,
+				`[[bin]]`,
+				`path = "a"`,
+				`[[bin]]`,
+				`path = "b"`,
+				`opts = { fast = true }`,
+			}, "\n"),
+			want: strings.Join([]string{
+				`name="glow"`,
+				`tags=[2 items]`,
+				`tags[0]="a"`,
+				`tags[1]="b"`,
+				`server={1 key}`,
+				`server.port=8080`,
+				`owner={1 key}`,
+				`owner.dob=1979-05-27T07:32:00Z`,
+				`bin=[2 items]`,
+				`bin[0]={1 key}`,
+				`bin[0].path="a"`,
+				`bin[1]={2 keys}`,
+				`bin[1].path="b"`,
+				`bin[1].opts={1 key}`,
+				`bin[1].opts.fast=true`,
+			}, "\n"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root, err := Parse([]byte(tc.input), tc.filename)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := paths(root, nil); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"a.json": `{"a": 1} {"b": 2}`,
+		"b.json": `{"a": }`,
+		"a.yaml": "a: [1, 2",
+		"a.toml": "a = 1\na = 2",
+	}
+
+	for filename, input := range tests {
+		t.Run(filename, func(t *testing.T) {
+			if _, err := Parse([]byte(input), filename); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestVisibleCollapsed(t *testing.T) {
+	root, err := Parse([]byte(`{"a": {"b": [1, 2]}, "c": 3}`), "a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a={1 key}\na.b=[2 items]\nc=3"
+	if got := paths(root, map[string]bool{"a.b": true}); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	want = "a={1 key}\nc=3"
+	if got := paths(root, map[string]bool{"a": true, "a.b": true}); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	root, err := Parse([]byte(`{"a": {"b": [1]}, "c": "d"}`), "a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"",
+		"  ▾ a {1 key}",
+		"    ▾ b [1 item]",
+		"        [0]: 1",
+		"    c: \"d\"",
+		"",
+	}, "\n")
+	if got := ansi.Strip(Render(root)); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestIsDataFile(t *testing.T) {
+	for filename, want := range map[string]bool{
+		"a.json":      true,
+		"A.YAML":      true,
+		"b.yml":       true,
+		"Cargo.toml":  true,
+		"README.md":   false,
+		"jsonfile.go": false,
+	} {
+		if got := IsDataFile(filename); got != want {
+			t.Errorf("IsDataFile(%q) = %v, want %v", filename, got, want)
+		}
+	}
+}
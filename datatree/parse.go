@@ -0,0 +1,250 @@
+package datatree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.yaml.in/yaml/v3"
+)
+
+var errTooLarge = errors.New("too many values")
+
+func parseJSON(b []byte) (*Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(b, []byte("\ufeff"))))
+	dec.UseNumber()
+	n, err := jsonValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unable to parse JSON: data after the top-level value")
+	}
+	return n, nil
+}
+
+// jsonValue reads the next value from dec. The tokens of the decoder are
+// used so the order of object members is kept.
+func jsonValue(dec *json.Decoder) (*Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &Node{Kind: Object}
+		if t == '[' {
+			n.Kind = Array
+		}
+		for dec.More() {
+			var key string
+			if n.Kind == Object {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err //nolint:wrapcheck
+				}
+				key, _ = tok.(string)
+			}
+			child, err := jsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.add(key, child)
+		}
+		// the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		return n, nil
+	case string:
+		return &Node{Value: t, Type: String}, nil
+	case json.Number:
+		return &Node{Value: t.String(), Type: Number}, nil
+	case bool:
+		return &Node{Value: fmt.Sprint(t), Type: Bool}, nil
+	}
+	return &Node{Value: "null", Type: Null}, nil
+}
+
+func parseYAML(b []byte) (*Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	var docs []*Node
+	nodes := 0
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse YAML: %w", err)
+		}
+		n, err := yamlValue(&doc, &nodes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse YAML: %w", err)
+		}
+		docs = append(docs, n)
+	}
+
+	switch len(docs) {
+	case 0:
+		return &Node{Value: "null", Type: Null}, nil
+	case 1:
+		return docs[0], nil
+	}
+	root := &Node{Kind: Array}
+	for _, d := range docs {
+		root.add("", d)
+	}
+	return root, nil
+}
+
+// yamlValue converts a YAML node. Aliases are expanded; nodes counts the
+// values, to limit how far.
+func yamlValue(y *yaml.Node, nodes *int) (*Node, error) {
+	if *nodes++; *nodes > maxNodes {
+		return nil, errTooLarge
+	}
+	switch y.Kind {
+	case yaml.DocumentNode:
+		if len(y.Content) == 0 {
+			return &Node{Value: "null", Type: Null}, nil
+		}
+		return yamlValue(y.Content[0], nodes)
+	case yaml.AliasNode:
+		return yamlValue(y.Alias, nodes)
+	case yaml.MappingNode, yaml.SequenceNode:
+		n := &Node{Kind: Array}
+		step := 1
+		if y.Kind == yaml.MappingNode {
+			n.Kind, step = Object, 2
+		}
+		for i := 0; i+step-1 < len(y.Content); i += step {
+			var key string
+			if step == 2 {
+				key = y.Content[i].Value
+			}
+			child, err := yamlValue(y.Content[i+step-1], nodes)
+			if err != nil {
+				return nil, err
+			}
+			n.add(key, child)
+		}
+		return n, nil
+	}
+
+	n := &Node{Value: y.Value}
+	switch y.ShortTag() {
+	case "!!str":
+		n.Type = String
+	case "!!int", "!!float":
+		n.Type = Number
+	case "!!bool":
+		n.Type = Bool
+	case "!!null":
+		n.Type, n.Value = Null, "null"
+	default:
+		n.Type = Other
+	}
+	return n, nil
+}
+
+func parseTOML(b []byte) (*Node, error) {
+	// the parser below keeps the order of keys but doesn't validate the
+	// document, so it's decoded first
+	var v map[string]any
+	if err := toml.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("unable to parse TOML: %w", err)
+	}
+
+	root := &Node{Kind: Object}
+	table := root
+	p := unstable.Parser{}
+	p.Reset(b)
+	for p.NextExpression() {
+		e := p.Expression()
+		switch e.Kind { //nolint:exhaustive
+		case unstable.KeyValue:
+			parent, key := tomlKey(table, e.Key())
+			parent.add(key, tomlValue(e.Value()))
+		case unstable.Table:
+			parent, key := tomlKey(root, e.Key())
+			table = parent.table(key)
+		case unstable.ArrayTable:
+			parent, key := tomlKey(root, e.Key())
+			tables := parent.member(key)
+			if tables == nil {
+				tables = &Node{Kind: Array}
+				parent.add(key, tables)
+			}
+			table = &Node{Kind: Object}
+			tables.add("", table)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, fmt.Errorf("unable to parse TOML: %w", err)
+	}
+	return root, nil
+}
+
+// tomlKey follows a dotted key from n, creating the tables on the way, and
+// returns the table holding its last part.
+func tomlKey(n *Node, it unstable.Iterator) (*Node, string) {
+	var parts []string
+	for it.Next() {
+		parts = append(parts, string(it.Node().Data))
+	}
+	if len(parts) == 0 {
+		return n, ""
+	}
+	for _, p := range parts[:len(parts)-1] {
+		n = n.table(p)
+	}
+	return n, parts[len(parts)-1]
+}
+
+// table returns the table with the given key, creating it if needed. For an
+// array of tables, it's the last one.
+func (n *Node) table(key string) *Node {
+	t := n.member(key)
+	switch {
+	case t == nil:
+		t = &Node{Kind: Object}
+		n.add(key, t)
+	case t.Kind == Array && len(t.Children) > 0:
+		return t.Children[len(t.Children)-1]
+	}
+	return t
+}
+
+func tomlValue(v *unstable.Node) *Node {
+	switch v.Kind { //nolint:exhaustive
+	case unstable.Array:
+		n := &Node{Kind: Array}
+		it := v.Children()
+		for it.Next() {
+			n.add("", tomlValue(it.Node()))
+		}
+		return n
+	case unstable.InlineTable:
+		n := &Node{Kind: Object}
+		it := v.Children()
+		for it.Next() {
+			kv := it.Node()
+			parent, key := tomlKey(n, kv.Key())
+			parent.add(key, tomlValue(kv.Value()))
+		}
+		return n
+	case unstable.String:
+		return &Node{Value: string(v.Data), Type: String}
+	case unstable.Integer, unstable.Float:
+		return &Node{Value: string(v.Data), Type: Number}
+	case unstable.Bool:
+		return &Node{Value: string(v.Data), Type: Bool}
+	}
+	return &Node{Value: string(v.Data), Type: Other}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+)
+
+// blockOp is the kind of change a block underwent between two versions.
+type blockOp int
+
+const (
+	blockEqual blockOp = iota
+	blockAdded
+	blockRemoved
+)
+
+// blockChange is a markdown block along with how it changed.
+type blockChange struct {
+	op    blockOp
+	block string
+}
+
+// splitBlocks splits markdown into its top-level blocks: paragraphs, lists,
+// headings and so on are separated by blank lines, while fenced code blocks
+// are kept in one piece. Headings always form a block of their own.
+func splitBlocks(md string) []string {
+	var (
+		blocks  []string
+		current []string
+		fence   string
+	)
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			current = append(current, line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				flush()
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+			current = append(current, line)
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			current = append(current, line)
+			flush()
+		default:
+			current = append(current, strings.TrimRight(line, " \t"))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// diffBlocks computes a block-level diff between two markdown documents
+// using the longest common subsequence of their blocks.
+func diffBlocks(a, b string) []blockChange {
+	x, y := splitBlocks(a), splitBlocks(b)
+
+	// lcs[i][j] is the length of the LCS of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := make([]blockChange, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			changes = append(changes, blockChange{blockEqual, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, blockChange{blockRemoved, x[i]})
+			i++
+		default:
+			changes = append(changes, blockChange{blockAdded, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		changes = append(changes, blockChange{blockRemoved, x[i]})
+	}
+	for ; j < len(y); j++ {
+		changes = append(changes, blockChange{blockAdded, y[j]})
+	}
+	return changes
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+)
+
+const diffGutterWidth = 2
+
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ED567A"))
+)
+
+var diffCmd = &cobra.Command{
+	Use:     "diff RANGE FILE",
+	Short:   "Render the changes to a document between git revisions",
+	Long:    paragraph(fmt.Sprintf("\n%s the changes to a markdown document between two git revisions, block by block. Added and removed blocks are marked in the gutter. A single revision is compared to the working tree.", keyword("Render"))),
+	Example: paragraph("glow diff main..feature docs/api.md\nglow diff HEAD~3 README.md\nglow diff main...feature README.md"),
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path := args[1]
+		if !utils.IsMarkdownFile(path) {
+			return fmt.Errorf("%s: only markdown documents can be diffed", path)
+		}
+
+		from, to, err := parseRevRange(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		a, err := gitShow(ctx, from, path)
+		if err != nil {
+			return err
+		}
+		var b []byte
+		if to == "" {
+			b, err = os.ReadFile(path)
+		} else {
+			b, err = gitShow(ctx, to, path)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read file: %w", err)
+		}
+
+		changes := diffBlocks(
+			string(utils.RemoveFrontmatter(a)),
+			string(utils.RemoveFrontmatter(b)),
+		)
+		out, err := renderDiff(changes)
+		if err != nil {
+			return err
+		}
+
+		if to == "" {
+			to = "working tree"
+		}
+		header := sourceHeader(fmt.Sprintf("%s: %s → %s", path, from, to))
+		return display(cmd, header+out, tuiOptions{}, os.Stdout)
+	},
+}
+
+// renderDiff renders each block on its own and marks added and removed
+// blocks in a gutter.
+func renderDiff(changes []blockChange) (string, error) {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		utils.GlamourStyle(style, false),
+		glamour.WithWordWrap(max(0, int(width)-diffGutterWidth)), //nolint:gosec
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to create renderer: %w", err)
+	}
+
+	var b strings.Builder
+	for _, c := range changes {
+		out, err := r.Render(c.block)
+		if err != nil {
+			return "", fmt.Errorf("unable to render markdown: %w", err)
+		}
+
+		var gutter string
+		switch c.op {
+		case blockAdded:
+			gutter = diffAddedStyle.Render("+ ")
+		case blockRemoved:
+			gutter = diffRemovedStyle.Render("- ")
+		case blockEqual:
+			gutter = strings.Repeat(" ", diffGutterWidth)
+		}
+
+		b.WriteString("\n")
+		for _, line := range trimBlankLines(strings.Split(out, "\n")) {
+			b.WriteString(gutter + line + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// trimBlankLines removes leading and trailing lines which only contain
+// whitespace or escape sequences.
+func trimBlankLines(lines []string) []string {
+	blank := func(s string) bool {
+		return strings.TrimSpace(ansi.Strip(s)) == ""
+	}
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
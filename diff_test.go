@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "paragraphs",
+			in:   "one\ntwo\n\nthree\n",
+			want: []string{"one\ntwo", "three"},
+		},
+		{
+			name: "heading is its own block",
+			in:   "# Title\ntext",
+			want: []string{"# Title", "text"},
+		},
+		{
+			name: "fenced code kept together",
+			in:   "```go\na\n\nb\n```\nafter",
+			want: []string{"```go\na\n\nb\n```", "after"},
+		},
+		{
+			name: "tilde fence",
+			in:   "~~~\n# not a heading\n~~~",
+			want: []string{"~~~\n# not a heading\n~~~"},
+		},
+		{
+			name: "trailing whitespace ignored",
+			in:   "text  \r\n\r\nmore",
+			want: []string{"text", "more"},
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitBlocks(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBlocks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	a := "# Title\n\nold text\n\n## Install\n\nrun it"
+	b := "# Title\n\nnew text\n\n## Install\n\nrun it\n\n## Usage"
+
+	want := []blockChange{
+		{blockEqual, "# Title"},
+		{blockRemoved, "old text"},
+		{blockAdded, "new text"},
+		{blockEqual, "## Install"},
+		{blockEqual, "run it"},
+		{blockAdded, "## Usage"},
+	}
+	if got := diffBlocks(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffBlocks() = %v, want %v", got, want)
+	}
+
+	t.Run("identical documents", func(t *testing.T) {
+		for _, c := range diffBlocks(a, a) {
+			if c.op != blockEqual {
+				t.Errorf("expected only equal blocks, got %v", c)
+			}
+		}
+	})
+}
@@ -0,0 +1,341 @@
+// Package epub reads EPUB books, converting their chapters into markdown.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/convert"
+	"golang.org/x/net/html"
+)
+
+// Extension is the file extension of EPUB books.
+const Extension = ".epub"
+
+// maxFileSize limits how much is read from a file in a book, so a malicious
+// book can't exhaust memory.
+const maxFileSize = 64 << 20
+
+// Book is a parsed EPUB book.
+type Book struct {
+	// Identifier is the book's unique identifier, like an ISBN or a UUID.
+	Identifier string
+	Title      string
+	Author     string
+	Chapters   []Chapter
+}
+
+// Chapter is a document in the reading order of a book.
+type Chapter struct {
+	Title    string
+	Markdown string
+}
+
+// IsEPUB returns whether a file is an EPUB book, based on its extension.
+func IsEPUB(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), Extension)
+}
+
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type packageDocument struct {
+	UniqueIdentifier string `xml:"unique-identifier,attr"`
+	Metadata         struct {
+		Titles      []string `xml:"title"`
+		Creators    []string `xml:"creator"`
+		Identifiers []struct {
+			ID    string `xml:"id,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"identifier"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		TOC      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type ncxPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Points []ncxPoint `xml:"navPoint"`
+}
+
+// tocEntry is an entry of a book's table of contents.
+type tocEntry struct {
+	title string
+	// file the entry links to, relative to the root of the book
+	file string
+}
+
+// Parse reads an EPUB book. The chapters are the documents in the book's
+// reading order, titled after its table of contents.
+func Parse(b []byte) (*Book, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read book: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var c container
+	if err := readXML(files, "META-INF/container.xml", &c); err != nil {
+		return nil, err
+	}
+	if len(c.Rootfiles) == 0 {
+		return nil, errors.New("unable to read book: no package document")
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	var pkg packageDocument
+	if err := readXML(files, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+
+	book := &Book{}
+	if len(pkg.Metadata.Titles) > 0 {
+		book.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+	book.Author = strings.TrimSpace(strings.Join(pkg.Metadata.Creators, ", "))
+	for _, id := range pkg.Metadata.Identifiers {
+		if book.Identifier == "" || id.ID == pkg.UniqueIdentifier {
+			book.Identifier = strings.TrimSpace(id.Value)
+		}
+	}
+
+	// the table of contents is either an EPUB 3 navigation document or an
+	// EPUB 2 NCX file
+	base := path.Dir(opfPath)
+	hrefs := map[string]string{}
+	var toc []tocEntry
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = resolve(base, item.Href)
+	}
+	for _, item := range pkg.Manifest {
+		if hasProperty(item.Properties, "nav") {
+			toc = readNav(files, hrefs[item.ID])
+		}
+	}
+	if len(toc) == 0 && pkg.Spine.TOC != "" {
+		toc = readNCX(files, hrefs[pkg.Spine.TOC])
+	}
+
+	for _, ref := range pkg.Spine.ItemRefs {
+		if ref.Linear == "no" {
+			continue
+		}
+		name, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		content, err := readFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		md, err := convert.HTMLBody(content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert %s: %w", name, err)
+		}
+		if strings.TrimSpace(md) == "" {
+			continue
+		}
+		book.Chapters = append(book.Chapters, Chapter{
+			Title:    chapterTitle(toc, name, md),
+			Markdown: md,
+		})
+	}
+	if len(book.Chapters) == 0 {
+		return nil, errors.New("unable to read book: no chapters")
+	}
+	return book, nil
+}
+
+// Markdown returns the whole book as a single document.
+func (b *Book) Markdown() string {
+	chapters := make([]string, 0, len(b.Chapters))
+	for _, c := range b.Chapters {
+		chapters = append(chapters, strings.TrimSpace(c.Markdown))
+	}
+	return strings.Join(chapters, "\n\n---\n\n") + "\n"
+}
+
+func readFile(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("unable to read book: missing %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", name, err)
+	}
+	defer r.Close() //nolint:errcheck
+	b, err := io.ReadAll(io.LimitReader(r, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", name, err)
+	}
+	return b, nil
+}
+
+func readXML(files map[string]*zip.File, name string, v any) error {
+	b, err := readFile(files, name)
+	if err != nil {
+		return err
+	}
+	d := xml.NewDecoder(bytes.NewReader(b))
+	d.Strict = false
+	if err := d.Decode(v); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// resolve resolves a link in a file in dir to a path within the book,
+// dropping any fragment.
+func resolve(dir, href string) string {
+	href, _, _ = strings.Cut(href, "#")
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return strings.TrimPrefix(path.Join(dir, href), "./")
+}
+
+func hasProperty(properties, name string) bool {
+	for _, p := range strings.Fields(properties) {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readNav reads the table of contents from an EPUB 3 navigation document.
+func readNav(files map[string]*zip.File, name string) []tocEntry {
+	b, err := readFile(files, name)
+	if err != nil {
+		return nil
+	}
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+
+	var nav *html.Node
+	var findNav func(n *html.Node)
+	findNav = func(n *html.Node) {
+		if nav != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "nav" {
+			for _, a := range n.Attr {
+				if a.Key == "epub:type" && hasProperty(a.Val, "toc") {
+					nav = n
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			findNav(c)
+		}
+	}
+	findNav(doc)
+	if nav == nil {
+		return nil
+	}
+
+	var toc []tocEntry
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, a := range n.Attr {
+				if a.Key == "href" {
+					toc = append(toc, tocEntry{
+						title: strings.Join(strings.Fields(nodeText(n)), " "),
+						file:  resolve(path.Dir(name), a.Val),
+					})
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(nav)
+	return toc
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+// readNCX reads the table of contents from an EPUB 2 NCX file.
+func readNCX(files map[string]*zip.File, name string) []tocEntry {
+	var ncx struct {
+		Points []ncxPoint `xml:"navMap>navPoint"`
+	}
+	if err := readXML(files, name, &ncx); err != nil {
+		return nil
+	}
+
+	var toc []tocEntry
+	var collect func(points []ncxPoint)
+	collect = func(points []ncxPoint) {
+		for _, p := range points {
+			toc = append(toc, tocEntry{
+				title: strings.Join(strings.Fields(p.Label), " "),
+				file:  resolve(path.Dir(name), p.Content.Src),
+			})
+			collect(p.Points)
+		}
+	}
+	collect(ncx.Points)
+	return toc
+}
+
+// chapterTitle returns the title of a chapter: its first entry in the
+// table of contents, or else its first heading or its file name.
+func chapterTitle(toc []tocEntry, name, md string) string {
+	for _, e := range toc {
+		if e.file == name && e.title != "" {
+			return e.title
+		}
+	}
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(line, "#") {
+			if title := strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+				return title
+			}
+		}
+	}
+	return strings.TrimSuffix(path.Base(name), path.Ext(name))
+}
@@ -0,0 +1,176 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const containerXML = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+func chapterXHTML(title, body string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>` + title + `</title></head>
+<body>` + body + `</body></html>`
+}
+
+// bookArchive builds an EPUB archive of the given files.
+func bookArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseEPUB3(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="isbn">978-3-16-148410-0</dc:identifier>
+    <dc:identifier id="uid">urn:uuid:1234</dc:identifier>
+    <dc:title>The Book</dc:title>
+    <dc:creator>Ada</dc:creator>
+    <dc:creator>Grace</dc:creator>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="text/notes.xhtml" media-type="application/xhtml+xml"/>
+    <item id="empty" href="text/empty.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover" linear="no"/>
+    <itemref idref="ch1"/>
+    <itemref idref="empty"/>
+    <itemref idref="ch2"/>
+    <itemref idref="notes"/>
+  </spine>
+</package>`
+	nav := `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="landmarks"><ol><li><a href="text/ch2.xhtml">Wrong</a></li></ol></nav>
+<nav epub:type="toc"><ol>
+  <li><a href="text/chapter%201.xhtml">Chapter  One</a>
+    <ol><li><a href="text/chapter%201.xhtml#part">A Part</a></li></ol></li>
+  <li><a href="text/ch2.xhtml#start">Chapter Two</a></li>
+</ol></nav>
+</body></html>`
+
+	b := bookArchive(t, map[string]string{
+		"mimetype":                     "application/epub+zip",
+		"META-INF/container.xml":       containerXML,
+		"OEBPS/content.opf":            opf,
+		"OEBPS/nav.xhtml":              nav,
+		"OEBPS/cover.xhtml":            chapterXHTML("Cover", `<p>Cover</p>`),
+		"OEBPS/text/chapter 1.xhtml":   chapterXHTML("One", `<h1>One</h1><p>It <em>begins</em>.</p>`),
+		"OEBPS/text/ch2.xhtml":         chapterXHTML("Two", `<h1>Two</h1><p>It goes on.</p>`),
+		"OEBPS/text/notes.xhtml":       chapterXHTML("Notes", `<h2>Notes</h2><p>Thanks.</p>`),
+		"OEBPS/text/empty.xhtml":       chapterXHTML("Empty", ``),
+		"OEBPS/text/unreferenced.html": chapterXHTML("Other", `<p>Other</p>`),
+	})
+	book, err := Parse(b)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if book.Title != "The Book" || book.Author != "Ada, Grace" || book.Identifier != "urn:uuid:1234" {
+		t.Errorf("metadata = %q, %q, %q", book.Title, book.Author, book.Identifier)
+	}
+	var titles []string
+	for _, c := range book.Chapters {
+		titles = append(titles, c.Title)
+	}
+	if got, want := strings.Join(titles, "|"), "Chapter One|Chapter Two|Notes"; got != want {
+		t.Errorf("chapter titles = %q, want %q", got, want)
+	}
+	if got, want := book.Chapters[0].Markdown, "# One\n\nIt *begins*.\n"; got != want {
+		t.Errorf("chapter markdown = %q, want %q", got, want)
+	}
+	if got, want := book.Markdown(), "# One\n\nIt *begins*.\n\n---\n\n# Two\n\nIt goes on.\n\n---\n\n## Notes\n\nThanks.\n"; got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestParseEPUB2(t *testing.T) {
+	opf := `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <metadata><dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">Old Book</dc:title></metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="a" href="a.html" media-type="application/xhtml+xml"/>
+    <item id="b" href="b.html" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx"><itemref idref="a"/><itemref idref="b"/></spine>
+</package>`
+	ncx := `<?xml version="1.0"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1"><navMap>
+  <navPoint id="p1"><navLabel><text>First</text></navLabel><content src="a.html"/></navPoint>
+</navMap></ncx>`
+
+	b := bookArchive(t, map[string]string{
+		"META-INF/container.xml": strings.ReplaceAll(containerXML, "OEBPS/", ""),
+		"content.opf":            opf,
+		"toc.ncx":                ncx,
+		"a.html":                 chapterXHTML("A", `<p>a</p>`),
+		"b.html":                 chapterXHTML("B", `<p>b</p>`),
+	})
+	book, err := Parse(b)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if book.Title != "Old Book" || len(book.Chapters) != 2 {
+		t.Fatalf("unexpected book %+v", book)
+	}
+	// chapters without a table of contents entry or heading are titled
+	// after their file
+	if book.Chapters[0].Title != "First" || book.Chapters[1].Title != "b" {
+		t.Errorf("chapter titles = %q, %q", book.Chapters[0].Title, book.Chapters[1].Title)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"not a zip":        []byte("not a zip"),
+		"no container":     bookArchive(t, map[string]string{"mimetype": "application/epub+zip"}),
+		"missing package":  bookArchive(t, map[string]string{"META-INF/container.xml": containerXML}),
+		"missing chapters": bookArchive(t, map[string]string{"META-INF/container.xml": containerXML, "OEBPS/content.opf": `<package><spine/></package>`}),
+	}
+	for name, b := range tests {
+		if _, err := Parse(b); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestIsEPUB(t *testing.T) {
+	for name, want := range map[string]bool{
+		"book.epub": true,
+		"BOOK.EPUB": true,
+		"book.md":   false,
+		"epub":      false,
+	} {
+		if got := IsEPUB(name); got != want {
+			t.Errorf("IsEPUB(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
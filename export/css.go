@@ -0,0 +1,252 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/styles"
+	glamouransi "github.com/charmbracelet/glamour/ansi"
+	"github.com/muesli/termenv"
+)
+
+// Colors of pages whose style doesn't set them, which glamour leaves to the
+// terminal.
+const (
+	darkBackground  = "#1c1c1c"
+	darkForeground  = "#d0d0d0"
+	lightBackground = "#ffffff"
+	lightForeground = "#262626"
+)
+
+// baseCSS lays out the page. Colors and text styles come from the glamour
+// style.
+const baseCSS = `body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; }
+main { max-width: 48rem; margin: 0 auto; padding: 2rem 1.5rem; }
+nav.toc { max-width: 48rem; margin: 0 auto; padding: 1rem 1.5rem 0; }
+nav.toc ul { margin: 0; padding-left: 1.25rem; }
+pre, code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9em; }
+pre { padding: 0.75rem 1rem; overflow-x: auto; border-radius: 4px; }
+code { padding: 0.1em 0.3em; border-radius: 3px; }
+pre code { padding: 0; }
+blockquote { margin-left: 0; padding-left: 1rem; border-left: 3px solid; }
+table { border-collapse: collapse; }
+th, td { padding: 0.25rem 0.75rem; border: 1px solid; }
+hr { border: 0; border-top: 1px solid; }
+img { max-width: 100%; }
+`
+
+// cssColor returns the CSS color of a glamour color, which is either a hex
+// color or an ANSI color number.
+func cssColor(c *string) string {
+	if c == nil || *c == "" {
+		return ""
+	}
+	if strings.HasPrefix(*c, "#") {
+		return *c
+	}
+	n, err := strconv.Atoi(*c)
+	if err != nil {
+		return ""
+	}
+	return paletteColor(n)
+}
+
+// paletteColor returns the hex color of an ANSI color number, in xterm's
+// palette.
+func paletteColor(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16: //nolint:mnd
+		return termenv.ConvertToRGB(termenv.ANSIColor(n)).Hex()
+	default:
+		return termenv.ConvertToRGB(termenv.ANSI256Color(n)).Hex()
+	}
+}
+
+// merge returns a primitive with the fields b sets overriding a's.
+func merge(a, b glamouransi.StylePrimitive) glamouransi.StylePrimitive {
+	if b.Color != nil {
+		a.Color = b.Color
+	}
+	if b.BackgroundColor != nil {
+		a.BackgroundColor = b.BackgroundColor
+	}
+	if b.Bold != nil {
+		a.Bold = b.Bold
+	}
+	if b.Italic != nil {
+		a.Italic = b.Italic
+	}
+	if b.Underline != nil {
+		a.Underline = b.Underline
+	}
+	if b.CrossedOut != nil {
+		a.CrossedOut = b.CrossedOut
+	}
+	if b.Faint != nil {
+		a.Faint = b.Faint
+	}
+	return a
+}
+
+// declarations returns the CSS declarations of a glamour style primitive.
+func declarations(p glamouransi.StylePrimitive) string {
+	var d []string
+	if c := cssColor(p.Color); c != "" {
+		d = append(d, "color: "+c)
+	}
+	if c := cssColor(p.BackgroundColor); c != "" {
+		d = append(d, "background-color: "+c)
+	}
+	if p.Bold != nil {
+		if *p.Bold {
+			d = append(d, "font-weight: bold")
+		} else {
+			d = append(d, "font-weight: normal")
+		}
+	}
+	if p.Italic != nil && *p.Italic {
+		d = append(d, "font-style: italic")
+	}
+	var decorations []string
+	if p.Underline != nil && *p.Underline {
+		decorations = append(decorations, "underline")
+	}
+	if p.CrossedOut != nil && *p.CrossedOut {
+		decorations = append(decorations, "line-through")
+	}
+	switch {
+	case len(decorations) > 0:
+		d = append(d, "text-decoration: "+strings.Join(decorations, " "))
+	case p.Underline != nil:
+		d = append(d, "text-decoration: none")
+	}
+	if p.Faint != nil && *p.Faint {
+		d = append(d, "opacity: 0.7")
+	}
+	return strings.Join(d, "; ")
+}
+
+// stylesheet returns the CSS of a page in the given glamour style.
+func stylesheet(cfg glamouransi.StyleConfig) string {
+	body := cfg.Document.StylePrimitive
+	fg, bg := TerminalColors(cfg)
+	body.Color, body.BackgroundColor = &fg, &bg
+	border := cfg.HorizontalRule
+	if border.Color == nil {
+		border.Color = body.Color
+	}
+
+	rules := []struct {
+		selector string
+		style    glamouransi.StylePrimitive
+	}{
+		{"body", body},
+		{"p", cfg.Paragraph.StylePrimitive},
+		{"h1", merge(cfg.Heading.StylePrimitive, cfg.H1.StylePrimitive)},
+		{"h2", merge(cfg.Heading.StylePrimitive, cfg.H2.StylePrimitive)},
+		{"h3", merge(cfg.Heading.StylePrimitive, cfg.H3.StylePrimitive)},
+		{"h4", merge(cfg.Heading.StylePrimitive, cfg.H4.StylePrimitive)},
+		{"h5", merge(cfg.Heading.StylePrimitive, cfg.H5.StylePrimitive)},
+		{"h6", merge(cfg.Heading.StylePrimitive, cfg.H6.StylePrimitive)},
+		{"blockquote", cfg.BlockQuote.StylePrimitive},
+		{"a", merge(cfg.Link, cfg.LinkText)},
+		{"em", cfg.Emph},
+		{"strong", cfg.Strong},
+		{"del", cfg.Strikethrough},
+		{"code", cfg.Code.StylePrimitive},
+		{"pre", cfg.CodeBlock.StylePrimitive},
+		{"table", cfg.Table.StylePrimitive},
+		{"dt", cfg.DefinitionTerm},
+		{"dd", cfg.DefinitionDescription},
+	}
+
+	var b strings.Builder
+	b.WriteString(baseCSS)
+	for _, r := range rules {
+		if d := declarations(r.style); d != "" {
+			fmt.Fprintf(&b, "%s { %s; }\n", r.selector, d)
+		}
+	}
+	// rules and borders are drawn in the color of glamour's rules
+	if c := cssColor(border.Color); c != "" {
+		fmt.Fprintf(&b, "hr, blockquote, th, td { border-color: %s; }\n", c)
+	}
+	return b.String()
+}
+
+// chromaStyle returns the chroma style code blocks are highlighted with.
+// Glamour styles either define their own colors or name a chroma theme.
+func chromaStyle(cfg glamouransi.StyleCodeBlock) (*chroma.Style, error) {
+	if cfg.Chroma == nil {
+		if cfg.Theme != "" {
+			return styles.Get(cfg.Theme), nil
+		}
+		return chroma.NewStyle("glow", chroma.StyleEntries{}) //nolint:wrapcheck
+	}
+
+	c := cfg.Chroma
+	tokens := map[chroma.TokenType]glamouransi.StylePrimitive{
+		chroma.Text:                c.Text,
+		chroma.Error:               c.Error,
+		chroma.Comment:             c.Comment,
+		chroma.CommentPreproc:      c.CommentPreproc,
+		chroma.Keyword:             c.Keyword,
+		chroma.KeywordReserved:     c.KeywordReserved,
+		chroma.KeywordNamespace:    c.KeywordNamespace,
+		chroma.KeywordType:         c.KeywordType,
+		chroma.Operator:            c.Operator,
+		chroma.Punctuation:         c.Punctuation,
+		chroma.Name:                c.Name,
+		chroma.NameBuiltin:         c.NameBuiltin,
+		chroma.NameTag:             c.NameTag,
+		chroma.NameAttribute:       c.NameAttribute,
+		chroma.NameClass:           c.NameClass,
+		chroma.NameConstant:        c.NameConstant,
+		chroma.NameDecorator:       c.NameDecorator,
+		chroma.NameException:       c.NameException,
+		chroma.NameFunction:        c.NameFunction,
+		chroma.NameOther:           c.NameOther,
+		chroma.Literal:             c.Literal,
+		chroma.LiteralNumber:       c.LiteralNumber,
+		chroma.LiteralDate:         c.LiteralDate,
+		chroma.LiteralString:       c.LiteralString,
+		chroma.LiteralStringEscape: c.LiteralStringEscape,
+		chroma.GenericDeleted:      c.GenericDeleted,
+		chroma.GenericEmph:         c.GenericEmph,
+		chroma.GenericInserted:     c.GenericInserted,
+		chroma.GenericStrong:       c.GenericStrong,
+		chroma.GenericSubheading:   c.GenericSubheading,
+		chroma.Background:          c.Background,
+	}
+	entries := chroma.StyleEntries{}
+	for t, p := range tokens {
+		var e []string
+		if col := cssColor(p.Color); col != "" {
+			e = append(e, col)
+		}
+		if col := cssColor(p.BackgroundColor); col != "" {
+			e = append(e, "bg:"+col)
+		}
+		if p.Italic != nil && *p.Italic {
+			e = append(e, "italic")
+		}
+		if p.Bold != nil && *p.Bold {
+			e = append(e, "bold")
+		}
+		if p.Underline != nil && *p.Underline {
+			e = append(e, "underline")
+		}
+		if len(e) > 0 {
+			entries[t] = strings.Join(e, " ")
+		}
+	}
+	style, err := chroma.NewStyle("glow", entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create code style: %w", err)
+	}
+	return style, nil
+}
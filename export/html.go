@@ -0,0 +1,240 @@
+// Package export turns markdown documents into files that can be shared
+// with people who don't read them in a terminal.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	glamouransi "github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// HTMLOptions configures HTML export.
+type HTMLOptions struct {
+	// Title of the page. The first heading is used if it's empty.
+	Title string
+	// BaseURL relative links and images are resolved against.
+	BaseURL string
+	// Style is the glamour style the page's colors are taken from.
+	Style glamouransi.StyleConfig
+	// TOC adds a table of contents linking to the headings.
+	TOC bool
+	// Script is JavaScript run by the page.
+	Script string
+}
+
+// heading is a heading of the exported document.
+type heading struct {
+	level int
+	id    string
+	text  string
+}
+
+// HTML renders a markdown document as a standalone HTML page, with its CSS
+// embedded.
+func HTML(md []byte, opts HTMLOptions) ([]byte, error) {
+	code, err := newCodeRenderer(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	gm := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// raw HTML is left out, as are links to javascript: URLs: exported
+		// pages are meant to be shared, and their documents may be remote
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(code, 100)), //nolint:mnd
+		),
+	)
+
+	ctx := parser.NewContext(parser.WithIDs(headingIDs{utils.Slugs{}}))
+	doc := gm.Parser().Parse(text.NewReader(md), parser.WithContext(ctx))
+	headings := documentHeadings(doc, md)
+
+	var body bytes.Buffer
+	if err := gm.Renderer().Render(&body, md, doc); err != nil {
+		return nil, fmt.Errorf("unable to render html: %w", err)
+	}
+
+	title := opts.Title
+	if title == "" && len(headings) > 0 {
+		title = headings[0].text
+	}
+
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<meta name=\"generator\" content=\"Glow\">\n")
+	if opts.BaseURL != "" {
+		b.WriteString("<base href=\"" + html.EscapeString(opts.BaseURL) + "\">\n")
+	}
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>\n" + stylesheet(opts.Style))
+	if err := code.formatter.WriteCSS(&b, code.style); err != nil {
+		return nil, fmt.Errorf("unable to write css: %w", err)
+	}
+	b.WriteString("</style>\n</head>\n<body>\n")
+	if opts.TOC {
+		b.WriteString(tableOfContents(headings))
+	}
+	b.WriteString("<main>\n")
+	b.Write(body.Bytes())
+	b.WriteString("</main>\n")
+	if opts.Script != "" {
+		b.WriteString("<script>\n" + opts.Script + "\n</script>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes(), nil
+}
+
+// headingIDs gives headings the anchors GitHub gives them, so links to
+// sections keep working.
+type headingIDs struct {
+	slugs utils.Slugs
+}
+
+func (ids headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	return []byte(ids.slugs.Slug(string(value)))
+}
+
+func (ids headingIDs) Put(value []byte) {
+	ids.slugs[string(value)] = 0
+}
+
+func documentHeadings(doc ast.Node, src []byte) []heading {
+	var headings []heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		id, _ := h.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, heading{
+			level: h.Level,
+			id:    string(idBytes),
+			text:  nodeText(h, src),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// nodeText returns the text of an inline node, without markup.
+func nodeText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(src))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// tableOfContents returns a nested list of links to the headings.
+func tableOfContents(headings []heading) string {
+	if len(headings) < 2 { //nolint:mnd
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("<nav class=\"toc\">\n")
+	// the levels of the open lists
+	var levels []int
+	for _, h := range headings {
+		switch {
+		case len(levels) == 0 || h.level > levels[len(levels)-1]:
+			b.WriteString("<ul>\n")
+			levels = append(levels, h.level)
+		default:
+			for len(levels) > 1 && h.level < levels[len(levels)-1] {
+				b.WriteString("</li>\n</ul>\n")
+				levels = levels[:len(levels)-1]
+			}
+			b.WriteString("</li>\n")
+		}
+		fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a>\n", html.EscapeString(h.id), html.EscapeString(h.text))
+	}
+	for range levels {
+		b.WriteString("</li>\n</ul>\n")
+	}
+	b.WriteString("</nav>\n")
+	return b.String()
+}
+
+// codeRenderer highlights code blocks with chroma, in the colors of the
+// glamour style.
+type codeRenderer struct {
+	formatter *chromahtml.Formatter
+	style     *chroma.Style
+}
+
+func newCodeRenderer(cfg glamouransi.StyleConfig) (*codeRenderer, error) {
+	style, err := chromaStyle(cfg.CodeBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &codeRenderer{
+		formatter: chromahtml.New(chromahtml.WithClasses(true)),
+		style:     style,
+	}, nil
+}
+
+func (r *codeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.render)
+	reg.Register(ast.KindCodeBlock, r.render)
+}
+
+func (r *codeRenderer) render(w util.BufWriter, src []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var code strings.Builder
+	lines := n.Lines()
+	for i := range lines.Len() {
+		seg := lines.At(i)
+		code.Write(seg.Value(src))
+	}
+	var lexer chroma.Lexer
+	if fb, ok := n.(*ast.FencedCodeBlock); ok {
+		lexer = lexers.Get(string(fb.Language(src)))
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("unable to highlight code: %w", err)
+	}
+	if err := r.formatter.Format(w, r.style, it); err != nil {
+		return ast.WalkStop, fmt.Errorf("unable to highlight code: %w", err)
+	}
+	_ = w.WriteByte('\n')
+	return ast.WalkSkipChildren, nil
+}
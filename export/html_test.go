@@ -0,0 +1,126 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+)
+
+func TestHTML(t *testing.T) {
+	md := strings.Join([]string{
+		"# Runbook",
+		"",
+		"Restart the *service* with `systemctl`.",
+		"",
+		"## Restart",
+		"",
+		"```go",
+		"func main() {}",
+		"```",
+		"",
+		"### Check `status`",
+		"",
+		"## Restart",
+	}, "\n")
+
+	out, err := HTML([]byte(md), HTMLOptions{Style: styles.DarkStyleConfig, TOC: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"<title>Runbook</title>",
+		"<h1 id=\"runbook\">Runbook</h1>",
+		"<em>service</em>",
+		"<code>systemctl</code>",
+		// headings get GitHub's anchors
+		"<h2 id=\"restart-1\">Restart</h2>",
+		"<h3 id=\"check-status\">Check <code>status</code></h3>",
+		// highlighted code and its colors
+		"<span class=\"kd\">func</span>",
+		".chroma .kd { color: #00aaff }",
+		// colors of the glamour style
+		"body { color: #d0d0d0; background-color: #1c1c1c; }",
+		"h1 { color: #ffff87; background-color: #5f5fff; font-weight: bold; }",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTMLOmitsRawHTML(t *testing.T) {
+	md := "Text <script>alert(1)</script>\n\n<div onclick=\"alert(2)\">block</div>\n\n[link](javascript:alert(3))\n"
+	out, err := HTML([]byte(md), HTMLOptions{Style: styles.DarkStyleConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, unwanted := range []string{"<script", "onclick", "javascript:"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestHTMLLightStyle(t *testing.T) {
+	out, err := HTML([]byte("text"), HTMLOptions{Style: styles.LightStyleConfig, BaseURL: "https://example.com/docs/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"background-color: #ffffff",
+		"<base href=\"https://example.com/docs/\">",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<nav") {
+		t.Error("the table of contents should be left out")
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	got := tableOfContents([]heading{
+		{level: 2, id: "a", text: "A"},
+		{level: 3, id: "b", text: "B"},
+		{level: 2, id: "c", text: "C & D"},
+	})
+	want := strings.Join([]string{
+		"<nav class=\"toc\">",
+		"<ul>",
+		"<li><a href=\"#a\">A</a>",
+		"<ul>",
+		"<li><a href=\"#b\">B</a>",
+		"</li>",
+		"</ul>",
+		"</li>",
+		"<li><a href=\"#c\">C &amp; D</a>",
+		"</li>",
+		"</ul>",
+		"</nav>",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCSSColor(t *testing.T) {
+	for in, want := range map[string]string{
+		"#123abc": "#123abc",
+		"1":       "#800000",
+		"63":      "#5f5fff",
+		"252":     "#d0d0d0",
+		"300":     "",
+		"red":     "",
+		"":        "",
+	} {
+		if got := cssColor(&in); got != want {
+			t.Errorf("cssColor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	glamouransi "github.com/charmbracelet/glamour/ansi"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/termenv"
+)
+
+// Metrics of the SVG grid, in pixels. Text is stretched to the width of its
+// cells, so columns line up whatever monospace font is used.
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4
+	svgLineHeight = 18
+	svgBaseline   = 14
+	svgPadding    = 16
+	tabWidth      = 8
+)
+
+const monospaceFonts = "ui-monospace, SFMono-Regular, Menlo, Consolas, monospace"
+
+// TerminalOptions configures exports of terminal output.
+type TerminalOptions struct {
+	// Title of the page or image.
+	Title string
+	// Colors of text and of the background, where the output doesn't set
+	// them. See TerminalColors.
+	Foreground, Background string
+}
+
+// TerminalColors returns the colors of text and of the background of a
+// terminal showing a document in the given glamour style.
+func TerminalColors(cfg glamouransi.StyleConfig) (string, string) {
+	fg := cssColor(cfg.Document.Color)
+	bg := cssColor(cfg.Document.BackgroundColor)
+	if bg == "" {
+		bg = lightBackground
+		if fg != "" {
+			if l, _, _ := termenv.ConvertToRGB(termenv.RGBColor(fg)).Lab(); l > 0.5 { //nolint:mnd
+				bg = darkBackground
+			}
+		}
+	}
+	if fg == "" {
+		fg = darkForeground
+		if bg == lightBackground {
+			fg = lightForeground
+		}
+	}
+	return fg, bg
+}
+
+// cellStyle is how text is drawn, as set by SGR escape sequences.
+type cellStyle struct {
+	fg, bg                                           string
+	bold, faint, italic, underline, strike, inverted bool
+}
+
+// span is text drawn in the same style.
+type span struct {
+	text  string
+	width int
+	style cellStyle
+}
+
+// visible returns whether a span shows anything besides its background.
+func (s span) visible() bool {
+	return strings.TrimSpace(s.text) != "" || s.style.underline || s.style.strike
+}
+
+// parseANSI splits terminal output into lines of styled spans. SGR
+// sequences set the style; other escape sequences are dropped.
+func parseANSI(out string) [][]span {
+	var (
+		lines [][]span
+		line  []span
+		style cellStyle
+		text  strings.Builder
+		width int
+		col   int
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			st := style
+			// the color of blanks doesn't show, so they join their
+			// neighbors
+			if strings.TrimSpace(text.String()) == "" && st.bg == "" && !st.inverted && !st.underline && !st.strike {
+				st = cellStyle{}
+			}
+			if n := len(line); n > 0 && line[n-1].style == st {
+				line[n-1].text += text.String()
+				line[n-1].width += width
+			} else {
+				line = append(line, span{text: text.String(), width: width, style: st})
+			}
+		}
+		text.Reset()
+		width = 0
+	}
+	endLine := func() {
+		flush()
+		// trailing blanks only pad the line
+		for len(line) > 0 && !line[len(line)-1].visible() && line[len(line)-1].style.bg == "" {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, line)
+		line = nil
+		col = 0
+	}
+
+	runes := []rune(strings.ReplaceAll(out, "\r\n", "\n"))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			endLine()
+		case r == '\t':
+			n := tabWidth - col%tabWidth
+			text.WriteString(strings.Repeat(" ", n))
+			width += n
+			col += n
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == '[':
+			// CSI: parameters up to a final byte
+			j := i + 2
+			for j < len(runes) && (runes[j] < 0x40 || runes[j] > 0x7e) {
+				j++
+			}
+			if j < len(runes) && runes[j] == 'm' {
+				flush()
+				style = style.apply(string(runes[i+2 : j]))
+			}
+			i = j
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == ']':
+			// OSC, like hyperlinks: up to BEL or ST
+			j := i + 2
+			for j < len(runes) && runes[j] != '\a' && !(runes[j] == '\x1b' && j+1 < len(runes) && runes[j+1] == '\\') {
+				j++
+			}
+			if j < len(runes) && runes[j] == '\x1b' {
+				j++
+			}
+			i = j
+		case r == '\x1b':
+			i++
+		case r < ' ' || r == 0x7f:
+		default:
+			w := runewidth.RuneWidth(r)
+			text.WriteRune(r)
+			width += w
+			col += w
+		}
+	}
+	endLine()
+
+	// blank lines around the output only add to the padding
+	for len(lines) > 0 && len(lines[0]) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// apply returns the style after an SGR sequence with the given parameters.
+func (s cellStyle) apply(params string) cellStyle {
+	var codes []int
+	for _, p := range strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' }) {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return s
+		}
+		codes = append(codes, n)
+	}
+	if len(codes) == 0 {
+		return cellStyle{}
+	}
+
+	for i := 0; i < len(codes); i++ {
+		c := codes[i]
+		switch {
+		case c == 0:
+			s = cellStyle{}
+		case c == 1:
+			s.bold = true
+		case c == 2: //nolint:mnd
+			s.faint = true
+		case c == 3: //nolint:mnd
+			s.italic = true
+		case c == 4: //nolint:mnd
+			s.underline = true
+		case c == 7: //nolint:mnd
+			s.inverted = true
+		case c == 9: //nolint:mnd
+			s.strike = true
+		case c == 22: //nolint:mnd
+			s.bold, s.faint = false, false
+		case c == 23: //nolint:mnd
+			s.italic = false
+		case c == 24: //nolint:mnd
+			s.underline = false
+		case c == 27: //nolint:mnd
+			s.inverted = false
+		case c == 29: //nolint:mnd
+			s.strike = false
+		case c >= 30 && c <= 37:
+			s.fg = paletteColor(c - 30)
+		case c >= 90 && c <= 97:
+			s.fg = paletteColor(c - 90 + 8)
+		case c == 39: //nolint:mnd
+			s.fg = ""
+		case c >= 40 && c <= 47:
+			s.bg = paletteColor(c - 40)
+		case c >= 100 && c <= 107:
+			s.bg = paletteColor(c - 100 + 8)
+		case c == 49: //nolint:mnd
+			s.bg = ""
+		case c == 38 || c == 48:
+			color, n := extendedColor(codes[i+1:])
+			if c == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+			i += n
+		}
+	}
+	return s
+}
+
+// extendedColor reads a 256 color or a true color, like 5;n or 2;r;g;b. It
+// returns the color and how many parameters it took.
+func extendedColor(codes []int) (string, int) {
+	switch {
+	case len(codes) >= 2 && codes[0] == 5:
+		return paletteColor(codes[1]), 2 //nolint:mnd
+	case len(codes) >= 4 && codes[0] == 2:
+		return fmt.Sprintf("#%02x%02x%02x", codes[1]&0xff, codes[2]&0xff, codes[3]&0xff), 4 //nolint:mnd
+	}
+	return "", len(codes)
+}
+
+// colors returns the colors a style is drawn in. An empty background is
+// the terminal's.
+func (s cellStyle) colors(opts TerminalOptions) (string, string) {
+	fg, bg := s.fg, s.bg
+	if fg == "" {
+		fg = opts.Foreground
+	}
+	if s.inverted {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = opts.Background
+		}
+	}
+	return fg, bg
+}
+
+// TerminalHTML turns terminal output into an HTML page showing it in a
+// <pre> element, in the same colors.
+func TerminalHTML(out string, opts TerminalOptions) []byte {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + html.EscapeString(opts.Title) + "</title>\n</head>\n")
+	fmt.Fprintf(&b, "<body style=\"margin: 0; background-color: %s;\">\n", opts.Background)
+	// browsers drop the newline right after <pre>
+	fmt.Fprintf(&b, "<pre style=\"margin: 0; padding: 1rem; color: %s; background-color: %s; font-family: %s; line-height: 1.3;\">\n",
+		opts.Foreground, opts.Background, monospaceFonts)
+	for i, line := range parseANSI(out) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, s := range line {
+			text := html.EscapeString(s.text)
+			if css := s.style.css(opts); css != "" {
+				fmt.Fprintf(&b, "<span style=\"%s\">%s</span>", css, text)
+			} else {
+				b.WriteString(text)
+			}
+		}
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return []byte(b.String())
+}
+
+// css returns the inline CSS of a span in the given style.
+func (s cellStyle) css(opts TerminalOptions) string {
+	fg, bg := s.colors(opts)
+	var d []string
+	if fg != opts.Foreground {
+		d = append(d, "color: "+fg)
+	}
+	if bg != "" {
+		d = append(d, "background-color: "+bg)
+	}
+	if s.bold {
+		d = append(d, "font-weight: bold")
+	}
+	if s.faint {
+		d = append(d, "opacity: 0.7")
+	}
+	if s.italic {
+		d = append(d, "font-style: italic")
+	}
+	if decoration := s.decoration(); decoration != "" {
+		d = append(d, "text-decoration: "+decoration)
+	}
+	return strings.Join(d, "; ")
+}
+
+func (s cellStyle) decoration() string {
+	var d []string
+	if s.underline {
+		d = append(d, "underline")
+	}
+	if s.strike {
+		d = append(d, "line-through")
+	}
+	return strings.Join(d, " ")
+}
+
+// TerminalSVG turns terminal output into an SVG image of it, in the same
+// colors.
+func TerminalSVG(out string, opts TerminalOptions) []byte {
+	lines := parseANSI(out)
+	cols := 0
+	for _, line := range lines {
+		w := 0
+		for _, s := range line {
+			w += s.width
+		}
+		cols = max(cols, w)
+	}
+	px := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	x := func(col int) string { return px(svgPadding + float64(col)*svgCellWidth) }
+	width := px(svgPadding*2 + float64(cols)*svgCellWidth)
+	height := strconv.Itoa(svgPadding*2 + len(lines)*svgLineHeight)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n", width, height, width, height)
+	if opts.Title != "" {
+		b.WriteString("<title>" + html.EscapeString(opts.Title) + "</title>\n")
+	}
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", opts.Background)
+	fmt.Fprintf(&b, "<g font-family=\"%s\" font-size=\"%d\" fill=\"%s\" xml:space=\"preserve\">\n", monospaceFonts, svgFontSize, opts.Foreground)
+	for row, line := range lines {
+		top := svgPadding + row*svgLineHeight
+		col := 0
+		for _, s := range line {
+			fg, bg := s.style.colors(opts)
+			if bg != "" {
+				fmt.Fprintf(&b, "<rect x=\"%s\" y=\"%d\" width=\"%s\" height=\"%d\" fill=\"%s\"/>\n",
+					x(col), top, px(float64(s.width)*svgCellWidth), svgLineHeight, bg)
+			}
+			if s.visible() {
+				fmt.Fprintf(&b, "<text x=\"%s\" y=\"%d\" textLength=\"%s\" lengthAdjust=\"spacingAndGlyphs\"%s>%s</text>\n",
+					x(col), top+svgBaseline, px(float64(s.width)*svgCellWidth),
+					s.style.svgAttributes(fg, opts), html.EscapeString(s.text))
+			}
+			col += s.width
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return []byte(b.String())
+}
+
+// svgAttributes returns the attributes of text drawn in the style.
+func (s cellStyle) svgAttributes(fg string, opts TerminalOptions) string {
+	var b strings.Builder
+	if fg != opts.Foreground {
+		b.WriteString(" fill=\"" + fg + "\"")
+	}
+	if s.bold {
+		b.WriteString(" font-weight=\"bold\"")
+	}
+	if s.faint {
+		b.WriteString(" fill-opacity=\"0.7\"")
+	}
+	if s.italic {
+		b.WriteString(" font-style=\"italic\"")
+	}
+	if d := s.decoration(); d != "" {
+		b.WriteString(" text-decoration=\"" + d + "\"")
+	}
+	return b.String()
+}
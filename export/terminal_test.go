@@ -0,0 +1,71 @@
+package export
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// terminalOutput has the kinds of escape sequences glamour emits, and a
+// hyperlink, a tab and wide characters.
+const terminalOutput = "\n" +
+	"  \x1b[38;5;228;48;5;63;1m Title \x1b[0m\x1b[37m   \x1b[0m\n" +
+	"\n" +
+	"  \x1b[37mSome \x1b[0m\x1b[37;3mtext\x1b[0m\x1b[37m and \x1b[0m\x1b[38;2;255;95;95;48;5;236m code \x1b[0m\n" +
+	"  \x1b]8;;https://example.com\x1b\\\x1b[36;4mlink\x1b[0m\x1b]8;;\x1b\\ 日本\tend\n" +
+	"  \x1b[7minverted\x1b[27m \x1b[2mfaint\x1b[22m \x1b[9mgone\x1b[0m\n" +
+	"\n"
+
+func TestParseANSI(t *testing.T) {
+	got := parseANSI("\x1b[1;31mred\x1b[39m bold\x1b[0m  \x1b[37m  \x1b[0m\n\n")
+	want := [][]span{{
+		{text: "red", width: 3, style: cellStyle{fg: "#800000", bold: true}},
+		{text: " bold", width: 5, style: cellStyle{bold: true}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestTerminalExport(t *testing.T) {
+	opts := TerminalOptions{Title: "test.md"}
+	opts.Foreground, opts.Background = TerminalColors(styles.DarkStyleConfig)
+
+	for name, got := range map[string][]byte{
+		"terminal.svg":  TerminalSVG(terminalOutput, opts),
+		"terminal.html": TerminalHTML(terminalOutput, opts),
+	} {
+		path := filepath.Join("testdata", name)
+		if *update {
+			if err := os.WriteFile(path, got, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s doesn't match the golden file, got:\n%s", name, got)
+		}
+	}
+}
+
+func TestTerminalColors(t *testing.T) {
+	for name, want := range map[string][2]string{
+		styles.DarkStyle:  {"#d0d0d0", "#1c1c1c"},
+		styles.LightStyle: {"#1c1c1c", "#ffffff"},
+		styles.NoTTYStyle: {"#262626", "#ffffff"},
+	} {
+		fg, bg := TerminalColors(*styles.DefaultStyles[name])
+		if fg != want[0] || bg != want[1] {
+			t.Errorf("%s: got %s on %s, want %s on %s", name, fg, bg, want[0], want[1])
+		}
+	}
+}
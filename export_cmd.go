@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/glow/v2/export"
+	"github.com/charmbracelet/glow/v2/mermaid"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Export formats.
+const (
+	// a standalone HTML page of the document
+	exportHTML = "html"
+	// the terminal output, in an HTML <pre> element
+	exportPre = "pre"
+	// the terminal output, as an SVG image
+	exportSVG = "svg"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+	exportStyle  string
+	exportWidth  uint
+	exportNoTOC  bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:     "export SOURCE",
+	Short:   "Export a document to HTML, or its terminal output to SVG",
+	Long:    paragraph(fmt.Sprintf("\n%s a document to a standalone HTML page, with its CSS embedded, its code highlighted and a table of contents. The colors are taken from the glamour style.\n\nThe pre and svg formats capture what glow shows in the terminal instead, at the given width, in an HTML <pre> element or an SVG image.", keyword("Export"))),
+	Example: paragraph("glow export --format html docs/runbook.md -o runbook.html\nglow export -s light README.md > readme.html\nglow export --format svg -w 60 -s dark README.md -o readme.svg"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat != exportHTML && exportFormat != exportPre && exportFormat != exportSVG {
+			return fmt.Errorf("unsupported export format: %s", exportFormat)
+		}
+
+		// the configured style, unless one was given for the export. The
+		// terminal's background only picks the auto style once, so the
+		// terminal output is rendered the same way.
+		styleName := exportStyle
+		if styleName == "" {
+			styleName = viper.GetString("style")
+		}
+		if styleName == styles.AutoStyle {
+			styleName = styles.LightStyle
+			if lipgloss.HasDarkBackground() {
+				styleName = styles.DarkStyle
+			}
+		}
+		if err := validateStyle(styleName); err != nil {
+			return err
+		}
+		cfg, err := utils.StyleConfig(styleName)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		src, err := openSource(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+
+		if exportFormat != exportHTML {
+			out, err := exportTerminal(src, styleName, cfg)
+			if err != nil {
+				return err
+			}
+			return writeOutput(exportOutput, out)
+		}
+
+		md, err := documentMarkdown(src)
+		if err != nil {
+			return err
+		}
+
+		opts := export.HTMLOptions{Style: cfg, TOC: !exportNoTOC}
+		// relative links of remote documents point next to them
+		if isURL(src.URL) {
+			if u, err := url.Parse(src.URL); err == nil {
+				u.Path = path.Dir(u.Path) + "/"
+				opts.BaseURL = u.String()
+			}
+		}
+		out, err := export.HTML([]byte(mermaid.RenderBlocks(md)), opts)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		return writeOutput(exportOutput, out)
+	},
+}
+
+// exportTerminal renders a source like glow does in a terminal, in true
+// color, and turns the output into an image of it.
+func exportTerminal(src *source, styleName string, cfg ansi.StyleConfig) ([]byte, error) {
+	out, _, err := renderSource(src, renderOptions{
+		style:   styleName,
+		width:   exportWidth,
+		profile: termenv.TrueColor,
+		// code is highlighted in true color too: the nearest colors chroma
+		// picks from the 256 color palette vary from run to run
+		chromaFormatter: "terminal16m",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := export.TerminalOptions{Title: filepath.Base(src.URL)}
+	opts.Foreground, opts.Background = export.TerminalColors(cfg)
+	if exportFormat == exportSVG {
+		return export.TerminalSVG(out, opts), nil
+	}
+	return export.TerminalHTML(out, opts), nil
+}
+
+// writeOutput writes to a file, or to stdout when the file is empty or "-".
+func writeOutput(file string, b []byte) error {
+	if file == "" || file == "-" {
+		if _, err := os.Stdout.Write(b); err != nil {
+			return fmt.Errorf("unable to write to stdout: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(file, b, 0o644); err != nil { //nolint:gosec,mnd
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to write %s: the directory doesn't exist", file)
+		}
+		return fmt.Errorf("unable to write file: %w", err)
+	}
+	return nil
+}
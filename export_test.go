@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestExportTerminalKeepsSettings(t *testing.T) {
+	savedStyle, savedWidth, savedProfile := style, width, lipgloss.ColorProfile()
+	t.Cleanup(func() {
+		style, width = savedStyle, savedWidth
+		lipgloss.SetColorProfile(savedProfile)
+	})
+	style, width = styles.NoTTYStyle, 40
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	src := &source{
+		reader: io.NopCloser(strings.NewReader("# Title\n\n```go\nfunc main() {}\n```\n")),
+		URL:    "doc.md",
+	}
+	cfg, err := utils.StyleConfig(styles.DarkStyle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exportTerminal(src, styles.DarkStyle, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte("<svg")) && !bytes.Contains(out, []byte("<pre")) {
+		t.Errorf("expected an export, got %q", out)
+	}
+
+	if style != styles.NoTTYStyle || width != 40 || lipgloss.ColorProfile() != termenv.Ascii {
+		t.Errorf("expected the settings to be kept, got style %q, width %d and profile %v", style, width, lipgloss.ColorProfile())
+	}
+}
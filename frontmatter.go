@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var frontMatterNameColor = lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}
+
+// frontMatterTable renders the front matter of a document as a compact
+// table of its fields, to be shown above the document.
+func frontMatterTable(src []byte, opts renderOptions) string {
+	fields, _, err := structure.FrontMatter(src)
+	if err != nil {
+		return ""
+	}
+	metadata := structure.Metadata(fields)
+	if len(metadata) == 0 {
+		return ""
+	}
+
+	var nameWidth int
+	for _, f := range metadata {
+		nameWidth = max(nameWidth, ansi.StringWidth(f.Name))
+	}
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(opts.profile)
+	r.SetHasDarkBackground(lipgloss.HasDarkBackground())
+	nameStyle := r.NewStyle().Foreground(frontMatterNameColor)
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, f := range metadata {
+		line := fmt.Sprintf("  %s  %s", nameStyle.Render(fmt.Sprintf("%-*s", nameWidth, f.Name)), f.Value)
+		if opts.width > 0 {
+			line = ansi.Truncate(line, int(opts.width), "…") //nolint:gosec
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitShow returns the contents of path as of the given git revision. path
+// is a local path, relative to the current working directory or absolute.
+func gitShow(ctx context.Context, rev, path string) ([]byte, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get absolute path: %w", err)
+	}
+
+	// git resolves paths starting with ./ relative to the directory it runs
+	// in rather than the repository root. Running it next to the file lets
+	// the file's own repository resolve it, wherever glow runs.
+	return runGit(ctx, filepath.Dir(abs), "show", "--end-of-options", rev+":./"+filepath.Base(abs))
+}
+
+// gitMergeBase returns the best common ancestor of two revisions.
+func gitMergeBase(ctx context.Context, a, b string) (string, error) {
+	out, err := runGit(ctx, "", "merge-base", "--end-of-options", a, b)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runGit runs a git command in dir, or the current working directory if
+// it's empty, and returns its output. Callers pass --end-of-options before
+// revisions from the user, so that revisions starting with a dash aren't
+// taken for options.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Dir = dir
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("unable to find git: make sure it's installed and in your PATH")
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("unable to run git: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// gitSource creates a readable source for a file as of a git revision.
+func gitSource(ctx context.Context, rev, path string) (*source, error) {
+	b, err := gitShow(ctx, rev, path)
+	if err != nil {
+		return nil, err
+	}
+	u, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get absolute path: %w", err)
+	}
+	return &source{
+		reader: io.NopCloser(bytes.NewReader(b)),
+		URL:    u,
+		rev:    rev,
+	}, nil
+}
+
+// parseRevRange parses a revision range such as "main..feature". A missing
+// revision on either side of ".." means HEAD; a single revision is compared
+// to the working tree, which is returned as an empty revision. For
+// "a...b" the first revision is the merge base of both.
+func parseRevRange(ctx context.Context, spec string) (string, string, error) {
+	if spec == "" {
+		return "", "", errors.New("missing revision range")
+	}
+
+	if a, b, ok := strings.Cut(spec, "..."); ok {
+		a, b = orHEAD(a), orHEAD(b)
+		base, err := gitMergeBase(ctx, a, b)
+		if err != nil {
+			return "", "", err
+		}
+		return base, b, nil
+	}
+	if a, b, ok := strings.Cut(spec, ".."); ok {
+		return orHEAD(a), orHEAD(b), nil
+	}
+	return spec, "", nil
+}
+
+func orHEAD(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
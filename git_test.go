@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRevRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		from, to string
+	}{
+		{"main..feature", "main", "feature"},
+		{"main..", "main", "HEAD"},
+		{"..feature", "HEAD", "feature"},
+		{"HEAD~3", "HEAD~3", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			from, to, err := parseRevRange(context.Background(), tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("parseRevRange(%q) = %q, %q, want %q, %q", tt.spec, from, to, tt.from, tt.to)
+			}
+		})
+	}
+
+	if _, _, err := parseRevRange(context.Background(), ""); err == nil {
+		t.Error("expected error for empty range")
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=glow", "-c", "user.email=glow@example.com"}, args...)...)
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	file := filepath.Join(dir, "docs", "README.md")
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "-q")
+	if err := os.WriteFile(file, []byte("# First"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-qm", "first")
+	if err := os.WriteFile(file, []byte("# Second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(filepath.Join(dir, "docs"))
+
+	src, err := gitSource(context.Background(), "HEAD", "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(src.reader)
+	if string(b) != "# First" {
+		t.Errorf("expected committed content, got %q", b)
+	}
+	if src.rev != "HEAD" {
+		t.Errorf("expected rev HEAD, got %q", src.rev)
+	}
+
+	if _, err := gitSource(context.Background(), "HEAD", "missing.md"); err == nil {
+		t.Error("expected error for file missing from revision")
+	}
+	if _, err := gitSource(context.Background(), "HEAD", "."); err == nil {
+		t.Error("expected error for directory")
+	}
+
+	// revisions are never options
+	out := filepath.Join(dir, "out")
+	if _, err := gitSource(context.Background(), "--output="+out, "README.md"); err == nil {
+		t.Error("expected error for a revision starting with a dash")
+	}
+	if _, err := gitMergeBase(context.Background(), "--all", "HEAD"); err == nil {
+		t.Error("expected error for a revision starting with a dash")
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("expected the revision not to be taken for --output")
+	}
+
+	// files are resolved in their own repository
+	t.Chdir(t.TempDir())
+	for _, path := range []string{file, mustRel(t, file)} {
+		src, err := gitSource(context.Background(), "HEAD", path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if b, _ := io.ReadAll(src.reader); string(b) != "# First" {
+			t.Errorf("%s: expected committed content, got %q", path, b)
+		}
+	}
+}
+
+// mustRel returns path relative to the current working directory.
+func mustRel(t *testing.T, path string) string {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +12,7 @@ import (
 )
 
 // findGitHubREADME tries to find the correct README filename in a repository using GitHub API.
-func findGitHubREADME(u *url.URL) (*source, error) {
+func findGitHubREADME(ctx context.Context, u *url.URL) (*source, error) {
 	owner, repo, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
 	if !ok {
 		return nil, fmt.Errorf("invalid url: %s", u.String())
@@ -23,12 +24,11 @@ func findGitHubREADME(u *url.URL) (*source, error) {
 
 	apiURL := fmt.Sprintf("https://api.%s/repos/%s/%s/readme", u.Hostname(), owner, repo)
 
-	//nolint:bodyclose
-	// it is closed on the caller
-	res, err := http.Get(apiURL) //nolint: gosec,noctx
+	res, err := httpGet(ctx, apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get url: %w", err)
+		return nil, err
 	}
+	defer res.Body.Close() //nolint:errcheck
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -43,14 +43,15 @@ func findGitHubREADME(u *url.URL) (*source, error) {
 	if res.StatusCode == http.StatusOK {
 		//nolint:bodyclose
 		// it is closed on the caller
-		resp, err := http.Get(result.DownloadURL) //nolint: noctx
+		resp, err := httpGet(ctx, result.DownloadURL)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get url: %w", err)
+			return nil, err
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			return &source{resp.Body, result.DownloadURL}, nil
+			return &source{reader: resp.Body, URL: result.DownloadURL, cachedAt: responseCachedAt(resp)}, nil
 		}
+		_ = resp.Body.Close()
 	}
 
 	return nil, errors.New("can't find README in GitHub repository")
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +12,7 @@ import (
 )
 
 // findGitLabREADME tries to find the correct README filename in a repository using GitLab API.
-func findGitLabREADME(u *url.URL) (*source, error) {
+func findGitLabREADME(ctx context.Context, u *url.URL) (*source, error) {
 	owner, repo, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
 	if !ok {
 		return nil, fmt.Errorf("invalid url: %s", u.String())
@@ -25,12 +26,11 @@ func findGitLabREADME(u *url.URL) (*source, error) {
 
 	apiURL := fmt.Sprintf("https://%s/api/v4/projects/%s", u.Hostname(), projectPath)
 
-	//nolint:bodyclose
-	// it is closed on the caller
-	res, err := http.Get(apiURL) //nolint: gosec,noctx
+	res, err := httpGet(ctx, apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get url: %w", err)
+		return nil, err
 	}
+	defer res.Body.Close() //nolint:errcheck
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -47,14 +47,15 @@ func findGitLabREADME(u *url.URL) (*source, error) {
 	if res.StatusCode == http.StatusOK {
 		//nolint:bodyclose
 		// it is closed on the caller
-		resp, err := http.Get(readmeRawURL) //nolint: gosec,noctx
+		resp, err := httpGet(ctx, readmeRawURL)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get url: %w", err)
+			return nil, err
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			return &source{resp.Body, readmeRawURL}, nil
+			return &source{reader: resp.Body, URL: readmeRawURL, cachedAt: responseCachedAt(resp)}, nil
 		}
+		_ = resp.Body.Close()
 	}
 
 	return nil, errors.New("can't find README in GitLab repository")
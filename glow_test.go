@@ -27,6 +27,12 @@ func TestGlowFlags(t *testing.T) {
 				return width == 40
 			},
 		},
+		{
+			args: []string{"--clipboard"},
+			check: func() bool {
+				return fromClipboard
+			},
+		},
 	}
 
 	for _, v := range tt {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/glow/v2/utils"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// httpClient is shared by every remote fetch. It's replaced by
+// setupHTTPClient once the flags and config have been read.
+var httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+// httpOptions configures the shared HTTP client.
+type httpOptions struct {
+	timeout   time.Duration
+	userAgent string
+	caFile    string
+
+	// Directory for caching responses. Caching is disabled when empty.
+	cacheDir string
+	// Serve cached responses only, without touching the network.
+	offline bool
+}
+
+// newHTTPClient creates an HTTP client which honors the proxy environment
+// variables (HTTP_PROXY, HTTPS_PROXY and NO_PROXY), sets glow's user agent,
+// optionally trusts an additional CA bundle and caches responses on disk.
+func newHTTPClient(opts httpOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if opts.caFile != "" {
+		pool, err := loadCertPool(utils.ExpandPath(opts.caFile))
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	var next http.RoundTripper = transport
+	if opts.cacheDir != "" {
+		next = &cachingTransport{
+			next:    transport,
+			dir:     opts.cacheDir,
+			offline: opts.offline,
+		}
+	}
+
+	return &http.Client{
+		Timeout: opts.timeout,
+		Transport: &userAgentTransport{
+			next:      next,
+			userAgent: opts.userAgent,
+		},
+	}, nil
+}
+
+// loadCertPool returns the system certificate pool extended with the PEM
+// encoded certificates found in path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle: %s", path)
+	}
+	return pool, nil
+}
+
+// userAgentTransport sets the User-Agent header on every outgoing request.
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.next.RoundTrip(req) //nolint:wrapcheck
+}
+
+func defaultUserAgent() string {
+	return "glow/" + Version
+}
+
+// setupHTTPClient replaces the shared HTTP client with one built from the
+// given options.
+func setupHTTPClient(opts httpOptions) error {
+	c, err := newHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+	httpClient = c
+	return nil
+}
+
+// httpGet issues a GET request for u which is aborted when ctx is done. The
+// caller is responsible for closing the response body.
+func httpGet(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errNotCached) {
+			return nil, fmt.Errorf("%w: %s", errNotCached, u)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return nil, fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("unable to get url: %w", err)
+	}
+	return res, nil
+}
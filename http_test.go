@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := newHTTPClient(httpOptions{timeout: time.Second, userAgent: "glow/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if got != "glow/test" {
+		t.Errorf("expected user agent %q, got %q", "glow/test", got)
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-done
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	c, err := newHTTPClient(httpOptions{timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(srv.URL); err == nil { //nolint:bodyclose
+		t.Error("expected timeout error")
+	}
+}
+
+func TestHTTPGetCancellation(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-done
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	saved := httpClient
+	t.Cleanup(func() { httpClient = saved })
+	httpClient = &http.Client{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err := httpGet(ctx, srv.URL); err == nil { //nolint:bodyclose
+		t.Error("expected cancelled request to fail")
+	}
+}
+
+func TestHTTPClientCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "# Hello")
+	}))
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("untrusted without bundle", func(t *testing.T) {
+		c, err := newHTTPClient(httpOptions{timeout: time.Second})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get(srv.URL); err == nil { //nolint:bodyclose
+			t.Error("expected certificate error")
+		}
+	})
+
+	t.Run("trusted with bundle", func(t *testing.T) {
+		c, err := newHTTPClient(httpOptions{timeout: time.Second, caFile: caFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+	})
+
+	t.Run("invalid bundle", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.pem")
+		if err := os.WriteFile(bad, []byte("not a certificate"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newHTTPClient(httpOptions{caFile: bad}); err == nil {
+			t.Error("expected error for bundle without certificates")
+		}
+	})
+}
+
+func TestSourceFromArgHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.md" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, "# Hello")
+	}))
+	t.Cleanup(srv.Close)
+
+	src, err := sourceFromArg(context.Background(), srv.URL+"/doc.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(src.reader)
+	_ = src.reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "# Hello" {
+		t.Errorf("expected body %q, got %q", "# Hello", b)
+	}
+
+	if _, err := sourceFromArg(context.Background(), srv.URL+"/missing.md"); err == nil {
+		t.Error("expected error for HTTP 404")
+	}
+}
+
+func TestRenderHTMLResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, "<html><head><script>tracking()</script></head><body><h1>Hello</h1><p>from <b>HTML</b></p></body></html>")
+	}))
+	t.Cleanup(srv.Close)
+
+	src, err := sourceFromArg(context.Background(), srv.URL+"/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.reader.Close() //nolint:errcheck
+
+	_, content, err := renderSource(src, defaultRenderOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Hello\n\nfrom **HTML**\n"; content != want {
+		t.Errorf("expected converted markdown %q, got %q", want, content)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/spf13/cobra"
+)
+
+// documentStructure reads a source and parses the structure of its
+// markdown.
+func documentStructure(src *source) (*structure.Document, error) {
+	md, _, err := readMarkdown(src)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := structure.Parse([]byte(md))
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	doc.Source = src.URL
+	return doc, nil
+}
+
+// executeJSON writes the structure of several sources as a JSON array.
+func executeJSON(cmd *cobra.Command, args []string, w io.Writer) error {
+	docs := make([]*structure.Document, 0, len(args))
+	for _, arg := range args {
+		src, err := openSource(cmd.Context(), arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		doc, err := documentStructure(src)
+		_ = src.reader.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		docs = append(docs, doc)
+	}
+	return writeJSON(w, docs)
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("unable to write json: %w", err)
+	}
+	return nil
+}
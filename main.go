@@ -2,6 +2,8 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,17 +12,26 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/glow/v2/convert"
+	"github.com/charmbracelet/glow/v2/datatree"
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/glow/v2/mermaid"
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/tabular"
 	"github.com/charmbracelet/glow/v2/ui"
 	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	gap "github.com/muesli/go-app-paths"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/term"
@@ -32,7 +43,7 @@ var (
 	// CommitSHA as provided by goreleaser.
 	CommitSHA = ""
 
-	readmeNames      = []string{"README.md", "README", "Readme.md", "Readme", "readme.md", "readme"}
+	readmeNames      = []string{"README.md", "README", "README.rst", "README.adoc", "README.asciidoc", "README.org"}
 	configFile       string
 	pager            bool
 	tui              bool
@@ -42,9 +53,22 @@ var (
 	showLineNumbers  bool
 	preserveNewLines bool
 	mouse            bool
+	httpTimeout      time.Duration
+	userAgent        string
+	caFile           string
+	offline          bool
+	fromClipboard    bool
+	revision         string
+	outputFile       string
+	colorMode        string
+	colorProfile     string
+	jsonOutput       bool
+	lineRange        string
+	tocFormat        string
+	showFrontMatter  bool
 
 	rootCmd = &cobra.Command{
-		Use:   "glow [SOURCE|DIR]",
+		Use:   "glow [SOURCE|DIR]...",
 		Short: "Render markdown on the CLI, with pizzazz!",
 		Long: paragraph(
 			fmt.Sprintf("\nRender markdown on the CLI, %s!", keyword("with pizzazz")),
@@ -52,7 +76,7 @@ var (
 		SilenceErrors:    false,
 		SilenceUsage:     true,
 		TraverseChildren: true,
-		Args:             cobra.MaximumNArgs(1),
+		Args:             cobra.ArbitraryArgs,
 		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
 			return nil, cobra.ShellCompDirectiveDefault
 		},
@@ -67,21 +91,41 @@ var (
 type source struct {
 	reader io.ReadCloser
 	URL    string
+
+	// When the source was fetched, if it was served from the offline cache.
+	cachedAt time.Time
+	// The git revision the source was read from, if any.
+	rev string
+	// The media type of a remote source, as reported by the server.
+	contentType string
 }
 
 // sourceFromArg parses an argument and creates a readable source for it.
-func sourceFromArg(arg string) (*source, error) {
+// Remote sources are fetched with the shared HTTP client and aborted when
+// ctx is done.
+func sourceFromArg(ctx context.Context, arg string) (*source, error) {
 	// from stdin
 	if arg == "-" {
 		return &source{reader: os.Stdin}, nil
 	}
 
+	// a man page from the manpath, like man:ls
+	if name, ok := strings.CutPrefix(arg, manPrefix); ok {
+		return manSource(name)
+	}
+
 	// a GitHub or GitLab URL (even without the protocol):
-	src, err := readmeURL(arg)
+	src, err := readmeURL(ctx, arg)
 	if src != nil && err == nil {
 		// if there's an error, try next methods...
 		return src, nil
 	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("request aborted: %w", ctxErr)
+	}
+	if errors.Is(err, errNotCached) {
+		return nil, err
+	}
 
 	// HTTP(S) URLs:
 	if u, err := url.ParseRequestURI(arg); err == nil && strings.Contains(arg, "://") { //nolint:nestif
@@ -90,14 +134,20 @@ func sourceFromArg(arg string) (*source, error) {
 				return nil, fmt.Errorf("%s is not a supported protocol", u.Scheme)
 			}
 			// consumer of the source is responsible for closing the ReadCloser.
-			resp, err := http.Get(u.String()) //nolint: noctx,bodyclose
+			resp, err := httpGet(ctx, u.String())
 			if err != nil {
-				return nil, fmt.Errorf("unable to get url: %w", err)
+				return nil, err
 			}
 			if resp.StatusCode != http.StatusOK {
+				_ = resp.Body.Close()
 				return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
 			}
-			return &source{resp.Body, u.String()}, nil
+			return &source{
+				reader:      resp.Body,
+				URL:         u.String(),
+				cachedAt:    responseCachedAt(resp),
+				contentType: resp.Header.Get("Content-Type"),
+			}, nil
 		}
 	}
 
@@ -109,25 +159,27 @@ func sourceFromArg(arg string) (*source, error) {
 	st, err := os.Stat(arg)
 	if err == nil && st.IsDir() { //nolint:nestif
 		var src *source
-		_ = filepath.Walk(arg, func(path string, _ os.FileInfo, err error) error {
+		_ = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			for _, v := range readmeNames {
-				if strings.EqualFold(filepath.Base(path), v) {
-					r, err := os.Open(path)
-					if err != nil {
-						continue
-					}
-
-					u, _ := filepath.Abs(path)
-					src = &source{r, u}
-
-					// abort filepath.Walk
-					return errors.New("source found")
-				}
+			if !info.IsDir() {
+				return nil
+			}
+			readme := findReadme(path)
+			if readme == "" {
+				return nil
 			}
-			return nil
+			r, err := os.Open(readme)
+			if err != nil {
+				return nil //nolint:nilerr
+			}
+
+			u, _ := filepath.Abs(readme)
+			src = &source{reader: r, URL: u}
+
+			// abort filepath.Walk
+			return errors.New("source found")
 		})
 
 		if src != nil {
@@ -145,7 +197,25 @@ func sourceFromArg(arg string) (*source, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to get absolute path: %w", err)
 	}
-	return &source{r, u}, nil
+	return &source{reader: r, URL: u}, nil
+}
+
+// findReadme returns the path of the README in dir, or an empty string if
+// there is none. Names are matched case-insensitively, in the order of
+// readmeNames, so markdown READMEs are preferred.
+func findReadme(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, name := range readmeNames {
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(e.Name(), name) {
+				return filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	return ""
 }
 
 // validateStyle checks if the style is a default style, if not, checks that
@@ -171,6 +241,11 @@ func validateOptions(cmd *cobra.Command) error {
 	showAllFiles = viper.GetBool("all")
 	preserveNewLines = viper.GetBool("preserveNewLines")
 	showLineNumbers = viper.GetBool("showLineNumbers")
+	httpTimeout = viper.GetDuration("timeout")
+	userAgent = viper.GetString("userAgent")
+	caFile = viper.GetString("caFile")
+	offline = viper.GetBool("offline")
+	showFrontMatter = viper.GetBool("frontMatter")
 
 	if pager && tui {
 		return errors.New("cannot use both pager and tui")
@@ -182,10 +257,56 @@ func validateOptions(cmd *cobra.Command) error {
 		return err
 	}
 
-	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
-	// We want to use a special no-TTY style, when stdout is not a terminal
+	cacheDir, err := getHTTPCacheDir()
+	if err != nil {
+		log.Debug("http cache disabled", "error", err)
+	}
+	if offline && cacheDir == "" {
+		return errors.New("cannot use offline mode without a cache dir")
+	}
+	if err := setupHTTPClient(httpOptions{
+		timeout:   httpTimeout,
+		userAgent: userAgent,
+		caFile:    caFile,
+		cacheDir:  cacheDir,
+		offline:   offline,
+	}); err != nil {
+		return err
+	}
+
+	outputFile = viper.GetString("output")
+	if outputFile != "" && (pager || tui) {
+		return errors.New("cannot use --output with the pager or tui")
+	}
+	if jsonOutput && (pager || tui) {
+		return errors.New("cannot use --json with the pager or tui")
+	}
+	switch tocFormat {
+	case "", tocText, tocMarkdown:
+	default:
+		return fmt.Errorf("unknown table of contents format: %s (use text or markdown)", tocFormat)
+	}
+	if tocFormat != "" && (jsonOutput || tui) {
+		return errors.New("cannot use --toc with --json or the tui")
+	}
+
+	selection.Start, selection.End, err = parseLineRange(lineRange)
+	if err != nil {
+		return err
+	}
+	if selection.MaxLevel < 0 || selection.MaxLevel > 6 { //nolint:mnd
+		return fmt.Errorf("invalid heading level %d: headings have levels 1 to 6", selection.MaxLevel)
+	}
+
+	// output written to a file isn't shown in the terminal
+	isTerminal := outputFile == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	colored, err := setupColors(isTerminal)
+	if err != nil {
+		return err
+	}
+	// We want to use a special no-TTY style, when the output isn't colored
 	// and there was no specific style passed by arg
-	if !isTerminal && !cmd.Flags().Changed("style") {
+	if !colored && !cmd.Flags().Changed("style") {
 		style = "notty"
 	}
 
@@ -208,6 +329,64 @@ func validateOptions(cmd *cobra.Command) error {
 	return nil
 }
 
+// Color modes.
+const (
+	colorAlways = "always"
+	colorNever  = "never"
+	colorAuto   = "auto"
+)
+
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+	"ascii":     termenv.Ascii,
+}
+
+// setupColors sets the color profile output is rendered in, following
+// --color and --color-profile. It returns whether the output is colored.
+func setupColors(isTerminal bool) (bool, error) {
+	colorMode = viper.GetString("color")
+	colorProfile = viper.GetString("colorProfile")
+
+	profile, ok := colorProfiles[colorProfile]
+	if !ok && colorProfile != "" {
+		return false, fmt.Errorf("unknown color profile: %s (use truecolor, 256, 16 or ascii)", colorProfile)
+	}
+
+	switch colorMode {
+	case colorAuto:
+		if !isTerminal {
+			lipgloss.SetColorProfile(termenv.Ascii)
+			return false, nil
+		}
+		if ok {
+			lipgloss.SetColorProfile(profile)
+		}
+	case colorAlways:
+		if !ok {
+			// what the terminal would support, from its environment
+			profile = termenv.NewOutput(os.Stdout, termenv.WithUnsafe()).EnvColorProfile()
+			if profile == termenv.Ascii {
+				profile = termenv.ANSI256
+			}
+		}
+		lipgloss.SetColorProfile(profile)
+	case colorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown color mode: %s (use always, never or auto)", colorMode)
+	}
+	return true, nil
+}
+
+// interactive reports whether the TUI can be opened, rather than the output
+// being written to a file or being something else than the document.
+func interactive() bool {
+	return outputFile == "" && !jsonOutput && tocFormat == ""
+}
+
 func stdinIsPipe() (bool, error) {
 	stat, err := os.Stdin.Stat()
 	if err != nil {
@@ -220,6 +399,33 @@ func stdinIsPipe() (bool, error) {
 }
 
 func execute(cmd *cobra.Command, args []string) error {
+	if outputFile == "" {
+		return executeTo(cmd, args, os.Stdout)
+	}
+
+	// the output file is only written once everything was rendered, so it's
+	// left as it was when something fails
+	var buf bytes.Buffer
+	if err := executeTo(cmd, args, &buf); err != nil {
+		return err
+	}
+	return writeFileAtomically(outputFile, buf.Bytes())
+}
+
+func executeTo(cmd *cobra.Command, args []string, w io.Writer) error {
+	// read from the clipboard, either with no source or with an explicit -.
+	if fromClipboard {
+		if len(args) > 1 || (len(args) == 1 && args[0] != "-") {
+			return errors.New("cannot use --clipboard with other sources")
+		}
+		src, err := clipboardSource()
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		return executeCLI(cmd, src, w)
+	}
+
 	// if stdin is a pipe then use stdin for input. note that you can also
 	// explicitly use a - to read from stdin.
 	if yes, err := stdinIsPipe(); err != nil {
@@ -227,42 +433,130 @@ func execute(cmd *cobra.Command, args []string) error {
 	} else if yes {
 		src := &source{reader: os.Stdin}
 		defer src.reader.Close() //nolint:errcheck
-		return executeCLI(cmd, src, os.Stdout)
+		return executeCLI(cmd, src, w)
+	}
+
+	args, err := expandArgs(args)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		if err := checkOutputFile(outputFile, args); err != nil {
+			return err
+		}
 	}
 
 	switch len(args) {
 	// TUI running on cwd
 	case 0:
-		return runTUI("", "")
+		if !interactive() {
+			return executeArg(cmd, ".", w)
+		}
+		return runTUI(tuiOptions{})
 
 	// TUI with possible dir argument
 	case 1:
 		// Validate that the argument is a directory. If it's not treat it as
-		// an argument to the non-TUI version of Glow (via fallthrough).
+		// an argument to the non-TUI version of Glow.
 		info, err := os.Stat(args[0])
-		if err == nil && info.IsDir() {
+		if err == nil && info.IsDir() && revision == "" && interactive() {
 			p, err := filepath.Abs(args[0])
 			if err == nil {
-				return runTUI(p, "")
+				return runTUI(tuiOptions{path: p})
 			}
 		}
-		fallthrough
+		return executeArg(cmd, args[0], w)
 
-	// CLI
+	// Multiple sources
 	default:
-		for _, arg := range args {
-			if err := executeArg(cmd, arg, os.Stdout); err != nil {
+		if tui || cmd.Flags().Changed("tui") {
+			if revision != "" {
+				return errors.New("cannot open a set of files from a git revision in the TUI")
+			}
+			files, err := localFiles(args)
+			if err != nil {
 				return err
 			}
+			return runTUI(tuiOptions{files: files})
 		}
+		return executeArgs(cmd, args, w)
 	}
+}
 
+// checkOutputFile makes sure the output file isn't one of the documents
+// being rendered, which would be overwritten.
+func checkOutputFile(file string, args []string) error {
+	out, err := os.Stat(file)
+	if err != nil {
+		// a new file can't be an input
+		return nil //nolint:nilerr
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	for _, arg := range args {
+		if arg == "-" || isURL(arg) {
+			continue
+		}
+		path := arg
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			// directories render their README
+			if path = findReadme(path); path == "" {
+				continue
+			}
+		}
+		if info, err := os.Stat(path); err == nil && os.SameFile(info, out) {
+			return fmt.Errorf("cannot write the output to %s, which is being rendered", file)
+		}
+	}
 	return nil
 }
 
+// writeFileAtomically writes a file by renaming a temporary file in the same
+// directory into place, so that it's never left half-written. The mode of an
+// existing file is kept.
+func writeFileAtomically(file string, b []byte) error {
+	mode := os.FileMode(0o644) //nolint:mnd
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	if err := os.Rename(f.Name(), file); err != nil {
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	return nil
+}
+
+// openSource creates a readable source for arg. If a git revision was given
+// with --rev, the source is read from that revision instead.
+func openSource(ctx context.Context, arg string) (*source, error) {
+	if revision != "" {
+		if arg == "-" || isURL(arg) {
+			return nil, fmt.Errorf("%s: only local files can be read from a git revision", arg)
+		}
+		return gitSource(ctx, revision, arg)
+	}
+	return sourceFromArg(ctx, arg)
+}
+
 func executeArg(cmd *cobra.Command, arg string, w io.Writer) error {
 	// create an io.Reader from the markdown source in cli-args
-	src, err := sourceFromArg(arg)
+	src, err := openSource(cmd.Context(), arg)
 	if err != nil {
 		return err
 	}
@@ -271,12 +565,112 @@ func executeArg(cmd *cobra.Command, arg string, w io.Writer) error {
 }
 
 func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
+	if jsonOutput {
+		doc, err := documentStructure(src)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, doc)
+	}
+	if tocFormat != "" {
+		out, err := renderTOC(src)
+		if err != nil {
+			return err
+		}
+		return display(cmd, out, tuiOptions{}, w)
+	}
+
+	out, content, err := renderSource(src, defaultRenderOptions())
+	if err != nil {
+		return err
+	}
+	return display(cmd, out, tuiOptionsForSource(src, content), w)
+}
+
+// renderOptions are the options documents are rendered with.
+type renderOptions struct {
+	style   string
+	width   uint
+	profile termenv.Profile
+	// chromaFormatter is the formatter code is highlighted with, if not
+	// glamour's default.
+	chromaFormatter string
+}
+
+// defaultRenderOptions returns the render options set by the flags and the
+// config file.
+func defaultRenderOptions() renderOptions {
+	return renderOptions{style: style, width: width, profile: lipgloss.ColorProfile()}
+}
+
+// renderSource reads and renders a source. It returns the rendered output
+// as well as the markdown content it was rendered from.
+func renderSource(src *source, opts renderOptions) (string, string, error) {
 	b, err := io.ReadAll(src.reader)
 	if err != nil {
-		return fmt.Errorf("unable to read from reader: %w", err)
+		return "", "", fmt.Errorf("unable to read from reader: %w", err)
+	}
+
+	// tabular data is rendered as a table rather than with glamour
+	if tabular.IsTableFile(src.URL) {
+		if selecting() {
+			return "", "", errors.New("cannot render part of a table")
+		}
+		out, err := tabular.Render(b, src.URL, tabular.DefaultMaxCellWidth)
+		if err != nil {
+			return "", "", err //nolint:wrapcheck
+		}
+		return out, string(b), nil
+	}
+
+	// structured data is rendered as a tree, or highlighted as code when it
+	// doesn't parse
+	if datatree.IsDataFile(src.URL) {
+		if root, err := datatree.Parse(b, src.URL); err == nil {
+			if selecting() {
+				return "", "", errors.New("cannot render part of a data file")
+			}
+			return datatree.Render(root), string(b), nil
+		}
 	}
 
-	b = utils.RemoveFrontmatter(b)
+	// convert documents in other formats to markdown
+	conv := convert.ForFile(src.URL)
+	if conv == nil && src.contentType != "" {
+		conv = convert.ForContentType(src.contentType)
+	}
+	isNotebook := notebook.IsNotebook(src.URL)
+	isBook := epub.IsEPUB(src.URL)
+	isCode := conv == nil && !isNotebook && !isBook && !utils.IsMarkdownFile(src.URL)
+	switch {
+	case isBook:
+		book, err := epub.Parse(b)
+		if err != nil {
+			return "", "", err //nolint:wrapcheck
+		}
+		b = []byte(book.Markdown())
+	case conv != nil:
+		md, err := conv(b)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to convert document: %w", err)
+		}
+		b = []byte(md)
+	case isNotebook && selecting():
+		return "", "", errors.New("cannot render part of a notebook")
+	}
+
+	var metadata string
+	if !isNotebook {
+		if b, err = selectDocument(b, isCode); err != nil {
+			return "", "", err
+		}
+		if conv == nil && !isBook {
+			if showFrontMatter && !isCode && !selecting() {
+				metadata = frontMatterTable(b, opts)
+			}
+			b = utils.RemoveFrontmatter(b)
+		}
+	}
 
 	// render
 	var baseURL string
@@ -286,32 +680,115 @@ func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
 		baseURL = u.String() + "/"
 	}
 
-	isCode := !utils.IsMarkdownFile(src.URL)
-
 	// initialize glamour
-	r, err := glamour.NewTermRenderer(
-		glamour.WithColorProfile(lipgloss.ColorProfile()),
-		utils.GlamourStyle(style, isCode),
-		glamour.WithWordWrap(int(width)), //nolint:gosec
+	rendererOpts := []glamour.TermRendererOption{
+		glamour.WithColorProfile(opts.profile),
+		utils.GlamourStyle(opts.style, isCode),
+		glamour.WithWordWrap(int(opts.width)), //nolint:gosec
 		glamour.WithBaseURL(baseURL),
 		glamour.WithPreservedNewLines(),
-	)
+	}
+	if opts.chromaFormatter != "" {
+		rendererOpts = append(rendererOpts, glamour.WithChromaFormatter(opts.chromaFormatter))
+	}
+	r, err := glamour.NewTermRenderer(rendererOpts...)
 	if err != nil {
-		return fmt.Errorf("unable to create renderer: %w", err)
+		return "", "", fmt.Errorf("unable to create renderer: %w", err)
+	}
+
+	// notebooks are rendered cell by cell
+	if isNotebook {
+		nb, err := notebook.Parse(b)
+		if err != nil {
+			return "", "", err //nolint:wrapcheck
+		}
+		out, err := nb.Render(r)
+		if err != nil {
+			return "", "", err //nolint:wrapcheck
+		}
+		return out, string(b), nil
 	}
 
 	content := string(b)
 	ext := filepath.Ext(src.URL)
+	md := content
 	if isCode {
 		content = utils.WrapCodeBlock(string(b), ext)
+		md = content
+	} else {
+		// diagrams are drawn before glamour sees their code blocks
+		md = mermaid.RenderBlocks(content)
+	}
+
+	out, err := r.Render(md)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to render markdown: %w", err)
+	}
+	return metadata + out, content, nil
+}
+
+// documentMarkdown reads a source and returns it as a markdown document.
+// Documents in other formats are converted, and code and data files become
+// code blocks.
+func documentMarkdown(src *source) (string, error) {
+	md, converted, err := readMarkdown(src)
+	if err != nil || converted {
+		return md, err
 	}
+	return string(utils.RemoveFrontmatter([]byte(md))), nil
+}
 
-	out, err := r.Render(content)
+// readMarkdown reads a source as markdown, converting documents in other
+// formats. Markdown documents keep their front matter, converted reports
+// whether the document was converted.
+func readMarkdown(src *source) (string, bool, error) {
+	b, err := io.ReadAll(src.reader)
 	if err != nil {
-		return fmt.Errorf("unable to render markdown: %w", err)
+		return "", false, fmt.Errorf("unable to read from reader: %w", err)
 	}
 
-	// display
+	conv := convert.ForFile(src.URL)
+	if conv == nil && src.contentType != "" {
+		conv = convert.ForContentType(src.contentType)
+	}
+	switch {
+	case tabular.IsTableFile(src.URL):
+		t, err := tabular.Parse(b, src.URL)
+		if err != nil {
+			return "", false, err //nolint:wrapcheck
+		}
+		return t.Markdown(), true, nil
+	case epub.IsEPUB(src.URL):
+		book, err := epub.Parse(b)
+		if err != nil {
+			return "", false, err //nolint:wrapcheck
+		}
+		return book.Markdown(), true, nil
+	case notebook.IsNotebook(src.URL):
+		nb, err := notebook.Parse(b)
+		if err != nil {
+			return "", false, err //nolint:wrapcheck
+		}
+		return nb.Markdown(), true, nil
+	case conv != nil:
+		md, err := conv(b)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to convert document: %w", err)
+		}
+		return md, true, nil
+	case !utils.IsMarkdownFile(src.URL):
+		code := string(b)
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+		return utils.WrapCodeBlock(code, filepath.Ext(src.URL)), true, nil
+	}
+	return string(b), false, nil
+}
+
+// display writes rendered output to w, or shows it in a pager or the TUI.
+// opts describes what the TUI should open in that case.
+func display(cmd *cobra.Command, out string, opts tuiOptions, w io.Writer) error {
 	switch {
 	case pager || cmd.Flags().Changed("pager"):
 		pagerCmd := os.Getenv("PAGER")
@@ -328,32 +805,62 @@ func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
 		}
 		return nil
 	case tui || cmd.Flags().Changed("tui"):
-		path := ""
-		if !isURL(src.URL) {
-			path = src.URL
-		}
-		return runTUI(path, content)
+		return runTUI(opts)
 	default:
 		// If output is taller than terminal, open in TUI pager
 		fd := int(os.Stdout.Fd())
-		if term.IsTerminal(fd) {
+		if outputFile == "" && term.IsTerminal(fd) && !opts.empty() {
 			_, h, sizeErr := term.GetSize(fd)
 			if sizeErr == nil && strings.Count(out, "\n") > h {
-				path := ""
-				if !isURL(src.URL) {
-					path = src.URL
-				}
-				return runTUI(path, content)
+				return runTUI(opts)
 			}
 		}
-		if _, err = fmt.Fprint(w, out); err != nil {
+		if _, err := fmt.Fprint(w, out); err != nil {
 			return fmt.Errorf("unable to write to writer: %w", err)
 		}
 		return nil
 	}
 }
 
-func runTUI(path string, content string) error {
+// tuiOptions describes what the TUI should open.
+type tuiOptions struct {
+	// File or directory to open.
+	path string
+	// Document content to show instead of reading path.
+	content string
+	// When content was fetched, if it was served from the offline cache.
+	cachedAt time.Time
+	// Set of files the file listing is restricted to.
+	files []string
+}
+
+// empty reports whether there's nothing specific for the TUI to open.
+func (o tuiOptions) empty() bool {
+	return o.path == "" && o.content == "" && len(o.files) == 0
+}
+
+func tuiOptionsForSource(src *source, content string) tuiOptions {
+	opts := tuiOptions{content: content, cachedAt: src.cachedAt}
+	// the TUI reads local files from disk, which doesn't work for files
+	// from a git revision, and would show all of a file instead of the
+	// selected part.
+	if !isURL(src.URL) && src.rev == "" && !selecting() {
+		opts.path = src.URL
+	}
+	return opts
+}
+
+// getReadingPositionsPath returns the file where the TUI remembers how far
+// books have been read.
+func getReadingPositionsPath() (string, error) {
+	path, err := gap.NewScope(gap.User, "glow").DataPath("reading-positions.json")
+	if err != nil {
+		return "", fmt.Errorf("unable to get data dir: %w", err)
+	}
+	return path, nil
+}
+
+func runTUI(opts tuiOptions) error {
 	// Read environment to get debugging stuff
 	cfg, err := env.ParseAs[ui.Config]()
 	if err != nil {
@@ -365,15 +872,21 @@ func runTUI(path string, content string) error {
 		cfg.GlamourStyle = style
 	}
 
-	cfg.Path = path
+	cfg.Path = opts.path
+	cfg.CachedAt = opts.cachedAt
+	cfg.Files = opts.files
 	cfg.ShowAllFiles = showAllFiles
 	cfg.ShowLineNumbers = showLineNumbers
 	cfg.GlamourMaxWidth = width
 	cfg.EnableMouse = mouse
 	cfg.PreserveNewLines = preserveNewLines
+	cfg.ShowTables = viper.GetBool("showTables")
+	if positions, err := getReadingPositionsPath(); err == nil {
+		cfg.ReadingPositionsFile = positions
+	}
 
 	// Run Bubble Tea program
-	if _, err := ui.NewProgram(cfg, content).Run(); err != nil {
+	if _, err := ui.NewProgram(cfg, opts.content).Run(); err != nil {
 		return fmt.Errorf("unable to run tui program: %w", err)
 	}
 
@@ -386,7 +899,17 @@ func main() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	if err := rootCmd.Execute(); err != nil {
+
+	// Interrupting glow cancels any in-flight requests. Once cancelled we
+	// restore the default signal behavior, so a second interrupt still
+	// terminates glow if it's blocked elsewhere.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		_ = closer()
 		os.Exit(1)
 	}
@@ -415,7 +938,23 @@ func init() {
 	rootCmd.Flags().BoolVarP(&showLineNumbers, "line-numbers", "l", false, "show line numbers (TUI-mode only)")
 	rootCmd.Flags().BoolVarP(&preserveNewLines, "preserve-new-lines", "n", false, "preserve newlines in the output")
 	rootCmd.Flags().BoolVarP(&mouse, "mouse", "m", false, "enable mouse wheel (TUI-mode only)")
+	rootCmd.Flags().BoolVar(&fromClipboard, "clipboard", false, "render markdown from the system clipboard")
+	rootCmd.Flags().StringVar(&revision, "rev", "", "read files as of a git revision")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the output to a file")
+	rootCmd.Flags().StringVar(&colorMode, "color", colorAuto, "when to color the output (always, never or auto)")
+	rootCmd.Flags().StringVar(&colorProfile, "color-profile", "", "colors to use (truecolor, 256, 16 or ascii; default detected)")
+	rootCmd.Flags().StringVar(&selection.Section, "section", "", "render only the section under a heading, by its text or slug")
+	rootCmd.Flags().StringVar(&lineRange, "lines", "", "render only a range of lines, like 120:180")
+	rootCmd.Flags().IntVar(&selection.MaxLevel, "heading-level", 0, "leave out sections below this heading level")
+	rootCmd.Flags().StringVar(&tocFormat, "toc", "", "print the table of contents instead of the document (text or markdown)")
+	rootCmd.Flags().Lookup("toc").NoOptDefVal = tocText
+	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "print the structure of the document as JSON instead of rendering it")
+	rootCmd.Flags().BoolVar(&showFrontMatter, "front-matter", true, "show the front matter of documents as a table above them")
 	_ = rootCmd.Flags().MarkHidden("mouse")
+	rootCmd.PersistentFlags().DurationVar(&httpTimeout, "timeout", defaultHTTPTimeout, "timeout for fetching remote documents (0 disables it)")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", defaultUserAgent(), "user agent for fetching remote documents")
+	rootCmd.PersistentFlags().StringVar(&caFile, "ca-file", "", "PEM bundle of additional certificate authorities to trust")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "serve remote documents from the cache only")
 
 	// Config bindings
 	_ = viper.BindPFlag("pager", rootCmd.Flags().Lookup("pager"))
@@ -427,12 +966,31 @@ func init() {
 	_ = viper.BindPFlag("preserveNewLines", rootCmd.Flags().Lookup("preserve-new-lines"))
 	_ = viper.BindPFlag("showLineNumbers", rootCmd.Flags().Lookup("line-numbers"))
 	_ = viper.BindPFlag("all", rootCmd.Flags().Lookup("all"))
+	_ = viper.BindPFlag("output", rootCmd.Flags().Lookup("output"))
+	_ = viper.BindPFlag("color", rootCmd.Flags().Lookup("color"))
+	_ = viper.BindPFlag("colorProfile", rootCmd.Flags().Lookup("color-profile"))
+	_ = viper.BindPFlag("frontMatter", rootCmd.Flags().Lookup("front-matter"))
+	_ = viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	_ = viper.BindPFlag("userAgent", rootCmd.PersistentFlags().Lookup("user-agent"))
+	_ = viper.BindPFlag("caFile", rootCmd.PersistentFlags().Lookup("ca-file"))
+	_ = viper.BindPFlag("offline", rootCmd.PersistentFlags().Lookup("offline"))
 
 	viper.SetDefault("style", styles.AutoStyle)
 	viper.SetDefault("width", 0)
 	viper.SetDefault("all", true)
 
-	rootCmd.AddCommand(configCmd, manCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", exportHTML, "format to export to (html, pre or svg)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write to (default stdout)")
+	exportCmd.Flags().StringVarP(&exportStyle, "style", "s", "", "style name or JSON path (default the configured style)")
+	exportCmd.Flags().UintVarP(&exportWidth, "width", "w", 80, "word-wrap the terminal output at width (pre and svg)") //nolint:mnd
+	exportCmd.Flags().BoolVar(&exportNoTOC, "no-toc", false, "leave out the table of contents (html)")
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", defaultServeAddr, "address to listen on")
+	serveCmd.Flags().StringVarP(&serveStyle, "style", "s", "", "style name or JSON path (default the configured style)")
+
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "print the statistics as JSON")
+
+	rootCmd.AddCommand(configCmd, manCmd, diffCmd, exportCmd, checkCmd, serveCmd, statsCmd)
 }
 
 func tryLoadConfigFromDefaultPlaces() {
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// manPrefix selects a man page from the manpath, like "man:ls" or
+// "man:printf(3)".
+const manPrefix = "man:"
+
+// manSections is the order sections are searched in when a page is asked
+// for without one, as man(1) does.
+var manSections = []string{"1", "n", "l", "8", "3", "2", "5", "4", "9", "6", "7"}
+
+var defaultManPath = []string{
+	"/usr/local/share/man",
+	"/usr/share/man",
+	"/usr/local/man",
+	"/opt/homebrew/share/man",
+}
+
+var (
+	manNameWithSection = regexp.MustCompile(`^(.+)\(([1-9nl][a-z]*)\)$`)
+	manNameWithSuffix  = regexp.MustCompile(`^(.+)\.([1-9nl][a-z]*)$`)
+)
+
+// parseManName splits a man page name like "printf(3)" or "ls.1" into the
+// page's name and section.
+func parseManName(s string) (name, section string) {
+	if m := manNameWithSection.FindStringSubmatch(s); m != nil {
+		return m[1], m[2]
+	}
+	if m := manNameWithSuffix.FindStringSubmatch(s); m != nil {
+		return m[1], m[2]
+	}
+	return s, ""
+}
+
+// manPath returns the directories man pages are looked up in: those of
+// $MANPATH, where an empty entry stands for the default ones.
+func manPath() []string {
+	env := os.Getenv("MANPATH")
+	if env == "" {
+		return defaultManPath
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(env) {
+		if dir == "" {
+			dirs = append(dirs, defaultManPath...)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// findManPage returns the file of a man page in the given directories, like
+// /usr/share/man/man1/ls.1.gz.
+func findManPage(dirs []string, name, section string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid man page name %q", name)
+	}
+	sections := manSections
+	if section != "" {
+		sections = []string{section}
+	}
+	for _, sec := range sections {
+		for _, dir := range dirs {
+			// pages may have a suffix, like printf.3p, and be compressed
+			matches, _ := filepath.Glob(filepath.Join(dir, "man"+sec[:1], globEscape(name)+"."+sec+"*"))
+			for _, m := range matches {
+				base := strings.TrimSuffix(filepath.Base(m), ".gz")
+				if manNameWithSuffix.MatchString(base) && strings.TrimSuffix(base, filepath.Ext(base)) == name {
+					return m, nil
+				}
+			}
+		}
+	}
+	if section != "" {
+		return "", fmt.Errorf("no manual entry for %s in section %s", name, section)
+	}
+	return "", fmt.Errorf("no manual entry for %s", name)
+}
+
+var globMeta = regexp.MustCompile(`[*?[\\]`)
+
+func globEscape(s string) string {
+	return globMeta.ReplaceAllString(s, `\$0`)
+}
+
+// manSource opens a man page from the manpath. Pages that only include
+// another page, with ".so man1/other.1", are followed.
+func manSource(arg string) (*source, error) {
+	name, section := parseManName(arg)
+	path, err := findManPage(manPath(), name, section)
+	if err != nil {
+		return nil, err
+	}
+
+	for range 5 {
+		b, err := readManPage(path)
+		if err != nil {
+			return nil, err
+		}
+		target, ok := manPageInclude(b)
+		if !ok {
+			// pages of sections like n have no extension of their own
+			return &source{reader: io.NopCloser(bytes.NewReader(b)), URL: path, contentType: "text/troff"}, nil
+		}
+		// included pages are relative to the root of the man directory
+		root := filepath.Dir(filepath.Dir(path))
+		path = filepath.Join(root, filepath.FromSlash(target))
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			path += ".gz"
+		}
+	}
+	return nil, fmt.Errorf("too many includes in man page %s", arg)
+}
+
+// readManPage reads a man page, decompressing it if needed.
+func readManPage(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read man page: %w", err)
+	}
+	if !bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		return b, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress man page: %w", err)
+	}
+	b, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress man page: %w", err)
+	}
+	return b, nil
+}
+
+// manPageInclude returns the page a man page consists of, if it's only an
+// include like ".so man1/other.1".
+func manPageInclude(b []byte) (string, bool) {
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, `.\"`) || strings.HasPrefix(line, `'\"`):
+			continue
+		case strings.HasPrefix(line, ".so "):
+			return strings.TrimSpace(strings.TrimPrefix(line, ".so ")), true
+		}
+		return "", false
+	}
+	return "", false
+}
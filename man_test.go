@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/glow/v2/convert"
+)
+
+func TestParseManName(t *testing.T) {
+	tests := []struct {
+		in, name, section string
+	}{
+		{"ls", "ls", ""},
+		{"printf(3)", "printf", "3"},
+		{"ls.1", "ls", "1"},
+		{"open.3p", "open", "3p"},
+		{"python3.11", "python3.11", ""},
+	}
+	for _, tt := range tests {
+		name, section := parseManName(tt.in)
+		if name != tt.name || section != tt.section {
+			t.Errorf("parseManName(%q) = %q, %q, want %q, %q", tt.in, name, section, tt.name, tt.section)
+		}
+	}
+}
+
+func TestManSource(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(".TH TOOL 1\n.SH NAME\ntool\n"))
+	_ = zw.Close()
+	write("man1/tool.1.gz", gz.Bytes())
+	write("man3/tool.3", []byte(".TH TOOL 3\n"))
+	write("man1/alias.1", []byte(".\\\" an alias\n.so man3/tool.3\n"))
+	write("mann/after.n", []byte(".TH after n\n"))
+	t.Setenv("MANPATH", dir)
+
+	tests := []struct {
+		arg, path, content string
+	}{
+		{"tool", "man1/tool.1.gz", ".TH TOOL 1\n.SH NAME\ntool\n"},
+		{"tool(3)", "man3/tool.3", ".TH TOOL 3\n"},
+		{"tool.3", "man3/tool.3", ".TH TOOL 3\n"},
+		{"alias", "man3/tool.3", ".TH TOOL 3\n"},
+		{"after(n)", "mann/after.n", ".TH after n\n"},
+	}
+	for _, tt := range tests {
+		src, err := sourceFromArg(context.Background(), manPrefix+tt.arg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.arg, err)
+		}
+		b, _ := io.ReadAll(src.reader)
+		if src.URL != filepath.Join(dir, tt.path) || string(b) != tt.content {
+			t.Errorf("%s: got %s %q, want %s %q", tt.arg, src.URL, b, tt.path, tt.content)
+		}
+		// pages are read as roff whatever their extension
+		if convert.ForFile(src.URL) == nil && convert.ForContentType(src.contentType) == nil {
+			t.Errorf("%s: expected %s to be converted as a man page", tt.arg, src.URL)
+		}
+	}
+
+	for _, arg := range []string{"missing", "tool(5)", "../tool"} {
+		if _, err := manSource(arg); err == nil {
+			t.Errorf("%s: expected an error", arg)
+		}
+	}
+}
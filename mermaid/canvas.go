@@ -0,0 +1,190 @@
+package mermaid
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Directions a line leaves a cell in. Lines crossing or meeting in a cell
+// are drawn with the box-drawing character joining their directions.
+const (
+	up uint8 = 1 << iota
+	down
+	left
+	right
+)
+
+type lineStyle int
+
+const (
+	solid lineStyle = iota
+	rounded
+	thick
+	dotted
+	double
+)
+
+var lineGlyphs = map[lineStyle]map[uint8]rune{
+	solid: {
+		up: '│', down: '│', up | down: '│',
+		left: '─', right: '─', left | right: '─',
+		down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+		up | down | right: '├', up | down | left: '┤',
+		left | right | down: '┬', left | right | up: '┴',
+		up | down | left | right: '┼',
+	},
+	rounded: {
+		down | right: '╭', down | left: '╮', up | right: '╰', up | left: '╯',
+	},
+	thick: {
+		up: '┃', down: '┃', up | down: '┃',
+		left: '━', right: '━', left | right: '━',
+		down | right: '┏', down | left: '┓', up | right: '┗', up | left: '┛',
+		up | down | right: '┣', up | down | left: '┫',
+		left | right | down: '┳', left | right | up: '┻',
+		up | down | left | right: '╋',
+	},
+	dotted: {
+		up: '┆', down: '┆', up | down: '┆',
+		left: '┄', right: '┄', left | right: '┄',
+	},
+	// double lines only meet single ones
+	double: {
+		up | down: '║', left | right: '═',
+		down | right: '╔', down | left: '╗', up | right: '╚', up | left: '╝',
+		up | down | right: '╟', up | down | left: '╢',
+		left | right | down: '╤', left | right | up: '╧',
+		up | down | left | right: '╪',
+	},
+}
+
+type point struct{ r, c int }
+
+// canvas is a grid of characters that grows as it's drawn on. Lines are
+// joined where they meet, and text is drawn over them.
+type canvas struct {
+	lines  map[point]uint8
+	styles map[point]lineStyle
+	text   map[point]rune
+
+	minR, maxR, minC, maxC int
+	empty                  bool
+}
+
+func newCanvas() *canvas {
+	return &canvas{
+		lines:  map[point]uint8{},
+		styles: map[point]lineStyle{},
+		text:   map[point]rune{},
+		empty:  true,
+	}
+}
+
+func (c *canvas) grow(p point) {
+	if c.empty {
+		c.minR, c.maxR, c.minC, c.maxC = p.r, p.r, p.c, p.c
+		c.empty = false
+		return
+	}
+	c.minR, c.maxR = min(c.minR, p.r), max(c.maxR, p.r)
+	c.minC, c.maxC = min(c.minC, p.c), max(c.maxC, p.c)
+}
+
+// join adds directions to the line in a cell. The style of a cell is the one
+// of the first line drawn in it.
+func (c *canvas) join(p point, dirs uint8, style lineStyle) {
+	if _, ok := c.lines[p]; !ok {
+		c.styles[p] = style
+	}
+	c.lines[p] |= dirs
+	c.grow(p)
+}
+
+// hline draws a horizontal line on row r, from column c0 to c1.
+func (c *canvas) hline(r, c0, c1 int, style lineStyle) {
+	if c0 > c1 {
+		c0, c1 = c1, c0
+	}
+	for col := c0; col < c1; col++ {
+		c.join(point{r, col}, right, style)
+		c.join(point{r, col + 1}, left, style)
+	}
+}
+
+// vline draws a vertical line on column col, from row r0 to r1.
+func (c *canvas) vline(col, r0, r1 int, style lineStyle) {
+	if r0 > r1 {
+		r0, r1 = r1, r0
+	}
+	for r := r0; r < r1; r++ {
+		c.join(point{r, col}, down, style)
+		c.join(point{r + 1, col}, up, style)
+	}
+}
+
+// box draws a box with its top left corner at r, col, and the given lines
+// of text centered in it.
+func (c *canvas) box(r, col, w, h int, style lineStyle, lines []string) {
+	c.hline(r, col, col+w-1, style)
+	c.hline(r+h-1, col, col+w-1, style)
+	c.vline(col, r, r+h-1, style)
+	c.vline(col+w-1, r, r+h-1, style)
+	for i, l := range lines {
+		c.write(r+1+i, col+(w-runewidth.StringWidth(l))/2, l)
+	}
+}
+
+// set draws a character over whatever is in a cell.
+func (c *canvas) set(r, col int, ch rune) {
+	p := point{r, col}
+	c.text[p] = ch
+	c.grow(p)
+}
+
+// write draws text from a cell to the right. Wide characters take two cells.
+func (c *canvas) write(r, col int, s string) {
+	for _, ch := range s {
+		w := runewidth.RuneWidth(ch)
+		if w == 0 {
+			continue
+		}
+		c.set(r, col, ch)
+		if w == 2 { //nolint:mnd
+			// the second half of a wide character
+			c.set(r, col+1, 0)
+		}
+		col += w
+	}
+}
+
+func (c *canvas) String() string {
+	if c.empty {
+		return ""
+	}
+	rows := make([]string, 0, c.maxR-c.minR+1)
+	for r := c.minR; r <= c.maxR; r++ {
+		var b strings.Builder
+		for col := c.minC; col <= c.maxC; col++ {
+			p := point{r, col}
+			if ch, ok := c.text[p]; ok {
+				if ch != 0 {
+					b.WriteRune(ch)
+				}
+				continue
+			}
+			dirs, ok := c.lines[p]
+			if !ok {
+				b.WriteByte(' ')
+				continue
+			}
+			ch, ok := lineGlyphs[c.styles[p]][dirs]
+			if !ok {
+				ch = lineGlyphs[solid][dirs]
+			}
+			b.WriteRune(ch)
+		}
+		rows = append(rows, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(rows, "\n")
+}
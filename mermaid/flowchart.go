@@ -0,0 +1,996 @@
+package mermaid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+type shape int
+
+const (
+	rectShape shape = iota
+	roundShape
+	decisionShape
+)
+
+// shapes are the delimiters of the text of nodes, longest first. Shapes we
+// can't draw are drawn as rectangles.
+var shapes = []struct {
+	open, close string
+	shape       shape
+}{
+	{"(((", ")))", roundShape},
+	{"((", "))", roundShape},
+	{"([", "])", roundShape},
+	{"[(", ")]", roundShape},
+	{"[[", "]]", rectShape},
+	{"[/", "/]", rectShape},
+	{"[/", `\]`, rectShape},
+	{`[\`, `\]`, rectShape},
+	{`[\`, "/]", rectShape},
+	{"{{", "}}", decisionShape},
+	{"[", "]", rectShape},
+	{"(", ")", roundShape},
+	{"{", "}", decisionShape},
+	{">", "]", rectShape},
+}
+
+// head is the end of an edge.
+type head int
+
+const (
+	noHead head = iota
+	arrowHead
+	circleHead
+	crossHead
+)
+
+type fcNode struct {
+	id    string
+	lines []string
+	shape shape
+	order int
+	// dummy nodes carry edges across layers
+	dummy bool
+
+	rank  int
+	pos   int // position in its layer
+	minor int // center across the layers
+	// labelWidth is the width of the labels of edges ending at the node,
+	// drawn labelAt past its center
+	labelWidth, labelAt int
+	up, down            []*fcNode
+}
+
+type fcEdge struct {
+	from, to   *fcNode
+	label      string
+	style      lineStyle
+	head, tail head
+	invisible  bool
+}
+
+// segment is the part of an edge between two adjacent layers. Upper is in
+// the layer drawn first.
+type segment struct {
+	upper, lower         *fcNode
+	upperHead, lowerHead head
+	// ports are offsets from the centers of the nodes
+	upperPort, lowerPort int
+	label                string
+	style                lineStyle
+	invisible            bool
+}
+
+type flowchart struct {
+	dir   string
+	nodes []*fcNode
+	byID  map[string]*fcNode
+	edges []*fcEdge
+}
+
+func renderFlowchart(dir string, lines []string) (string, error) {
+	f := &flowchart{byID: map[string]*fcNode{}}
+	switch strings.ToUpper(dir) {
+	case "", "TB", "TD", "V":
+		f.dir = "TD"
+	case "BT", "^":
+		f.dir = "BT"
+	case "LR", ">":
+		f.dir = "LR"
+	case "RL", "<":
+		f.dir = "RL"
+	default:
+		return "", fmt.Errorf("unknown direction %q", dir)
+	}
+
+	for _, line := range lines {
+		for _, stmt := range splitStatements(line) {
+			if err := f.statement(stmt); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(f.nodes) == 0 {
+		return "", errors.New("empty flowchart")
+	}
+	return f.draw(), nil
+}
+
+// splitStatements splits a line at semicolons outside of quotes.
+func splitStatements(line string) []string {
+	var (
+		stmts  []string
+		quoted bool
+		start  int
+	)
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ';' && !quoted:
+			stmts = append(stmts, line[start:i])
+			start = i + 1
+		}
+	}
+	return append(stmts, line[start:])
+}
+
+var (
+	nodeID          = regexp.MustCompile(`^[\p{L}\p{N}_$]+(?:[.\-][\p{L}\p{N}_$]+)*`)
+	linkPattern     = regexp.MustCompile(`^(<|o|x)?(-{2,}|={2,}|-\.+-|~{3,})(>|o|x)?`)
+	linkOpen        = regexp.MustCompile(`^(<|o|x)?(--|==|-\.)\s`)
+	linkClose       = regexp.MustCompile(`\s(-{2,}|={2,}|\.-+)(>|o|x)?`)
+	classSuffix     = regexp.MustCompile(`^:::[\w-]+`)
+	ignoredWords    = []string{"classDef", "class", "style", "linkStyle", "click", "subgraph", "end", "direction", "accTitle:", "accDescr:", "accDescr"}
+	errNoNode       = errors.New("expected a node")
+	errUnterminated = errors.New("unterminated node text")
+)
+
+func (f *flowchart) statement(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	word, _, _ := strings.Cut(s, " ")
+	if slices.Contains(ignoredWords, word) {
+		return nil
+	}
+
+	p := &scanner{s: s}
+	prev, err := f.nodeGroup(p)
+	if err != nil {
+		return err
+	}
+	for {
+		p.skipSpace()
+		if p.done() {
+			return nil
+		}
+		e, err := p.link()
+		if err != nil {
+			return err
+		}
+		next, err := f.nodeGroup(p)
+		if err != nil {
+			return err
+		}
+		for _, a := range prev {
+			for _, b := range next {
+				edge := e
+				edge.from, edge.to = a, b
+				f.edges = append(f.edges, &edge)
+			}
+		}
+		prev = next
+	}
+}
+
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (p *scanner) rest() string { return p.s[p.pos:] }
+func (p *scanner) done() bool   { return p.pos >= len(p.s) }
+
+func (p *scanner) skipSpace() {
+	for !p.done() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// nodeGroup parses nodes joined with "&".
+func (f *flowchart) nodeGroup(p *scanner) ([]*fcNode, error) {
+	var nodes []*fcNode
+	for {
+		n, err := f.node(p)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		p.skipSpace()
+		if !strings.HasPrefix(p.rest(), "&") {
+			return nodes, nil
+		}
+		p.pos++
+	}
+}
+
+// node parses a node, with the text and shape it may be given.
+func (f *flowchart) node(p *scanner) (*fcNode, error) {
+	p.skipSpace()
+	id := nodeID.FindString(p.rest())
+	if id == "" {
+		return nil, errNoNode
+	}
+	p.pos += len(id)
+
+	n := f.byID[id]
+	if n == nil {
+		n = &fcNode{id: id, lines: []string{id}, order: len(f.nodes)}
+		f.byID[id] = n
+		f.nodes = append(f.nodes, n)
+	}
+
+	rest := p.rest()
+	for _, s := range shapes {
+		if !strings.HasPrefix(rest, s.open) {
+			continue
+		}
+		text := rest[len(s.open):]
+		var end int
+		if t := strings.TrimLeft(text, " "); strings.HasPrefix(t, `"`) {
+			q := strings.Index(t[1:], `"`)
+			if q < 0 {
+				return nil, errUnterminated
+			}
+			after := strings.TrimLeft(t[q+2:], " ")
+			if !strings.HasPrefix(after, s.close) {
+				continue
+			}
+			end = len(text) - len(after)
+			text = t[:q+2]
+		} else {
+			end = strings.Index(text, s.close)
+			if end < 0 {
+				continue
+			}
+			text = text[:end]
+		}
+		n.lines, n.shape = labelLines(text), s.shape
+		p.pos += len(s.open) + end + len(s.close)
+		break
+	}
+	p.pos += len(classSuffix.FindString(p.rest()))
+	return n, nil
+}
+
+// link parses the link between two nodes, with its label.
+func (p *scanner) link() (fcEdge, error) {
+	var e fcEdge
+	rest := p.rest()
+
+	// a label inside the link, like "a -- text --> b"
+	if m := linkOpen.FindStringSubmatch(rest); m != nil {
+		after := rest[len(m[0]):]
+		if c := linkClose.FindStringSubmatchIndex(after); c != nil {
+			e.label = label(after[:c[0]])
+			e.tail = headOf(m[1])
+			e.style = linkStyle(m[2])
+			if c[4] >= 0 {
+				e.head = headOf(after[c[4]:c[5]])
+			}
+			p.pos += len(m[0]) + c[1]
+			return e, nil
+		}
+	}
+
+	m := linkPattern.FindStringSubmatch(rest)
+	if m == nil {
+		return e, fmt.Errorf("expected a link: %q", rest)
+	}
+	n := len(m[0])
+	// circle and cross heads can't be followed by a node's name
+	if (m[3] == "o" || m[3] == "x") && nodeID.MatchString(rest[n:]) {
+		m[3] = ""
+		n--
+	}
+	e.tail, e.head = headOf(m[1]), headOf(m[3])
+	e.style = linkStyle(m[2])
+	e.invisible = strings.HasPrefix(m[2], "~")
+	p.pos += n
+
+	p.skipSpace()
+	if strings.HasPrefix(p.rest(), "|") {
+		end := strings.Index(p.rest()[1:], "|")
+		if end < 0 {
+			return e, errors.New("unterminated link text")
+		}
+		e.label = label(p.rest()[1 : end+1])
+		p.pos += end + 2 //nolint:mnd
+	}
+	return e, nil
+}
+
+func headOf(s string) head {
+	switch s {
+	case ">", "<":
+		return arrowHead
+	case "o":
+		return circleHead
+	case "x":
+		return crossHead
+	}
+	return noHead
+}
+
+func linkStyle(s string) lineStyle {
+	switch {
+	case strings.HasPrefix(s, "="):
+		return thick
+	case strings.Contains(s, "."):
+		return dotted
+	}
+	return solid
+}
+
+// Layout
+
+const (
+	// gaps between the nodes of a layer
+	columnGap = 3
+	rowGap    = 1
+)
+
+func (f *flowchart) vertical() bool { return f.dir == "TD" || f.dir == "BT" }
+
+// size returns the width and height of a node's box.
+func (n *fcNode) size() (int, int) {
+	if n.dummy {
+		return 1, 1
+	}
+	w := 0
+	for _, l := range n.lines {
+		w = max(w, runewidth.StringWidth(l))
+	}
+	return w + 4, len(n.lines) + 2 //nolint:mnd
+}
+
+// across returns the size of a node across the layers, and along its
+// layer.
+func (f *flowchart) across(n *fcNode) int {
+	w, h := n.size()
+	if f.vertical() {
+		return h
+	}
+	return w
+}
+
+func (f *flowchart) along(n *fcNode) int {
+	w, h := n.size()
+	if f.vertical() {
+		return w
+	}
+	return h
+}
+
+// extents returns how far a node reaches before and after its center in
+// its layer, with the labels drawn next to edges ending at it.
+func (f *flowchart) extents(n *fcNode) (int, int) {
+	size := f.along(n)
+	before, after := size/2, size-size/2-1 //nolint:mnd
+	if f.vertical() && n.labelWidth > 0 {
+		after = max(after, n.labelAt+n.labelWidth)
+	}
+	return before, after
+}
+
+// rank puts the nodes into layers, so edges go from one layer to a later
+// one. Edges closing cycles are reversed.
+func (f *flowchart) rank() {
+	reversed := map[*fcEdge]bool{}
+	out := map[*fcNode][]*fcEdge{}
+	for _, e := range f.edges {
+		out[e.from] = append(out[e.from], e)
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*fcNode]int{}
+	var visit func(n *fcNode)
+	visit = func(n *fcNode) {
+		state[n] = visiting
+		for _, e := range out[n] {
+			switch state[e.to] {
+			case visiting:
+				reversed[e] = true
+			case unvisited:
+				visit(e.to)
+			}
+		}
+		state[n] = visited
+	}
+	for _, n := range f.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	// longest paths, in topological order
+	succ := map[*fcNode][]*fcNode{}
+	indegree := map[*fcNode]int{}
+	for _, e := range f.edges {
+		a, b := e.from, e.to
+		if a == b {
+			continue
+		}
+		if reversed[e] {
+			a, b = b, a
+		}
+		succ[a] = append(succ[a], b)
+		indegree[b]++
+	}
+	var queue []*fcNode
+	for _, n := range f.nodes {
+		if indegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, s := range succ[n] {
+			s.rank = max(s.rank, n.rank+1)
+			if indegree[s]--; indegree[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+
+	if f.dir == "BT" || f.dir == "RL" {
+		last := 0
+		for _, n := range f.nodes {
+			last = max(last, n.rank)
+		}
+		for _, n := range f.nodes {
+			n.rank = last - n.rank
+		}
+	}
+}
+
+// layers splits the edges into segments between adjacent layers, adding
+// dummy nodes where they cross a layer.
+func (f *flowchart) layers() ([][]*fcNode, []*segment) {
+	last := 0
+	for _, n := range f.nodes {
+		last = max(last, n.rank)
+	}
+	layers := make([][]*fcNode, last+1)
+	for _, n := range f.nodes {
+		layers[n.rank] = append(layers[n.rank], n)
+	}
+
+	var segments []*segment
+	for _, e := range f.edges {
+		a, b, headA, headB := e.from, e.to, e.tail, e.head
+		if a == b {
+			continue
+		}
+		if a.rank > b.rank {
+			a, b, headA, headB = b, a, headB, headA
+		}
+		chain := []*fcNode{a}
+		for r := a.rank + 1; r < b.rank; r++ {
+			d := &fcNode{dummy: true, rank: r, order: len(f.nodes) + len(segments)}
+			layers[r] = append(layers[r], d)
+			chain = append(chain, d)
+		}
+		chain = append(chain, b)
+		for i := 1; i < len(chain); i++ {
+			s := &segment{upper: chain[i-1], lower: chain[i], style: e.style, invisible: e.invisible}
+			if i == 1 {
+				s.upperHead = headA
+			}
+			if i == len(chain)-1 {
+				s.lowerHead, s.label = headB, e.label
+			}
+			s.upper.down = append(s.upper.down, s.lower)
+			s.lower.up = append(s.lower.up, s.upper)
+			segments = append(segments, s)
+		}
+	}
+
+	f.ports(segments)
+
+	// labels ending at the same node are drawn together
+	labels := map[*fcNode][]string{}
+	for _, s := range segments {
+		if s.label != "" && !s.invisible {
+			labels[s.lower] = append(labels[s.lower], s.label)
+			s.lower.labelAt = max(s.lower.labelAt, s.lowerPort+2) //nolint:mnd
+		}
+	}
+	for n, l := range labels {
+		n.labelWidth = runewidth.StringWidth(strings.Join(l, ", "))
+	}
+	return layers, segments
+}
+
+// ports moves edges off the centers of nodes when a node has edges pointing
+// to it and edges leaving it on the same side, so the heads don't cover the
+// other lines. Edges going back against the flow are moved.
+func (f *flowchart) ports(segments []*segment) {
+	type side struct{ headed, plain []*segment }
+	far, near := map[*fcNode]*side{}, map[*fcNode]*side{}
+	add := func(sides map[*fcNode]*side, n *fcNode, s *segment, headed bool) {
+		if sides[n] == nil {
+			sides[n] = &side{}
+		}
+		if headed {
+			sides[n].headed = append(sides[n].headed, s)
+		} else {
+			sides[n].plain = append(sides[n].plain, s)
+		}
+	}
+	for _, s := range segments {
+		if s.invisible {
+			continue
+		}
+		add(far, s.upper, s, s.upperHead != noHead)
+		add(near, s.lower, s, s.lowerHead != noHead)
+	}
+
+	moved := map[*fcNode]bool{}
+	for n, sd := range far {
+		if len(sd.headed) > 0 && len(sd.plain) > 0 {
+			for _, s := range sd.headed {
+				s.upperPort = 1
+			}
+			moved[n] = true
+		}
+	}
+	for n, sd := range near {
+		if len(sd.headed) > 0 && len(sd.plain) > 0 {
+			for _, s := range sd.plain {
+				s.lowerPort = 1
+			}
+			moved[n] = true
+		}
+	}
+
+	// boxes across horizontal layers need a row for the port
+	if f.vertical() {
+		return
+	}
+	for n := range moved {
+		switch len(n.lines) {
+		case 1:
+			n.lines = []string{"", n.lines[0], ""}
+		case 2: //nolint:mnd
+			n.lines = append(n.lines, "")
+		}
+	}
+}
+
+// order orders the nodes of each layer to limit how often edges cross,
+// moving them to the mean position of their neighbors.
+func order(layers [][]*fcNode, segments []*segment) {
+	number := func() {
+		for _, l := range layers {
+			for i, n := range l {
+				n.pos = i
+			}
+		}
+	}
+	crossings := func() int {
+		count := 0
+		for i, a := range segments {
+			for _, b := range segments[i+1:] {
+				if a.upper.rank == b.upper.rank &&
+					(a.upper.pos-b.upper.pos)*(a.lower.pos-b.lower.pos) < 0 {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	sortLayer := func(l []*fcNode, neighbors func(n *fcNode) []*fcNode) {
+		bary := map[*fcNode]float64{}
+		for _, n := range l {
+			bary[n] = float64(n.pos)
+			if nb := neighbors(n); len(nb) > 0 {
+				sum := 0
+				for _, m := range nb {
+					sum += m.pos
+				}
+				bary[n] = float64(sum) / float64(len(nb))
+			}
+		}
+		slices.SortStableFunc(l, func(a, b *fcNode) int {
+			switch {
+			case bary[a] < bary[b]:
+				return -1
+			case bary[a] > bary[b]:
+				return 1
+			}
+			return 0
+		})
+		for i, n := range l {
+			n.pos = i
+		}
+	}
+
+	number()
+	best, bestOrder := crossings(), snapshot(layers)
+	for range 4 {
+		for r := 1; r < len(layers); r++ {
+			sortLayer(layers[r], func(n *fcNode) []*fcNode { return n.up })
+		}
+		for r := len(layers) - 2; r >= 0; r-- {
+			sortLayer(layers[r], func(n *fcNode) []*fcNode { return n.down })
+		}
+		if c := crossings(); c < best {
+			best, bestOrder = c, snapshot(layers)
+		}
+	}
+	for r := range layers {
+		layers[r] = bestOrder[r]
+	}
+	number()
+}
+
+func snapshot(layers [][]*fcNode) [][]*fcNode {
+	s := make([][]*fcNode, len(layers))
+	for i, l := range layers {
+		s[i] = slices.Clone(l)
+	}
+	return s
+}
+
+// place sets the centers of the nodes in each layer, close to the centers
+// of their neighbors.
+func (f *flowchart) place(layers [][]*fcNode) {
+	gap := columnGap
+	if !f.vertical() {
+		gap = rowGap
+	}
+	distances := func(l []*fcNode) []int {
+		d := make([]int, len(l))
+		for i := 1; i < len(l); i++ {
+			_, after := f.extents(l[i-1])
+			before, _ := f.extents(l[i])
+			d[i] = after + 1 + gap + before
+		}
+		return d
+	}
+	align := func(l []*fcNode, neighbors func(n *fcNode) []*fcNode) {
+		want := make([]float64, len(l))
+		for i, n := range l {
+			want[i] = float64(n.minor)
+			if nb := neighbors(n); len(nb) > 0 {
+				sum := 0
+				for _, m := range nb {
+					sum += m.minor
+				}
+				want[i] = float64(sum) / float64(len(nb))
+			}
+		}
+		for i, c := range spread(want, distances(l)) {
+			l[i].minor = c
+		}
+	}
+
+	for _, l := range layers {
+		c := 0
+		for i, d := range distances(l) {
+			c += d
+			l[i].minor = c
+		}
+	}
+	for range 4 {
+		for r := 1; r < len(layers); r++ {
+			align(layers[r], func(n *fcNode) []*fcNode { return n.up })
+		}
+		for r := len(layers) - 2; r >= 0; r-- {
+			align(layers[r], func(n *fcNode) []*fcNode { return n.down })
+		}
+	}
+	for r := 1; r < len(layers); r++ {
+		align(layers[r], func(n *fcNode) []*fcNode { return n.up })
+	}
+
+	// edges that are only a little off are straightened when there's room
+	for _, l := range layers {
+		d := distances(l)
+		for i, n := range l {
+			if len(n.up) != 1 {
+				continue
+			}
+			want := n.up[0].minor
+			if want == n.minor || abs(want-n.minor) > 2 ||
+				i > 0 && want-l[i-1].minor < d[i] ||
+				i < len(l)-1 && l[i+1].minor-want < d[i+1] {
+				continue
+			}
+			n.minor = want
+		}
+	}
+
+	first := math.MaxInt
+	for _, l := range layers {
+		for _, n := range l {
+			before, _ := f.extents(n)
+			first = min(first, n.minor-before)
+		}
+	}
+	for _, l := range layers {
+		for _, n := range l {
+			n.minor -= first
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// spread returns positions as close as possible to the wanted ones, in
+// order and at least the given distances apart. It's an isotonic
+// regression of the positions less their offsets.
+func spread(want []float64, distances []int) []int {
+	type block struct {
+		sum   float64
+		count int
+	}
+	mean := func(b block) float64 { return b.sum / float64(b.count) }
+
+	offsets := make([]int, len(want))
+	var blocks []block
+	for i, w := range want {
+		if i > 0 {
+			offsets[i] = offsets[i-1] + distances[i]
+		}
+		blocks = append(blocks, block{w - float64(offsets[i]), 1})
+		for len(blocks) > 1 && mean(blocks[len(blocks)-2]) > mean(blocks[len(blocks)-1]) {
+			b := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			blocks[len(blocks)-1].sum += b.sum
+			blocks[len(blocks)-1].count += b.count
+		}
+	}
+
+	pos := make([]int, 0, len(want))
+	for _, b := range blocks {
+		c := int(math.Round(mean(b)))
+		for range b.count {
+			pos = append(pos, c+offsets[len(pos)])
+		}
+	}
+	return pos
+}
+
+// Drawing
+
+func (f *flowchart) draw() string {
+	f.rank()
+	layers, segments := f.layers()
+	order(layers, segments)
+	f.place(layers)
+
+	// how thick each layer is, and the gaps between them
+	thickness := make([]int, len(layers))
+	for r, l := range layers {
+		for _, n := range l {
+			if !n.dummy {
+				thickness[r] = max(thickness[r], f.across(n))
+			}
+		}
+	}
+	// segments leaving the same port share a channel to turn in
+	type port struct {
+		n      *fcNode
+		offset int
+	}
+	channels := make([]map[port]int, len(layers))
+	labelSize := make([]int, len(layers))
+	for r := range layers {
+		channels[r] = map[port]int{}
+	}
+	for _, s := range segments {
+		r := s.upper.rank
+		if s.invisible {
+			continue
+		}
+		if s.upper.minor+s.upperPort != s.lower.minor+s.lowerPort {
+			channels[r][port{s.upper, s.upperPort}] = 0
+		}
+		if s.label != "" {
+			if f.vertical() {
+				labelSize[r] = 1
+			} else {
+				labelSize[r] = max(labelSize[r], s.lower.labelWidth+4) //nolint:mnd
+			}
+		}
+	}
+	// channels are taken in order across the layer, except that a segment
+	// leaving on a row another segment arrives on turns first, so the two
+	// don't run over each other
+	for r, l := range layers {
+		var ports []port
+		for _, n := range l {
+			for offset := range 2 {
+				if _, ok := channels[r][port{n, offset}]; ok {
+					ports = append(ports, port{n, offset})
+				}
+			}
+		}
+		before := map[port]map[port]bool{}
+		for _, s := range segments {
+			p := port{s.upper, s.upperPort}
+			if _, ok := channels[r][p]; !ok || s.upper.rank != r {
+				continue
+			}
+			for _, q := range ports {
+				if q != p && q.n.minor+q.offset == s.lower.minor+s.lowerPort {
+					if before[p] == nil {
+						before[p] = map[port]bool{}
+					}
+					before[p][q] = true
+				}
+			}
+		}
+		for i := 0; len(ports) > 0; i++ {
+			// the first port with nothing left to wait for, or the first
+			// one when they all wait on each other
+			next := 0
+			for j, p := range ports {
+				if !slices.ContainsFunc(ports, func(q port) bool { return before[p][q] }) {
+					next = j
+					break
+				}
+			}
+			channels[r][ports[next]] = i
+			ports = slices.Delete(ports, next, next+1)
+		}
+	}
+	start := make([]int, len(layers))
+	for r := 1; r < len(layers); r++ {
+		gap := 2 + len(channels[r-1]) + labelSize[r-1]
+		start[r] = start[r-1] + thickness[r-1] + gap
+	}
+
+	c := newCanvas()
+	// at returns the cell at a position across and along the layers
+	at := func(across, along int) (int, int) {
+		if f.vertical() {
+			return across, along
+		}
+		return along, across
+	}
+	lineAcross := func(along, from, to int, style lineStyle) {
+		if f.vertical() {
+			c.vline(along, from, to, style)
+		} else {
+			c.hline(along, from, to, style)
+		}
+	}
+	lineAlong := func(across, from, to int, style lineStyle) {
+		if f.vertical() {
+			c.hline(across, from, to, style)
+		} else {
+			c.vline(across, from, to, style)
+		}
+	}
+
+	for r, l := range layers {
+		for _, n := range l {
+			if n.dummy {
+				continue
+			}
+			w, h := n.size()
+			before, _ := f.extents(n)
+			row, col := at(start[r], n.minor-before)
+			style := solid
+			switch n.shape {
+			case roundShape:
+				style = rounded
+			case decisionShape:
+				style = double
+			}
+			c.box(row, col, w, h, style, n.lines)
+		}
+	}
+
+	drawn := map[*fcNode]bool{}
+	for _, s := range segments {
+		if s.invisible {
+			continue
+		}
+		r := s.upper.rank
+		u, v := s.upper.minor+s.upperPort, s.lower.minor+s.lowerPort
+		if d := s.upper; d.dummy && !drawn[d] {
+			lineAcross(u, start[r], start[r]+thickness[r]-1, s.style)
+			drawn[d] = true
+		}
+
+		from := start[r] + thickness[r] - 1
+		if !s.upper.dummy {
+			from = start[r] + f.across(s.upper) - 1
+		}
+		if s.upperHead != noHead {
+			from++
+			c.set(f.headAt(from, u, s.upperHead, true, at))
+		}
+		to := start[r+1]
+		if s.lowerHead != noHead {
+			to--
+			c.set(f.headAt(to, v, s.lowerHead, false, at))
+		}
+
+		if u == v {
+			lineAcross(u, from, to, s.style)
+		} else {
+			ch := start[r] + thickness[r] + 1 + channels[r][port{s.upper, s.upperPort}]
+			lineAcross(u, from, ch, s.style)
+			lineAlong(ch, u, v, s.style)
+			lineAcross(v, ch, to, s.style)
+		}
+	}
+
+	// labels are drawn over the lines, next to the node the edge ends at. In
+	// vertical charts, those of a node are drawn together.
+	labels := map[port][]string{}
+	for _, s := range segments {
+		if s.label != "" && !s.invisible {
+			p := port{s.lower, s.lowerPort}
+			if f.vertical() {
+				p.offset = 0
+			}
+			labels[p] = append(labels[p], s.label)
+		}
+	}
+	for p, l := range labels {
+		text := strings.Join(l, ", ")
+		r := p.n.rank - 1
+		across := start[r] + thickness[r] + 1 + len(channels[r])
+		if f.vertical() {
+			c.write(across, p.n.minor+p.n.labelAt, text)
+		} else {
+			c.write(p.n.minor+p.offset, across+1, " "+text+" ")
+		}
+	}
+	return c.String()
+}
+
+// headAt returns where the head of an edge is drawn and how. Heads point
+// to the node they're next to, which comes before or after them.
+func (f *flowchart) headAt(across, along int, h head, before bool, at func(int, int) (int, int)) (int, int, rune) {
+	r, c := at(across, along)
+	switch h {
+	case circleHead:
+		return r, c, 'o'
+	case crossHead:
+		return r, c, '×'
+	}
+	switch {
+	case f.vertical() && before:
+		return r, c, '▲'
+	case f.vertical():
+		return r, c, '▼'
+	case before:
+		return r, c, '◄'
+	}
+	return r, c, '►'
+}
@@ -0,0 +1,185 @@
+// Package mermaid draws Mermaid diagrams as text, with box-drawing
+// characters, so they can be read in a terminal. Flowcharts and sequence
+// diagrams are supported.
+package mermaid
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Language is the info string of fenced code blocks holding diagrams.
+const Language = "mermaid"
+
+var errUnsupported = errors.New("unsupported diagram")
+
+// Render draws a diagram. It reports false when the type of diagram isn't
+// supported, or the diagram can't be parsed.
+func Render(src string) (string, bool) {
+	lines := diagramLines(src)
+	if len(lines) == 0 {
+		return "", false
+	}
+
+	// statements may follow the header, like "graph LR; a --> b"
+	header, rest, _ := strings.Cut(lines[0], ";")
+	if rest = strings.TrimSpace(rest); rest != "" {
+		lines[0] = rest
+	} else {
+		lines = lines[1:]
+	}
+
+	var (
+		out string
+		err error
+	)
+	fields := strings.Fields(header)
+	switch fields[0] {
+	case "graph", "flowchart", "flowchart-elk":
+		var dir string
+		if len(fields) > 1 {
+			dir = fields[1]
+		}
+		out, err = renderFlowchart(dir, lines)
+	case "sequenceDiagram":
+		out, err = renderSequence(lines)
+	default:
+		err = errUnsupported
+	}
+	if err != nil {
+		return "", false
+	}
+	return out, true
+}
+
+// diagramLines returns the lines of a diagram, without front matter,
+// directives, comments and blank lines.
+func diagramLines(src string) []string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	// front matter holds the title and configuration
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+
+	var out []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%%") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+var (
+	fenceOpen = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^ \t`]*)")
+	brTag     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTag   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	faIcon    = regexp.MustCompile(`fa[bklrs]?:fa-[\w-]+\s*`)
+	entity    = regexp.MustCompile(`#(\w+);`)
+)
+
+// RenderBlocks replaces the mermaid code blocks of a markdown document with
+// code blocks holding their diagrams. Blocks with diagrams that can't be
+// drawn are left as they are.
+func RenderBlocks(md string) string {
+	if !strings.Contains(md, Language) {
+		return md
+	}
+
+	lines := strings.Split(md, "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		m := fenceOpen.FindStringSubmatch(lines[i])
+		if m == nil {
+			out = append(out, lines[i])
+			continue
+		}
+
+		// the closing fence, or the end of the document
+		start, end := i, len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if isClosingFence(lines[j], m[2]) {
+				end = j
+				break
+			}
+		}
+		i = end
+		block := lines[start:min(end+1, len(lines))]
+		if !strings.EqualFold(m[3], Language) {
+			out = append(out, block...)
+			continue
+		}
+
+		indent := m[1]
+		var src strings.Builder
+		for _, line := range lines[start+1 : end] {
+			src.WriteString(strings.TrimPrefix(line, indent) + "\n")
+		}
+		diagram, ok := Render(src.String())
+		if !ok {
+			out = append(out, block...)
+			continue
+		}
+		fence := "```"
+		for strings.Contains(diagram, fence) {
+			fence += "`"
+		}
+		out = append(out, indent+fence)
+		for _, line := range strings.Split(diagram, "\n") {
+			out = append(out, indent+line)
+		}
+		out = append(out, indent+fence)
+	}
+	return strings.Join(out, "\n")
+}
+
+// isClosingFence returns whether a line closes a code block opened with the
+// given fence.
+func isClosingFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) >= len(fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// labelLines turns the text of a node or a message into lines, without
+// markup.
+func labelLines(s string) []string {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	s = strings.Trim(s, "`")
+	s = brTag.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = faIcon.ReplaceAllString(s, "")
+	// mermaid escapes characters as #quot; or #35;
+	s = entity.ReplaceAllStringFunc(s, func(e string) string {
+		if e[1] >= '0' && e[1] <= '9' {
+			return "&" + e
+		}
+		return "&" + e[1:]
+	})
+	s = html.UnescapeString(s)
+
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		lines = append(lines, strings.Join(strings.Fields(l), " "))
+	}
+	return lines
+}
+
+// label returns the text of an edge or a message on one line.
+func label(s string) string {
+	return strings.Join(labelLines(s), " ")
+}
@@ -0,0 +1,165 @@
+package mermaid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tt := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "flowchart",
+			src:  "graph LR; A --> B",
+			want: []string{
+				"┌───┐  ┌───┐",
+				"│ A ├─►│ B │",
+				"└───┘  └───┘",
+			},
+		},
+		{
+			name: "flowchart with a cycle",
+			src:  "graph TD\nA[Start] --> B{OK?}\nB -->|yes| C[Done]\nB -->|no| A",
+			want: []string{
+				"┌───────┐",
+				"│ Start │",
+				"└───┬───┘",
+				"    │▲",
+				"    ││ no",
+				"    ▼│",
+				" ╔═══╧═╗",
+				" ║ OK? ║",
+				" ╚══╤══╝",
+				"    │",
+				"    │ yes",
+				"    ▼",
+				"┌──────┐",
+				"│ Done │",
+				"└──────┘",
+			},
+		},
+		{
+			name: "sequence diagram",
+			src:  "sequenceDiagram\n%% a comment\nAlice->>Bob: Hi\nBob-->>Alice: Hello",
+			want: []string{
+				"┌───────┐   ┌─────┐",
+				"│ Alice │   │ Bob │",
+				"└───┬───┘   └──┬──┘",
+				"    │          │",
+				"    │   Hi     │",
+				"    ├─────────►│",
+				"    │  Hello   │",
+				"    │◄┄┄┄┄┄┄┄┄┄┤",
+				"    │          │",
+				"┌───┴───┐   ┌──┴──┐",
+				"│ Alice │   │ Bob │",
+				"└───────┘   └─────┘",
+			},
+		},
+		{
+			name: "sequence diagram with notes and blocks",
+			src:  "sequenceDiagram\nautonumber\nA->>A: think\nNote right of A: ok\nloop every day\nA-xB: ping\nend",
+			want: []string{
+				"┌───┐          ┌───┐",
+				"│ A │          │ B │",
+				"└─┬─┘          └─┬─┘",
+				"  │              │",
+				"  ├──┐ 1. think  │",
+				"  │◄─┘           │",
+				"  │ ┌────┐       │",
+				"  │ │ ok │       │",
+				"  │ └────┘       │",
+				"┄┄ loop every day ┄┄",
+				"  │   2. ping    │",
+				"  ├─────────────×│",
+				"┄┄┼┄┄┄┄┄┄┄┄┄┄┄┄┄┄┼┄┄",
+				"  │              │",
+				"┌─┴─┐          ┌─┴─┐",
+				"│ A │          │ B │",
+				"└───┘          └───┘",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Render(tc.src)
+			if !ok {
+				t.Fatal("expected the diagram to be drawn")
+			}
+			if want := strings.Join(tc.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"pie title Pets\n\"Dogs\" : 386",
+		"sequenceDiagram\nend",
+		"graph TD\nA --> ",
+	} {
+		if _, ok := Render(src); ok {
+			t.Errorf("%q shouldn't be drawn", src)
+		}
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	md := strings.Join([]string{
+		"# Diagrams",
+		"",
+		"```mermaid",
+		"graph LR; A --> B",
+		"```",
+		"",
+		"```mermaid",
+		"pie",
+		"```",
+		"",
+		"```go",
+		"graph LR; A --> B",
+		"```",
+	}, "\n")
+	want := strings.Join([]string{
+		"# Diagrams",
+		"",
+		"```",
+		"┌───┐  ┌───┐",
+		"│ A ├─►│ B │",
+		"└───┘  └───┘",
+		"```",
+		"",
+		"```mermaid",
+		"pie",
+		"```",
+		"",
+		"```go",
+		"graph LR; A --> B",
+		"```",
+	}, "\n")
+	if got := RenderBlocks(md); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelLines(t *testing.T) {
+	tt := map[string][]string{
+		`"Quoted"`:             {"Quoted"},
+		"one<br>two":           {"one", "two"},
+		"<b>bold</b> text":     {"bold text"},
+		"fa:fa-car Car":        {"Car"},
+		"A #quot;B#quot; #35;": {`A "B" #`},
+	}
+	for in, want := range tt {
+		got := labelLines(in)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("labelLines(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package mermaid
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+type participant struct {
+	id, label string
+	center    int
+}
+
+type eventKind int
+
+const (
+	messageEvent eventKind = iota
+	noteEvent
+	blockEvent
+)
+
+type seqEvent struct {
+	kind     eventKind
+	from, to int
+	text     string
+	style    lineStyle
+	head     head
+	// where notes are drawn: "left of", "right of" or "over"
+	placement string
+}
+
+type sequence struct {
+	participants []*participant
+	byID         map[string]int
+	events       []seqEvent
+}
+
+var (
+	participantDecl = regexp.MustCompile(`^(?:create\s+)?(?:participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+	message         = regexp.MustCompile(`^(.+?)\s*(<<-->>|<<->>|--?>>|--?>|--?x|--?\))\s*[+-]?\s*(.+?)\s*(?::(.*))?$`)
+	note            = regexp.MustCompile(`(?i)^note\s+(left of|right of|over)\s+([^:]+?)\s*:(.*)$`)
+	blockStart      = []string{"loop", "alt", "opt", "par", "critical", "break", "rect"}
+	blockBranch     = []string{"else", "and", "option"}
+	seqIgnored      = []string{"activate", "deactivate", "destroy", "title", "link", "links", "properties", "details", "accTitle:", "accDescr:", "accDescr"}
+)
+
+func renderSequence(lines []string) (string, error) {
+	s := &sequence{byID: map[string]int{}}
+	autonumber := false
+	// blocks and boxes of participants, which both close with "end"
+	var open []bool
+	for _, line := range lines {
+		word, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch {
+		case word == "autonumber":
+			autonumber = true
+		case slices.Contains(seqIgnored, word):
+		case word == "box":
+			open = append(open, false)
+		case slices.Contains(blockStart, word):
+			open = append(open, true)
+			s.events = append(s.events, seqEvent{kind: blockEvent, text: strings.TrimSpace(word + " " + rest)})
+		case slices.Contains(blockBranch, word):
+			s.events = append(s.events, seqEvent{kind: blockEvent, text: strings.TrimSpace(word + " " + rest)})
+		case word == "end":
+			if len(open) == 0 {
+				return "", errors.New("unexpected end")
+			}
+			if open[len(open)-1] {
+				s.events = append(s.events, seqEvent{kind: blockEvent})
+			}
+			open = open[:len(open)-1]
+		default:
+			if err := s.statement(line); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(s.participants) == 0 {
+		return "", errors.New("empty sequence diagram")
+	}
+
+	if autonumber {
+		n := 0
+		for i, e := range s.events {
+			if e.kind == messageEvent {
+				n++
+				s.events[i].text = strings.TrimSpace(strconv.Itoa(n) + ". " + e.text)
+			}
+		}
+	}
+	return s.draw(), nil
+}
+
+func (s *sequence) statement(line string) error {
+	if m := participantDecl.FindStringSubmatch(line); m != nil {
+		i := s.participant(m[1])
+		if m[2] != "" {
+			s.participants[i].label = label(m[2])
+		}
+		return nil
+	}
+	if m := note.FindStringSubmatch(line); m != nil {
+		e := seqEvent{kind: noteEvent, placement: strings.ToLower(m[1]), text: m[3]}
+		names := strings.Split(m[2], ",")
+		e.from = s.participant(names[0])
+		e.to = s.participant(names[len(names)-1])
+		s.events = append(s.events, e)
+		return nil
+	}
+	if m := message.FindStringSubmatch(line); m != nil {
+		e := seqEvent{kind: messageEvent, text: label(m[4])}
+		e.from, e.to = s.participant(m[1]), s.participant(m[3])
+		arrow := strings.Trim(m[2], "<")
+		if strings.HasPrefix(arrow, "--") {
+			e.style = dotted
+		}
+		switch {
+		case strings.HasSuffix(arrow, "x"):
+			e.head = crossHead
+		case strings.HasSuffix(arrow, ">>"), strings.HasSuffix(arrow, ")"):
+			e.head = arrowHead
+		}
+		s.events = append(s.events, e)
+		return nil
+	}
+	return errors.New("unknown statement: " + line)
+}
+
+// participant returns the index of a participant, adding it the first time
+// it's mentioned.
+func (s *sequence) participant(id string) int {
+	id = strings.TrimSpace(id)
+	if i, ok := s.byID[id]; ok {
+		return i
+	}
+	s.byID[id] = len(s.participants)
+	s.participants = append(s.participants, &participant{id: id, label: label(id)})
+	return len(s.participants) - 1
+}
+
+func (p *participant) width() int { return runewidth.StringWidth(p.label) + 4 } //nolint:mnd
+
+func noteSize(text string) ([]string, int, int) {
+	lines := labelLines(text)
+	w := 0
+	for _, l := range lines {
+		w = max(w, runewidth.StringWidth(l))
+	}
+	return lines, w + 4, len(lines) + 2 //nolint:mnd
+}
+
+// place sets the centers of the participants, far enough apart for the
+// messages and notes between them.
+func (s *sequence) place() {
+	ps := s.participants
+	gaps := make([]int, len(ps))
+	for i := 1; i < len(ps); i++ {
+		a, b := ps[i-1].width(), ps[i].width()
+		gaps[i] = a - a/2 - 1 + 1 + columnGap + b/2
+	}
+
+	type need struct{ from, to, distance int }
+	var needs []need
+	for _, e := range s.events {
+		tw := runewidth.StringWidth(e.text)
+		switch {
+		case e.kind == messageEvent && e.from != e.to:
+			needs = append(needs, need{min(e.from, e.to), max(e.from, e.to), tw + 4})
+		case e.kind == messageEvent && e.from < len(ps)-1:
+			needs = append(needs, need{e.from, e.from + 1, tw + 7})
+		case e.kind == noteEvent && e.placement == "right of" && e.from < len(ps)-1:
+			_, w, _ := noteSize(e.text)
+			needs = append(needs, need{e.from, e.from + 1, w + 3})
+		case e.kind == noteEvent && e.placement == "left of" && e.from > 0:
+			_, w, _ := noteSize(e.text)
+			needs = append(needs, need{e.from - 1, e.from, w + 3})
+		}
+	}
+	// shorter spans first, widening the last gap of each
+	slices.SortStableFunc(needs, func(a, b need) int { return (a.to - a.from) - (b.to - b.from) })
+	for _, n := range needs {
+		d := 0
+		for i := n.from + 1; i <= n.to; i++ {
+			d += gaps[i]
+		}
+		if d < n.distance {
+			gaps[n.to] += n.distance - d
+		}
+	}
+
+	c := ps[0].width() / 2 //nolint:mnd
+	for i, p := range ps {
+		c += gaps[i]
+		p.center = c
+	}
+}
+
+func (s *sequence) draw() string {
+	s.place()
+	c := newCanvas()
+	ps := s.participants
+
+	const boxHeight = 3
+	boxes := func(row int) {
+		for _, p := range ps {
+			w := p.width()
+			c.box(row, p.center-w/2, w, boxHeight, solid, []string{p.label})
+		}
+	}
+	first, last := ps[0], ps[len(ps)-1]
+	left := first.center - first.width()/2
+	right := last.center + last.width() - last.width()/2 - 1
+
+	// rows of lifelines hidden behind notes
+	hidden := map[int][]int{}
+	row := boxHeight + 1
+	for _, e := range s.events {
+		switch e.kind {
+		case blockEvent:
+			c.hline(row, left, right, dotted)
+			if e.text != "" {
+				c.write(row, left+2, " "+e.text+" ") //nolint:mnd
+			}
+			row++
+
+		case noteEvent:
+			lines, w, h := noteSize(e.text)
+			a, b := ps[e.from].center, ps[e.to].center
+			var col int
+			switch e.placement {
+			case "right of":
+				col = a + 2 //nolint:mnd
+			case "left of":
+				col = a - 2 - w + 1 //nolint:mnd
+			default:
+				a, b = min(a, b), max(a, b)
+				w = max(w, b-a+5) //nolint:mnd
+				col = (a+b)/2 - w/2
+			}
+			c.box(row, col, w, h, solid, lines)
+			for i, p := range ps {
+				if p.center >= col && p.center < col+w {
+					for r := row; r < row+h; r++ {
+						hidden[i] = append(hidden[i], r)
+					}
+				}
+			}
+			row += h
+
+		case messageEvent:
+			a, b := ps[e.from].center, ps[e.to].center
+			if a == b {
+				// a message to oneself loops back
+				c.hline(row, a, a+3, e.style)
+				c.vline(a+3, row, row+1, e.style)
+				c.hline(row+1, a+1, a+3, e.style)
+				c.set(row+1, a+1, headGlyph(e.head, '◄'))
+				if e.head == noHead {
+					c.hline(row+1, a, a+1, e.style)
+				}
+				c.write(row, a+5, e.text) //nolint:mnd
+				row += 2
+				continue
+			}
+			if e.text != "" {
+				c.write(row, (a+b)/2-runewidth.StringWidth(e.text)/2, e.text)
+				row++
+			}
+			dir, glyph := 1, '►'
+			if b < a {
+				dir, glyph = -1, '◄'
+			}
+			end := b
+			if e.head != noHead {
+				end = b - dir
+				c.set(row, end, headGlyph(e.head, glyph))
+			}
+			c.hline(row, a, end, e.style)
+			row++
+		}
+	}
+	row++
+
+	boxes(0)
+	boxes(row)
+	for i, p := range ps {
+		// lifelines run between the boxes, behind notes
+		top := boxHeight - 1
+		for r := top + 1; r < row; r++ {
+			if !slices.Contains(hidden[i], r) {
+				continue
+			}
+			switch {
+			case top < r-1:
+				c.vline(p.center, top, r-1, solid)
+			case top == r-1:
+				c.join(point{top, p.center}, up|down, solid)
+			}
+			top = r + 1
+		}
+		if top < row {
+			c.vline(p.center, top, row, solid)
+		}
+	}
+	return c.String()
+}
+
+func headGlyph(h head, arrow rune) rune {
+	if h == crossHead {
+		return '×'
+	}
+	return arrow
+}
@@ -0,0 +1,269 @@
+// Package notebook parses and renders Jupyter notebooks.
+package notebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glow/v2/mermaid"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Extension is the file extension of Jupyter notebooks.
+const Extension = ".ipynb"
+
+// defaultLanguage is the language of code cells when the notebook doesn't
+// specify its kernel language.
+const defaultLanguage = "python"
+
+// gutter is prefixed to every line of an output.
+const gutter = "  │ "
+
+var (
+	outputStyle = lipgloss.NewStyle().Faint(true)
+	errorStyle  = lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("#ED567A"))
+)
+
+// Cell types.
+const (
+	CellMarkdown = "markdown"
+	CellCode     = "code"
+	CellRaw      = "raw"
+)
+
+// Notebook is a parsed Jupyter notebook.
+type Notebook struct {
+	// Language is the programming language of the notebook's kernel.
+	Language string
+	Cells    []Cell
+}
+
+// Cell is a notebook cell.
+type Cell struct {
+	Type    string
+	Source  string
+	Outputs []Output
+}
+
+// Output is the output of a code cell. It's either text, or a placeholder
+// for rich output we can't show in a terminal, such as images.
+type Output struct {
+	Text string
+	// MediaType is the type of rich output, like "image/png".
+	MediaType string
+	// Error is set for tracebacks.
+	Error bool
+}
+
+// multiline is a notebook string, which is either a string or a list of
+// lines.
+type multiline string
+
+func (s *multiline) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = multiline(str)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err != nil {
+		return err //nolint:wrapcheck
+	}
+	*s = multiline(strings.Join(lines, ""))
+	return nil
+}
+
+type rawNotebook struct {
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []struct {
+		CellType string      `json:"cell_type"`
+		Source   multiline   `json:"source"`
+		Outputs  []rawOutput `json:"outputs"`
+	} `json:"cells"`
+}
+
+type rawOutput struct {
+	OutputType string               `json:"output_type"`
+	Text       multiline            `json:"text"`
+	Data       map[string]multiline `json:"data"`
+	Ename      string               `json:"ename"`
+	Evalue     string               `json:"evalue"`
+	Traceback  []string             `json:"traceback"`
+}
+
+// IsNotebook returns whether a file is a Jupyter notebook, based on its
+// extension.
+func IsNotebook(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), Extension)
+}
+
+// Parse parses a notebook in the Jupyter notebook format.
+func Parse(b []byte) (*Notebook, error) {
+	var raw rawNotebook
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("unable to parse notebook: %w", err)
+	}
+
+	nb := &Notebook{Language: raw.Metadata.Kernelspec.Language}
+	if nb.Language == "" {
+		nb.Language = raw.Metadata.LanguageInfo.Name
+	}
+	if nb.Language == "" {
+		nb.Language = defaultLanguage
+	}
+
+	for _, c := range raw.Cells {
+		cell := Cell{Type: c.CellType, Source: string(c.Source)}
+		for _, o := range c.Outputs {
+			cell.Outputs = append(cell.Outputs, parseOutput(o))
+		}
+		nb.Cells = append(nb.Cells, cell)
+	}
+	return nb, nil
+}
+
+func parseOutput(o rawOutput) Output {
+	switch o.OutputType {
+	case "stream":
+		return Output{Text: string(o.Text)}
+
+	case "error":
+		text := strings.Join(o.Traceback, "\n")
+		if text == "" {
+			text = o.Ename + ": " + o.Evalue
+		}
+		// tracebacks are colored with escape sequences
+		return Output{Text: ansi.Strip(text), Error: true}
+	}
+
+	// execute_result and display_data have a representation per media
+	// type. Images are preferred as they're usually the point of the
+	// output, e.g. for plots, which also have a useless text representation.
+	types := make([]string, 0, len(o.Data))
+	for t := range o.Data {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		if strings.HasPrefix(t, "image/") {
+			return Output{MediaType: t}
+		}
+	}
+	if text, ok := o.Data["text/plain"]; ok {
+		return Output{Text: string(text)}
+	}
+	if len(types) > 0 {
+		return Output{MediaType: types[0]}
+	}
+	return Output{}
+}
+
+// Render renders the notebook. Markdown cells and code cells are rendered
+// with r, outputs are shown as dimmed blocks below their cell.
+func (nb *Notebook) Render(r *glamour.TermRenderer) (string, error) {
+	var b strings.Builder
+	for _, c := range nb.Cells {
+		var md string
+		switch c.Type {
+		case CellMarkdown:
+			md = mermaid.RenderBlocks(c.Source)
+		case CellCode:
+			md = fence(c.Source, nb.Language)
+		default:
+			md = fence(c.Source, "")
+		}
+
+		if strings.TrimSpace(c.Source) != "" {
+			out, err := r.Render(md)
+			if err != nil {
+				return "", fmt.Errorf("unable to render cell: %w", err)
+			}
+			b.WriteString(strings.TrimRight(out, "\n") + "\n")
+		}
+
+		for _, o := range c.Outputs {
+			b.WriteString(o.render())
+		}
+	}
+	return b.String(), nil
+}
+
+// Markdown returns the notebook as a markdown document. Code cells and
+// their text outputs become code blocks.
+func (nb *Notebook) Markdown() string {
+	var b strings.Builder
+	for _, c := range nb.Cells {
+		if strings.TrimSpace(c.Source) != "" {
+			switch c.Type {
+			case CellMarkdown:
+				b.WriteString(strings.TrimRight(c.Source, "\n") + "\n\n")
+			case CellCode:
+				b.WriteString(fence(c.Source, nb.Language) + "\n")
+			default:
+				b.WriteString(fence(c.Source, "") + "\n")
+			}
+		}
+
+		for _, o := range c.Outputs {
+			switch {
+			case o.MediaType != "":
+				b.WriteString("*[" + o.MediaType + " output]*\n\n")
+			case strings.TrimSpace(o.Text) != "":
+				b.WriteString(fence(o.Text, "") + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func (o Output) render() string {
+	if o.MediaType != "" {
+		return "\n" + outputStyle.Render(gutter+"["+o.MediaType+" output]") + "\n"
+	}
+
+	text := strings.TrimRight(strings.ReplaceAll(o.Text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return ""
+	}
+	style := outputStyle
+	if o.Error {
+		style = errorStyle
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, line := range strings.Split(text, "\n") {
+		// progress bars redraw their line with carriage returns, only
+		// show what's left in the end
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		b.WriteString(style.Render(gutter+expandTabs(line)) + "\n")
+	}
+	return b.String()
+}
+
+// fence wraps code in a fenced code block.
+func fence(code, language string) string {
+	f := "```"
+	for strings.Contains(code, f) {
+		f += "`"
+	}
+	return f + language + "\n" + strings.TrimRight(code, "\n") + "\n" + f + "\n"
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
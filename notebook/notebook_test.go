@@ -0,0 +1,157 @@
+package notebook
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParse(t *testing.T) {
+	b, err := os.ReadFile("testdata/example.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb, err := Parse(b)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if nb.Language != "python" {
+		t.Errorf("Language = %q, want python", nb.Language)
+	}
+	want := []Cell{
+		{Type: CellMarkdown, Source: "# Analysis\n\nSome *notes* about the data."},
+		{
+			Type:    CellCode,
+			Source:  "import pandas as pd\nprint('loading')",
+			Outputs: []Output{{Text: "loading\ndone\n"}},
+		},
+		{
+			Type:    CellCode,
+			Source:  "plot()",
+			Outputs: []Output{{MediaType: "image/png"}, {Text: "42"}},
+		},
+		{
+			Type:   CellCode,
+			Source: "1/0",
+			Outputs: []Output{{
+				Text:  "ZeroDivisionError Traceback\nZeroDivisionError: division by zero",
+				Error: true,
+			}},
+		},
+	}
+	if !reflect.DeepEqual(nb.Cells, want) {
+		t.Errorf("Cells =\n%#v\nwant\n%#v", nb.Cells, want)
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"kernelspec", `{"metadata": {"kernelspec": {"language": "julia"}}, "cells": []}`, "julia"},
+		{"language info", `{"metadata": {"language_info": {"name": "R"}}, "cells": []}`, "R"},
+		{"default", `{"cells": []}`, "python"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nb, err := Parse([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if nb.Language != tt.want {
+				t.Errorf("Language = %q, want %q", nb.Language, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("# not a notebook")); err == nil {
+		t.Error("expected error for invalid notebook")
+	}
+}
+
+func TestRender(t *testing.T) {
+	b, err := os.ReadFile("testdata/example.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(80))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := nb.Render(r)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	out = ansi.Strip(out)
+	for _, want := range []string{
+		"# Analysis",
+		"import pandas as pd",
+		gutter + "loading",
+		gutter + "done",
+		gutter + "[image/png output]",
+		gutter + "42",
+		gutter + "ZeroDivisionError: division by zero",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Figure size") {
+		t.Error("Render() should show image outputs as placeholders")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	nb := &Notebook{
+		Language: "python",
+		Cells: []Cell{
+			{Type: CellMarkdown, Source: "# Analysis\n"},
+			{Type: CellCode, Source: "plot()", Outputs: []Output{{MediaType: "image/png"}, {Text: "42\n"}}},
+		},
+	}
+
+	want := strings.Join([]string{
+		"# Analysis",
+		"",
+		"```python",
+		"plot()",
+		"```",
+		"",
+		"*[image/png output]*",
+		"",
+		"```",
+		"42",
+		"```",
+		"",
+		"",
+	}, "\n")
+	if got := nb.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIsNotebook(t *testing.T) {
+	for name, want := range map[string]bool{
+		"analysis.ipynb": true,
+		"ANALYSIS.IPYNB": true,
+		"notes.md":       false,
+	} {
+		if got := IsNotebook(name); got != want {
+			t.Errorf("IsNotebook(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
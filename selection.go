@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/structure"
+)
+
+// selection is the part of documents --section, --lines and --heading-level
+// select.
+var selection structure.Selection
+
+// selecting reports whether only part of documents is rendered.
+func selecting() bool {
+	return selection != structure.Selection{}
+}
+
+// parseLineRange parses a range of lines like 120:180. Either bound can be
+// left out, and a single line can be given on its own.
+func parseLineRange(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	from, to, isRange := strings.Cut(s, ":")
+	if !isRange {
+		to = from
+	}
+
+	var start, end int
+	var err error
+	if from != "" {
+		if start, err = strconv.Atoi(from); err != nil || start < 1 {
+			return 0, 0, fmt.Errorf("invalid line range %q: lines are numbered from 1", s)
+		}
+	}
+	if to != "" {
+		if end, err = strconv.Atoi(to); err != nil || end < 1 {
+			return 0, 0, fmt.Errorf("invalid line range %q: lines are numbered from 1", s)
+		}
+	}
+	if end > 0 && start > end {
+		return 0, 0, fmt.Errorf("invalid line range %q: it ends before it starts", s)
+	}
+	return start, end, nil
+}
+
+// selectDocument returns the selected part of a document. Code files only
+// have lines to select.
+func selectDocument(b []byte, isCode bool) ([]byte, error) {
+	if !selecting() {
+		return b, nil
+	}
+	if isCode {
+		if selection.Section != "" || selection.MaxLevel > 0 {
+			return nil, errors.New("--section and --heading-level only apply to markdown documents")
+		}
+		return selectLines(b, selection.Start, selection.End), nil
+	}
+	return structure.Select(b, selection) //nolint:wrapcheck
+}
+
+// selectLines returns the lines from start to end, where 0 leaves a bound
+// open.
+func selectLines(b []byte, start, end int) []byte {
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start == 0 {
+		start = 1
+	}
+	if start > end {
+		return nil
+	}
+	return []byte(strings.Join(lines[start-1:end], ""))
+}
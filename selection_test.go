@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/glow/v2/structure"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end int
+		err        bool
+	}{
+		{in: ""},
+		{in: "120:180", start: 120, end: 180},
+		{in: "120:", start: 120},
+		{in: ":180", end: 180},
+		{in: "7", start: 7, end: 7},
+		{in: "0:3", err: true},
+		{in: "5:2", err: true},
+		{in: "a:b", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			start, end, err := parseLineRange(tt.in)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("expected %d:%d, got %d:%d", tt.start, tt.end, start, end)
+			}
+		})
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	src := []byte("one\ntwo\nthree\nfour\n")
+	tests := []struct {
+		start, end int
+		want       string
+	}{
+		{0, 0, "one\ntwo\nthree\nfour\n"},
+		{2, 3, "two\nthree\n"},
+		{3, 0, "three\nfour\n"},
+		{3, 10, "three\nfour\n"},
+		{9, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := string(selectLines(src, tt.start, tt.end)); got != tt.want {
+			t.Errorf("selectLines(%d, %d): expected %q, got %q", tt.start, tt.end, tt.want, got)
+		}
+	}
+}
+
+func TestTUIOptionsForSelection(t *testing.T) {
+	saved := selection
+	t.Cleanup(func() { selection = saved })
+
+	src := &source{URL: "README.md"}
+	selection = structure.Selection{}
+	if opts := tuiOptionsForSource(src, "# Glow"); opts.path != "README.md" {
+		t.Errorf("expected the TUI to open README.md, got %+v", opts)
+	}
+
+	selection = structure.Selection{Section: "installation"}
+	opts := tuiOptionsForSource(src, "## Installation")
+	if opts.path != "" || opts.content != "## Installation" {
+		t.Errorf("expected the TUI to show only the selection, got %+v", opts)
+	}
+}
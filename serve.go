@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	glamouransi "github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glow/v2/export"
+	"github.com/charmbracelet/glow/v2/mermaid"
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// eventsPath is where pages listen for changes to their document.
+const eventsPath = "/_glow/events"
+
+// reloadScript reloads a page when its document changes.
+const reloadScript = `new EventSource("` + eventsPath + `?path=" + encodeURIComponent(decodeURIComponent(location.pathname)))
+	.addEventListener("reload", () => location.reload());`
+
+// previewServer serves the documents in a directory as HTML pages, which
+// reload when their document changes.
+type previewServer struct {
+	root  string
+	style glamouransi.StyleConfig
+
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	// clients are the pages waiting for changes, by the file they show.
+	clients map[chan struct{}]string
+}
+
+func newPreviewServer(root string, style glamouransi.StyleConfig) (*previewServer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch files: %w", err)
+	}
+	s := &previewServer{
+		root:    root,
+		style:   style,
+		watcher: watcher,
+		clients: map[chan struct{}]string{},
+	}
+	go s.watch()
+	return s, nil
+}
+
+// Close stops watching files.
+func (s *previewServer) Close() error {
+	return s.watcher.Close() //nolint:wrapcheck
+}
+
+func (s *previewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == eventsPath {
+		s.serveEvents(w, r)
+		return
+	}
+
+	// hidden files, like .env or .git, aren't served
+	for _, part := range strings.Split(r.URL.Path, "/") {
+		if strings.HasPrefix(part, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	file := s.localPath(r.URL.Path)
+	info, err := os.Stat(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	switch {
+	case info.IsDir():
+		s.serveListing(w, file)
+	case isDocumentFile(file) && !r.URL.Query().Has("raw"):
+		s.serveDocument(w, file)
+	default:
+		// images and other files documents link to
+		http.ServeFile(w, r, file)
+	}
+}
+
+// localPath returns the file a URL path points to, which is always in the
+// served directory.
+func (s *previewServer) localPath(urlPath string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+urlPath)))
+}
+
+func (s *previewServer) serveDocument(w http.ResponseWriter, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close() //nolint:errcheck
+
+	md, err := documentMarkdown(&source{reader: f, URL: file})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writePage(w, mermaid.RenderBlocks(md), export.HTMLOptions{
+		TOC:    true,
+		Script: reloadScript,
+	})
+}
+
+// serveListing lists the documents in a directory and below, the way the
+// file listing finds them.
+func (s *previewServer) serveListing(w http.ResponseWriter, dir string) {
+	files, err := stashFiles(dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := filepath.Base(s.root)
+	if rel, err := filepath.Rel(s.root, dir); err == nil && rel != "." {
+		title = filepath.ToSlash(rel)
+	}
+	var b strings.Builder
+	b.WriteString("# " + markdownLinkText.Replace(title) + "\n\n")
+	if len(files) == 0 {
+		b.WriteString("No documents found.\n")
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(s.root, file)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		u := url.URL{Path: "/" + rel}
+		fmt.Fprintf(&b, "- [%s](%s)\n", markdownLinkText.Replace(rel), u.EscapedPath())
+	}
+	s.writePage(w, b.String(), export.HTMLOptions{Title: title})
+}
+
+func (s *previewServer) writePage(w http.ResponseWriter, md string, opts export.HTMLOptions) {
+	opts.Style = s.style
+	page, err := export.HTML([]byte(md), opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(page)
+}
+
+// serveEvents streams server-sent events to a page, telling it to reload
+// when its document changes.
+func (s *previewServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	file := s.localPath(r.URL.Query().Get("path"))
+	if err := s.watcher.Add(filepath.Dir(file)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[ch] = file
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			if _, err := fmt.Fprint(w, "event: reload\ndata: \n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// watch tells the pages showing a file when it's written to.
+func (s *previewServer) watch() {
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+
+			log.Debug("fsnotify event", "file", event.Name, "event", event.Op)
+			s.mu.Lock()
+			for ch, file := range s.clients {
+				if file != event.Name {
+					continue
+				}
+				// a reload is already pending if the channel is full
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+			s.mu.Unlock()
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Debug("fsnotify error", "error", err)
+		}
+	}
+}
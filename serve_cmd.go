@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const defaultServeAddr = "localhost:8080"
+
+var (
+	serveAddr  string
+	serveStyle string
+
+	serveCmd = &cobra.Command{
+		Use:     "serve [DIR]",
+		Short:   "Preview documents in a browser, reloading them as they change",
+		Long:    paragraph(fmt.Sprintf("\n%s the documents in a directory as HTML pages on a local HTTP server. Directories list the documents the file listing would find, and pages reload when their document changes.", keyword("Serve"))),
+		Example: paragraph("glow serve\nglow serve docs --addr localhost:9000 -s light"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			root, err := filepath.Abs(dir)
+			if err != nil {
+				return fmt.Errorf("unable to find directory: %w", err)
+			}
+
+			styleName := serveStyle
+			if styleName == "" {
+				styleName = viper.GetString("style")
+			}
+			if err := validateStyle(styleName); err != nil {
+				return err
+			}
+			cfg, err := utils.StyleConfig(styleName)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			s, err := newPreviewServer(root, cfg)
+			if err != nil {
+				return err
+			}
+			defer s.Close() //nolint:errcheck
+
+			ln, err := net.Listen("tcp", serveAddr)
+			if err != nil {
+				return fmt.Errorf("unable to listen: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s\n", dir, ln.Addr())
+
+			// requests end with the command, so pages waiting for changes
+			// don't keep the server from shutting down
+			ctx := cmd.Context()
+			srv := &http.Server{
+				Handler:           s,
+				ReadHeaderTimeout: 10 * time.Second, //nolint:mnd
+				BaseContext:       func(net.Listener) context.Context { return ctx },
+			}
+			go func() {
+				<-ctx.Done()
+				_ = srv.Shutdown(context.Background())
+			}()
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("unable to serve: %w", err)
+			}
+			return nil
+		},
+	}
+)
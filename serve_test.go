@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/glamour/styles"
+)
+
+func TestPreviewServer(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"README.md":      "# Project\n\n![logo](logo.png)\n",
+		"docs/guide.md":  "# Guide\n",
+		"logo.png":       "png",
+		".env":           "SECRET=1",
+		"docs/notes.txt": "notes",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := newPreviewServer(dir, styles.DarkStyleConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close() //nolint:errcheck
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		want   []string
+	}{
+		{"/", http.StatusOK, []string{`<a href="/README.md">README.md</a>`, `<a href="/docs/guide.md">docs/guide.md</a>`}},
+		{"/docs", http.StatusOK, []string{`<h1 id="docs">docs</h1>`, `<a href="/docs/guide.md">`}},
+		{"/README.md", http.StatusOK, []string{`<h1 id="project">Project</h1>`, `<img src="logo.png"`, "EventSource"}},
+		{"/README.md?raw", http.StatusOK, []string{"# Project"}},
+		{"/logo.png", http.StatusOK, []string{"png"}},
+		{"/.env", http.StatusNotFound, nil},
+		{"/../" + filepath.Base(dir) + "/README.md", http.StatusNotFound, nil},
+		{"/missing.md", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close() //nolint:errcheck
+			b, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, resp.StatusCode, b)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("expected the response to contain %q, got: %s", want, b)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewServerReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(file, []byte("# One\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newPreviewServer(dir, styles.DarkStyleConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close() //nolint:errcheck
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + eventsPath + "?path=/README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+
+	events := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if strings.HasPrefix(sc.Text(), "event: ") {
+				events <- strings.TrimPrefix(sc.Text(), "event: ")
+			}
+		}
+	}()
+
+	if err := os.WriteFile(file, []byte("# Two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e != "reload" {
+			t.Errorf("expected a reload event, got %q", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected a reload event after the file changed")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/charmbracelet/glow/v2/convert"
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/tabular"
+	"github.com/charmbracelet/glow/v2/ui"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var sourceHeaderStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#ECFD65")).
+	Background(lipgloss.Color("#EE6FF8")).
+	Bold(true).
+	Padding(0, 1)
+
+// expandArgs expands glob patterns in the source arguments and removes
+// duplicates. Patterns are expanded for shells that don't do it themselves,
+// and only documents are kept from their matches. Sources that were
+// named explicitly are always kept.
+func expandArgs(args []string) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	seen := map[string]bool{}
+	add := func(arg string) {
+		key := arg
+		if !isURL(arg) && arg != "-" {
+			if abs, err := filepath.Abs(arg); err == nil {
+				key = abs
+			}
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		expanded = append(expanded, arg)
+	}
+
+	for _, arg := range args {
+		if !isGlob(arg) || isURL(arg) {
+			add(arg)
+			continue
+		}
+		if _, err := os.Stat(arg); err == nil {
+			// a file which happens to contain glob characters
+			add(arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+		}
+		var n int
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() || !isDocumentFile(match) {
+				continue
+			}
+			add(match)
+			n++
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("no documents match %s", arg)
+		}
+	}
+	return expanded, nil
+}
+
+// stashFiles returns the documents in a directory the file listing would
+// show, skipping ignored files, sorted by path.
+func stashFiles(dir string) ([]string, error) {
+	cfg, err := env.ParseAs[ui.Config]()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config: %w", err)
+	}
+	cfg.ShowAllFiles = false
+	ch, err := ui.FindFiles(cfg, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find files: %w", err)
+	}
+	var paths []string
+	for res := range ch {
+		if !res.Info.IsDir() {
+			paths = append(paths, res.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// isDocumentFile returns whether glow renders a file as a document rather
+// than as source code.
+func isDocumentFile(name string) bool {
+	return utils.IsMarkdownFile(name) ||
+		convert.ForFile(name) != nil ||
+		notebook.IsNotebook(name) ||
+		epub.IsEPUB(name) ||
+		tabular.IsTableFile(name)
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// localFiles resolves the arguments to absolute paths of local files, which
+// the TUI can then show as a set.
+func localFiles(args []string) ([]string, error) {
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "-" || isURL(arg) {
+			return nil, fmt.Errorf("%s: only local files can be opened as a set", arg)
+		}
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open file: %w", err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory: only files can be opened as a set", arg)
+		}
+		p, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get absolute path: %w", err)
+		}
+		files = append(files, p)
+	}
+	return files, nil
+}
+
+// executeArgs renders several sources one after another, each preceded by a
+// header naming it. When the output is shown in the TUI, its file listing is
+// restricted to the local files among the sources.
+func executeArgs(cmd *cobra.Command, args []string, w io.Writer) error {
+	if jsonOutput {
+		return executeJSON(cmd, args, w)
+	}
+
+	var (
+		b     strings.Builder
+		files []string
+	)
+	for i, arg := range args {
+		src, err := openSource(cmd.Context(), arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		var out string
+		if tocFormat != "" {
+			out, err = renderTOC(src)
+		} else {
+			out, _, err = renderSource(src, defaultRenderOptions())
+		}
+		_ = src.reader.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(sourceHeader(arg))
+		b.WriteString(out)
+
+		if src.URL != "" && !isURL(src.URL) && src.rev == "" {
+			files = append(files, src.URL)
+		}
+	}
+	return display(cmd, b.String(), tuiOptions{files: files}, w)
+}
+
+// sourceHeader renders the separator shown above each of several sources.
+func sourceHeader(name string) string {
+	if name == "-" {
+		name = "stdin"
+	}
+	return "\n  " + sourceHeaderStyle.Render(name) + "\n"
+}
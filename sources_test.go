@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.md", "c.go", "image.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# "+name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.md"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b, c := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md"), filepath.Join(dir, "c.go")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"plain files", []string{a, b}, []string{a, b}, false},
+		{"duplicates removed", []string{a, b, a}, []string{a, b}, false},
+		{"glob keeps markdown only", []string{filepath.Join(dir, "*")}, []string{a, b}, false},
+		{"glob and explicit file deduplicated", []string{a, filepath.Join(dir, "*.md")}, []string{a, b}, false},
+		{"explicit code file kept", []string{c}, []string{c}, false},
+		{"urls kept as is", []string{"https://host.tld/*.md"}, []string{"https://host.tld/*.md"}, false},
+		{"glob without matches", []string{filepath.Join(dir, "*.txt")}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expandArgs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expandArgs()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(file, []byte("# a"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := localFiles([]string{file, "https://host.tld/b.md"}); err == nil {
+		t.Error("expected error for remote source")
+	}
+	if _, err := localFiles([]string{file, dir}); err == nil {
+		t.Error("expected error for directory")
+	}
+	got, err := localFiles([]string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != file {
+		t.Errorf("localFiles() = %v, want [%s]", got, file)
+	}
+}
+
+func TestFindReadme(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files []string
+		want  string
+	}{
+		"none":         {files: []string{"doc.md"}, want: ""},
+		"markdown":     {files: []string{"README.md"}, want: "README.md"},
+		"case":         {files: []string{"readme.md"}, want: "readme.md"},
+		"rst":          {files: []string{"README.rst"}, want: "README.rst"},
+		"asciidoc":     {files: []string{"Readme.adoc"}, want: "Readme.adoc"},
+		"prefer md":    {files: []string{"README.adoc", "README.md", "README.rst"}, want: "README.md"},
+		"prefer rst":   {files: []string{"README.adoc", "README.rst"}, want: "README.rst"},
+		"plain readme": {files: []string{"README", "README.rst"}, want: "README"},
+		"org":          {files: []string{"README.org"}, want: "README.org"},
+		"unknown ext":  {files: []string{"README.txt"}, want: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, f), []byte("x"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := ""
+			if tc.want != "" {
+				want = filepath.Join(dir, tc.want)
+			}
+			if got := findReadme(dir); got != want {
+				t.Errorf("findReadme() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var statsLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+
+var (
+	statsJSON bool
+
+	statsCmd = &cobra.Command{
+		Use:     "stats [SOURCE]...",
+		Short:   "Summarize the length and structure of documents",
+		Long:    paragraph(fmt.Sprintf("\n%s how long markdown documents are and what they're made of: their words, estimated reading time, headings per level, code blocks by language, links and images, and their longest sections.", keyword("Summarize"))),
+		Example: paragraph("glow stats README.md\nglow stats docs/*.md --json"),
+		Args:    cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"-"}
+			}
+
+			stats := make([]documentStats, 0, len(args))
+			for _, arg := range args {
+				src, err := openSource(cmd.Context(), arg)
+				if err != nil {
+					return fmt.Errorf("%s: %w", arg, err)
+				}
+				doc, err := documentStructure(src)
+				_ = src.reader.Close()
+				if err != nil {
+					return fmt.Errorf("%s: %w", arg, err)
+				}
+				stats = append(stats, documentStats{Source: doc.Source, Stats: doc.Stats(), name: arg})
+			}
+
+			w := cmd.OutOrStdout()
+			if statsJSON {
+				return writeJSON(w, stats)
+			}
+			for _, s := range stats {
+				writeStats(w, s)
+			}
+			return nil
+		},
+	}
+)
+
+// documentStats are the statistics of a source.
+type documentStats struct {
+	Source string `json:"source,omitempty"`
+	structure.Stats
+	// name is the argument the source was given as.
+	name string
+}
+
+// writeStats writes the statistics of a document as a report.
+func writeStats(w io.Writer, s documentStats) {
+	row := func(label, value string) {
+		fmt.Fprintf(w, "  %s%s\n", statsLabelStyle.Render(fmt.Sprintf("%-16s", label)), value)
+	}
+
+	fmt.Fprintln(w)
+	if s.name != "-" {
+		fmt.Fprintf(w, "  %s\n\n", tocTopStyle.Render(s.name))
+	}
+	row("Words", humanize.Comma(int64(s.Words)))
+	row("Reading time", structure.FormatReadingTime(s.Words))
+	row("Lines", humanize.Comma(int64(s.Lines)))
+	row("Headings", s.HeadingCounts())
+	row("Code blocks", s.CodeBlockCounts())
+	row("Links", humanize.Comma(int64(s.Links)))
+	row("Images", humanize.Comma(int64(s.Images)))
+
+	if len(s.LongestSections) > 0 {
+		fmt.Fprintf(w, "\n  %s\n", statsLabelStyle.Render("Longest sections"))
+		digits := len(humanize.Comma(int64(s.LongestSections[0].Words)))
+		for _, sec := range s.LongestSections {
+			fmt.Fprintf(w, "  %*s %-5s  %s  %s\n",
+				digits, humanize.Comma(int64(sec.Words)), plural(sec.Words, "word"),
+				sec.Text,
+				tocSlugStyle.Render("#"+sec.Slug),
+			)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// plural returns the plural of a noun, unless there's just one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
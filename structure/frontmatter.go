@@ -0,0 +1,64 @@
+package structure
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// Delimiters of YAML and TOML front matter.
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// FrontMatter parses the YAML or TOML front matter a document starts with.
+// It returns its fields and the length of the front matter, which the body
+// of the document follows.
+func FrontMatter(src []byte) (map[string]any, int, error) {
+	unmarshal := yaml.Unmarshal
+	raw, n := splitFrontMatter(src, yamlDelimiter)
+	if n == 0 {
+		unmarshal = toml.Unmarshal
+		raw, n = splitFrontMatter(src, tomlDelimiter)
+	}
+	if n == 0 {
+		return nil, 0, nil
+	}
+
+	fields := map[string]any{}
+	if err := unmarshal(raw, &fields); err != nil {
+		return nil, 0, fmt.Errorf("unable to parse front matter: %w", err)
+	}
+	return fields, n, nil
+}
+
+// splitFrontMatter returns the content of the front matter between lines
+// consisting of delim, and the length of the front matter including the
+// delimiters. The length is 0 if src doesn't start with front matter.
+func splitFrontMatter(src []byte, delim string) ([]byte, int) {
+	first, rest, ok := cutLine(src)
+	if !ok || string(first) != delim {
+		return nil, 0
+	}
+
+	start := len(src) - len(rest)
+	for off := start; off < len(src); {
+		line, next, _ := cutLine(src[off:])
+		end := len(src) - len(next)
+		if string(line) == delim {
+			return src[start:off], end
+		}
+		off = end
+	}
+	return nil, 0
+}
+
+// cutLine cuts the first line off b, without its line ending. ok reports
+// whether the line was terminated.
+func cutLine(b []byte) (line, rest []byte, ok bool) {
+	line, rest, ok = bytes.Cut(b, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r")), rest, ok
+}
@@ -0,0 +1,106 @@
+package structure
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// metadataOrder is the order well-known front matter fields are listed in.
+// Other fields follow them alphabetically.
+var metadataOrder = []string{
+	"title", "description", "author", "authors",
+	"date", "published", "updated", "lastmod",
+	"tags", "categories", "keywords",
+}
+
+// Field is a front matter field, with its value formatted for display.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Metadata returns the fields of front matter formatted for display, the
+// well-known ones like title, authors, dates and tags first. Empty fields
+// are left out.
+func Metadata(fields map[string]any) []Field {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		if i := slices.Index(metadataOrder, strings.ToLower(name)); i >= 0 {
+			return i
+		}
+		return len(metadataOrder)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	metadata := make([]Field, 0, len(names))
+	for _, name := range names {
+		if v := formatValue(fields[name]); v != "" {
+			metadata = append(metadata, Field{Name: name, Value: v})
+		}
+	}
+	return metadata
+}
+
+// Title returns the title set in front matter, if any.
+func Title(fields map[string]any) string {
+	for name, v := range fields {
+		if s, ok := v.(string); ok && strings.EqualFold(name, "title") {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// formatValue formats a front matter value on a single line. Lists are
+// joined with commas, and dates without a time of day leave it out.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Join(strings.Fields(v), " ")
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format("2006-01-02 15:04")
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := formatValue(item); s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ", ")
+	case map[string]any:
+		// people are often listed with their name and email
+		if name, ok := v["name"]; ok {
+			return formatValue(name)
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if s := formatValue(v[k]); s != "" {
+				values = append(values, k+": "+s)
+			}
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,52 @@
+package structure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Field
+	}{
+		{
+			"yaml",
+			"---\nzone: eu\ntags: [ops, oncall]\ntitle: Runbook\ndate: 2024-03-01\n" +
+				"authors:\n  - name: Ada\n    email: ada@example.com\n  - Grace\ndraft: false\nempty:\n---\n",
+			[]Field{
+				{"title", "Runbook"},
+				{"authors", "Ada, Grace"},
+				{"date", "2024-03-01"},
+				{"tags", "ops, oncall"},
+				{"draft", "false"},
+				{"zone", "eu"},
+			},
+		},
+		{
+			"toml",
+			"+++\ntitle = \"Runbook\"\ndate = 2024-03-01T09:30:00Z\nupdated = 2024-03-02\n[params]\nteam = \"sre\"\n+++\n",
+			[]Field{
+				{"title", "Runbook"},
+				{"date", "2024-03-01 09:30"},
+				{"updated", "2024-03-02"},
+				{"params", "team: sre"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, _, err := FrontMatter([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Metadata(fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected\n%v\ngot\n%v", tt.want, got)
+			}
+			if got := Title(fields); got != "Runbook" {
+				t.Errorf("expected the title Runbook, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package structure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Selection selects part of a document.
+type Selection struct {
+	// Section is the text or the slug of the heading whose section is
+	// selected, with its subsections.
+	Section string
+	// Start and End are the first and last line selected. 0 leaves them
+	// unbounded.
+	Start, End int
+	// MaxLevel leaves out the sections of headings deeper than it, unless
+	// it's 0.
+	MaxLevel int
+}
+
+// Find returns the first heading whose text matches name, ignoring case, or
+// whose slug is name, with or without a leading #.
+func (d *Document) Find(name string) *Heading {
+	var found *Heading
+	Walk(d.Headings, func(h *Heading) {
+		if found == nil && (strings.EqualFold(h.Text, name) || h.Slug == strings.TrimPrefix(name, "#")) {
+			found = h
+		}
+	})
+	return found
+}
+
+// Select returns the lines of a markdown document s selects. Front matter
+// is never selected.
+func Select(src []byte, s Selection) ([]byte, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	_, n, _ := FrontMatter(src)
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	start, end := bytes.Count(src[:n], []byte("\n"))+1, len(lines)
+	if s.Start > 0 {
+		start = max(start, s.Start)
+	}
+	if s.End > 0 {
+		end = min(end, s.End)
+	}
+
+	var section *Heading
+	if s.Section != "" {
+		section = doc.Find(s.Section)
+		if section == nil {
+			return nil, fmt.Errorf("no section named %q", s.Section)
+		}
+		start, end = max(start, section.StartLine), min(end, section.EndLine)
+	}
+
+	skip := make([]bool, len(lines)+1)
+	if s.MaxLevel > 0 {
+		Walk(doc.Headings, func(h *Heading) {
+			if h.Level <= s.MaxLevel || h == section {
+				return
+			}
+			for l := h.StartLine; l <= h.EndLine; l++ {
+				skip[l] = true
+			}
+		})
+	}
+
+	var b bytes.Buffer
+	for l := start; l <= end; l++ {
+		if !skip[l] {
+			b.Write(lines[l-1])
+		}
+	}
+	return b.Bytes(), nil
+}
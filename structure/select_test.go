@@ -0,0 +1,62 @@
+package structure
+
+import "testing"
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection Selection
+		want      string
+	}{
+		{
+			name:      "section",
+			selection: Selection{Section: "Restarting"},
+			want:      "## Restarting\n\n```sh\nsystemctl restart service\n```\n\nSee <https://example.com/status> and\n![the dashboard](dashboard.png).\n\n### Checks\n\n    curl localhost\n",
+		},
+		{
+			name:      "slug",
+			selection: Selection{Section: "#escalating"},
+			want:      "## Escalating\n\nPage the [on-call](#on-call-person).\n",
+		},
+		{
+			name:      "heading level",
+			selection: Selection{Section: "restarting", MaxLevel: 2},
+			want:      "## Restarting\n\n```sh\nsystemctl restart service\n```\n\nSee <https://example.com/status> and\n![the dashboard](dashboard.png).\n\n",
+		},
+		{
+			name:      "document heading level",
+			selection: Selection{MaxLevel: 1},
+			want:      "\n# Runbook\n\nRestart the [service](https://example.com/service) when it's down.\n\n\n",
+		},
+		{
+			name:      "lines",
+			selection: Selection{Start: 12, End: 14},
+			want:      "```sh\nsystemctl restart service\n```\n",
+		},
+		{
+			name:      "lines skip front matter",
+			selection: Selection{End: 6},
+			want:      "\n# Runbook\n",
+		},
+		{
+			name:      "lines within section",
+			selection: Selection{Section: "Escalating", Start: 25},
+			want:      "Page the [on-call](#on-call-person).\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select([]byte(testDocument), tt.selection)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := Select([]byte(testDocument), Selection{Section: "Missing"}); err == nil {
+		t.Error("expected a missing section to fail")
+	}
+}
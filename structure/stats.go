@@ -0,0 +1,152 @@
+package structure
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WordsPerMinute is the reading speed reading times are estimated with.
+const WordsPerMinute = 200
+
+// How many of the longest sections stats list.
+const longestSections = 5
+
+// Stats summarizes a document: how long it is, and what it's made of.
+type Stats struct {
+	Words int `json:"words"`
+	Lines int `json:"lines"`
+	// ReadingMinutes is the estimated reading time, in whole minutes.
+	ReadingMinutes int `json:"reading_minutes"`
+	// Headings counts the headings of each level, h1 first.
+	Headings [6]int `json:"headings"`
+	// CodeBlocks counts the code blocks by language. Blocks without a
+	// language are counted under "".
+	CodeBlocks map[string]int `json:"code_blocks"`
+	Links      int            `json:"links"`
+	Images     int            `json:"images"`
+	// LongestSections are the sections with the most words, longest first.
+	LongestSections []Section `json:"longest_sections"`
+}
+
+// Section is a section of a document, and the words of its own text,
+// leaving out its subsections.
+type Section struct {
+	Text      string `json:"text"`
+	Slug      string `json:"slug"`
+	StartLine int    `json:"start_line"`
+	Words     int    `json:"words"`
+}
+
+// Stats returns the statistics of the document.
+func (d *Document) Stats() Stats {
+	s := Stats{
+		Words:           d.Words,
+		Lines:           d.Lines,
+		ReadingMinutes:  int(ReadingTime(d.Words) / time.Minute),
+		CodeBlocks:      map[string]int{},
+		LongestSections: []Section{},
+	}
+	var sections []Section
+	Walk(d.Headings, func(h *Heading) {
+		s.Headings[h.Level-1]++
+		words := h.Words
+		for _, c := range h.Children {
+			words -= c.Words
+		}
+		sections = append(sections, Section{
+			Text:      h.Text,
+			Slug:      h.Slug,
+			StartLine: h.StartLine,
+			Words:     words,
+		})
+	})
+	for _, c := range d.CodeBlocks {
+		s.CodeBlocks[c.Language]++
+	}
+	for _, l := range d.Links {
+		if l.Image {
+			s.Images++
+		} else {
+			s.Links++
+		}
+	}
+
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sections[i].Words > sections[j].Words
+	})
+	for _, sec := range sections[:min(len(sections), longestSections)] {
+		if sec.Words > 0 {
+			s.LongestSections = append(s.LongestSections, sec)
+		}
+	}
+	return s
+}
+
+// ReadingTime estimates how long it takes to read a number of words,
+// rounded up to the minute.
+func ReadingTime(words int) time.Duration {
+	if words <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(float64(words)/WordsPerMinute)) * time.Minute
+}
+
+// FormatReadingTime describes how long it takes to read a number of words,
+// like "3 min read", or "none" if there are none.
+func FormatReadingTime(words int) string {
+	if words <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d min read", ReadingTime(words)/time.Minute)
+}
+
+// HeadingCounts lists how many headings there are of each level, like
+// "h1 1 · h2 4".
+func (s Stats) HeadingCounts() string {
+	var counts []string
+	for i, n := range s.Headings {
+		if n > 0 {
+			counts = append(counts, fmt.Sprintf("h%d %d", i+1, n))
+		}
+	}
+	return joinCounts(counts)
+}
+
+// CodeBlockCounts lists how many code blocks there are of each language,
+// the most used first, like "go 3 · plain 1".
+func (s Stats) CodeBlockCounts() string {
+	var counts []string
+	for _, lang := range s.Languages() {
+		name := lang
+		if name == "" {
+			name = "plain"
+		}
+		counts = append(counts, fmt.Sprintf("%s %d", name, s.CodeBlocks[lang]))
+	}
+	return joinCounts(counts)
+}
+
+func joinCounts(counts []string) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	return strings.Join(counts, " · ")
+}
+
+// Languages returns the languages of the code blocks, the most used first.
+func (s Stats) Languages() []string {
+	langs := make([]string, 0, len(s.CodeBlocks))
+	for lang := range s.CodeBlocks {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if s.CodeBlocks[langs[i]] != s.CodeBlocks[langs[j]] {
+			return s.CodeBlocks[langs[i]] > s.CodeBlocks[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
@@ -0,0 +1,84 @@
+package structure
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	doc, err := Parse([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := doc.Stats()
+
+	if s.Words != 18 || s.Lines != 25 || s.ReadingMinutes != 1 {
+		t.Errorf("expected 18 words, 25 lines and 1 minute, got %d, %d and %d", s.Words, s.Lines, s.ReadingMinutes)
+	}
+	if want := [6]int{1, 2, 1}; s.Headings != want {
+		t.Errorf("expected headings %v, got %v", want, s.Headings)
+	}
+	if want := map[string]int{"sh": 1, "": 1}; !reflect.DeepEqual(s.CodeBlocks, want) {
+		t.Errorf("expected code blocks %v, got %v", want, s.CodeBlocks)
+	}
+	if want := []string{"", "sh"}; !reflect.DeepEqual(s.Languages(), want) {
+		t.Errorf("expected languages %q, got %q", want, s.Languages())
+	}
+	if got := s.HeadingCounts(); got != "h1 1 · h2 2 · h3 1" {
+		t.Errorf("expected heading counts %q, got %q", "h1 1 · h2 2 · h3 1", got)
+	}
+	if got := s.CodeBlockCounts(); got != "plain 1 · sh 1" {
+		t.Errorf("expected code block counts %q, got %q", "plain 1 · sh 1", got)
+	}
+	if got := (Stats{}).HeadingCounts() + " " + (Stats{}).CodeBlockCounts(); got != "none none" {
+		t.Errorf("expected no counts, got %q", got)
+	}
+	if s.Links != 3 || s.Images != 1 {
+		t.Errorf("expected 3 links and 1 image, got %d and %d", s.Links, s.Images)
+	}
+
+	wantSections := []Section{
+		{Text: "Runbook", Slug: "runbook", StartLine: 6, Words: 7},
+		{Text: "Restarting", Slug: "restarting", StartLine: 10, Words: 6},
+		{Text: "Escalating", Slug: "escalating", StartLine: 23, Words: 4},
+		{Text: "Checks", Slug: "checks", StartLine: 19, Words: 1},
+	}
+	if !reflect.DeepEqual(s.LongestSections, wantSections) {
+		t.Errorf("expected sections\n%+v\ngot\n%+v", wantSections, s.LongestSections)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  time.Duration
+	}{
+		{0, 0},
+		{1, time.Minute},
+		{200, time.Minute},
+		{201, 2 * time.Minute},
+		{1000, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := ReadingTime(tt.words); got != tt.want {
+			t.Errorf("ReadingTime(%d): expected %v, got %v", tt.words, tt.want, got)
+		}
+	}
+}
+
+func TestFormatReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  string
+	}{
+		{0, "none"},
+		{150, "1 min read"},
+		{1001, "6 min read"},
+	}
+	for _, tt := range tests {
+		if got := FormatReadingTime(tt.words); got != tt.want {
+			t.Errorf("FormatReadingTime(%d): expected %q, got %q", tt.words, tt.want, got)
+		}
+	}
+}
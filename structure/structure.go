@@ -0,0 +1,427 @@
+// Package structure parses the structure of markdown documents: their front
+// matter, headings, links and code blocks, along with the lines they're on.
+package structure
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Document is the structure of a markdown document. Lines are numbered from
+// 1, in the source including its front matter.
+type Document struct {
+	// Source the document was read from, if the caller sets it.
+	Source      string         `json:"source,omitempty"`
+	FrontMatter map[string]any `json:"front_matter,omitempty"`
+	// Headings are the top-level headings, with the ones below them nested.
+	Headings   []*Heading  `json:"headings"`
+	Links      []Link      `json:"links"`
+	CodeBlocks []CodeBlock `json:"code_blocks"`
+	Lines      int         `json:"lines"`
+	// Words counts the words of the text, leaving out code blocks.
+	Words int `json:"words"`
+}
+
+// Heading is a heading and the section it starts.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// Slug is the anchor GitHub gives the heading.
+	Slug string `json:"slug"`
+	// StartLine is the line of the heading, EndLine the last line of its
+	// section, up to the next heading of the same or a higher level.
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+	// Words counts the words of the section, including its subsections.
+	Words    int        `json:"words"`
+	Children []*Heading `json:"children,omitempty"`
+}
+
+// Link is a link or an image.
+type Link struct {
+	URL   string `json:"url"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+	Image bool   `json:"image,omitempty"`
+}
+
+// CodeBlock is a fenced or indented code block. Its lines include the
+// fences.
+type CodeBlock struct {
+	Language  string `json:"language,omitempty"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// Parse parses the structure of a markdown document.
+func Parse(src []byte) (*Document, error) {
+	fm, n, err := FrontMatter(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		src:        src,
+		body:       src[n:],
+		offset:     n,
+		lineStarts: lineStarts(src),
+	}
+	p.words = make([]int, len(p.lineStarts)+1)
+
+	md := goldmark.New(goldmark.WithExtensions(
+		extension.GFM, extension.DefinitionList, extension.Footnote,
+	))
+	root := md.Parser().Parse(text.NewReader(p.body))
+
+	doc := &Document{
+		FrontMatter: fm,
+		Links:       []Link{},
+		CodeBlocks:  []CodeBlock{},
+		Lines:       len(p.lineStarts),
+	}
+	if len(src) == 0 {
+		doc.Lines = 0
+	}
+
+	var headings []*Heading
+	slugs := utils.Slugs{}
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			t := p.text(n)
+			headings = append(headings, &Heading{
+				Level:     n.Level,
+				Text:      t,
+				Slug:      slugs.Slug(t),
+				StartLine: p.blockLine(n),
+			})
+		case *ast.FencedCodeBlock:
+			doc.CodeBlocks = append(doc.CodeBlocks, p.fencedCodeBlock(n))
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock:
+			doc.CodeBlocks = append(doc.CodeBlocks, p.codeBlock(n))
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			doc.Links = append(doc.Links, p.link(n, string(n.Destination), false))
+		case *ast.Image:
+			doc.Links = append(doc.Links, p.link(n, string(n.Destination), true))
+		case *ast.AutoLink:
+			u := string(n.URL(p.body))
+			line := p.inlineLine(n, u)
+			p.words[line]++
+			doc.Links = append(doc.Links, Link{
+				URL:  u,
+				Text: string(n.Label(p.body)),
+				Line: line,
+			})
+		case *ast.Text:
+			p.countWords(n.Segment)
+		case *ast.String:
+			// strings have no position, they're counted with their block
+			p.words[p.inlineLine(n, "")] += CountWords(string(n.Value))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, w := range p.words {
+		doc.Words += w
+	}
+	p.sections(headings)
+	doc.Headings = nest(headings)
+	return doc, nil
+}
+
+// Walk calls fn for every heading of the tree, in document order.
+func Walk(headings []*Heading, fn func(*Heading)) {
+	for _, h := range headings {
+		fn(h)
+		Walk(h.Children, fn)
+	}
+}
+
+type parser struct {
+	src []byte
+	// body is the source after its front matter, which starts at offset.
+	body       []byte
+	offset     int
+	lineStarts []int
+	// words counts the words on each line.
+	words []int
+}
+
+// lineStarts returns the offsets the lines of src start at.
+func lineStarts(src []byte) []int {
+	starts := []int{0}
+	for i, c := range src {
+		if c == '\n' && i+1 < len(src) {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// line returns the line of an offset in the body.
+func (p *parser) line(offset int) int {
+	return sort.Search(len(p.lineStarts), func(i int) bool {
+		return p.lineStarts[i] > offset+p.offset
+	})
+}
+
+// lineText returns the text of a line, without its line ending.
+func (p *parser) lineText(line int) string {
+	if line < 1 || line > len(p.lineStarts) {
+		return ""
+	}
+	end := len(p.src)
+	if line < len(p.lineStarts) {
+		end = p.lineStarts[line]
+	}
+	return strings.TrimRight(string(p.src[p.lineStarts[line-1]:end]), "\r\n")
+}
+
+// blockLine returns the first line of a block.
+func (p *parser) blockLine(n ast.Node) int {
+	if n.Lines().Len() > 0 {
+		return p.line(n.Lines().At(0).Start)
+	}
+	if h, ok := n.(*ast.Heading); ok {
+		return p.headingLine(h)
+	}
+	// blocks without content of their own, like lists, start with their
+	// first child
+	if c := n.FirstChild(); c != nil && c.Type() == ast.TypeBlock {
+		return p.blockLine(c)
+	}
+	// empty blocks, like empty list items, follow the previous block
+	return p.searchStart(n)
+}
+
+// blockEndLine returns the last line of a block.
+func (p *parser) blockEndLine(n ast.Node) int {
+	switch n := n.(type) {
+	case *ast.FencedCodeBlock:
+		return p.fencedCodeBlock(n).EndLine
+	}
+	if c := n.LastChild(); c != nil && c.Type() == ast.TypeBlock {
+		return p.blockEndLine(c)
+	}
+	if l := n.Lines().Len(); l > 0 {
+		return p.line(n.Lines().At(l-1).Stop - 1)
+	}
+	return p.blockLine(n)
+}
+
+// inlineLine returns the line of an inline node: the line of its first text
+// or, failing that, the line needle is found on in its block.
+func (p *parser) inlineLine(n ast.Node, needle string) int {
+	var line int
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := c.(*ast.Text); ok && entering && t.Segment.Len() > 0 {
+			line = p.line(t.Segment.Start)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if line > 0 {
+		return line
+	}
+
+	block := n.Parent()
+	for block != nil && block.Type() != ast.TypeBlock {
+		block = block.Parent()
+	}
+	if block == nil {
+		return p.line(0)
+	}
+	start := p.blockLine(block)
+	if needle != "" {
+		from := p.lineStarts[start-1] - p.offset
+		if i := bytes.Index(p.body[from:], []byte(needle)); i >= 0 {
+			return p.line(from + i)
+		}
+	}
+	return start
+}
+
+func (p *parser) countWords(seg text.Segment) {
+	if seg.Len() == 0 {
+		return
+	}
+	p.words[p.line(seg.Start)] += CountWords(string(seg.Value(p.body)))
+}
+
+// CountWords counts the words of s, leaving out punctuation.
+func CountWords(s string) int {
+	var n int
+	for _, f := range strings.Fields(s) {
+		if strings.IndexFunc(f, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsNumber(r)
+		}) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// text returns the text of an inline container, without markup.
+func (p *parser) text(n ast.Node) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(p.body))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.AutoLink:
+			b.Write(c.Label(p.body))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+func (p *parser) link(n ast.Node, dest string, image bool) Link {
+	return Link{
+		URL:   dest,
+		Text:  p.text(n),
+		Line:  p.inlineLine(n, dest),
+		Image: image,
+	}
+}
+
+func (p *parser) codeBlock(n *ast.CodeBlock) CodeBlock {
+	lines := n.Lines()
+	return CodeBlock{
+		StartLine: p.line(lines.At(0).Start),
+		EndLine:   p.line(lines.At(lines.Len()-1).Stop - 1),
+	}
+}
+
+func (p *parser) fencedCodeBlock(n *ast.FencedCodeBlock) CodeBlock {
+	cb := CodeBlock{Language: string(n.Language(p.body))}
+	lines := n.Lines()
+	switch {
+	case n.Info != nil:
+		cb.StartLine = p.line(n.Info.Segment.Start)
+	case lines.Len() > 0:
+		cb.StartLine = p.line(lines.At(0).Start) - 1
+	default:
+		cb.StartLine = p.fenceLine(n)
+	}
+
+	last := cb.StartLine
+	if lines.Len() > 0 {
+		last = p.line(lines.At(lines.Len()-1).Stop - 1)
+	}
+	// fences left open run to the end of their container
+	cb.EndLine = last
+	fence := strings.TrimLeft(p.lineText(cb.StartLine), " >\t")
+	if len(fence) > 0 {
+		c := fence[0]
+		if strings.HasPrefix(strings.TrimLeft(p.lineText(last+1), " >\t"), strings.Repeat(string(c), 3)) {
+			cb.EndLine = last + 1
+		}
+	}
+	return cb
+}
+
+// fenceLine returns the line of the opening fence of an empty code block
+// without an info string, the first fence after the previous block.
+func (p *parser) fenceLine(n ast.Node) int {
+	from := p.searchStart(n)
+	for l := from; l <= len(p.lineStarts); l++ {
+		s := strings.TrimLeft(p.lineText(l), " >\t-*+0123456789.)")
+		if strings.HasPrefix(s, "```") || strings.HasPrefix(s, "~~~") {
+			return l
+		}
+	}
+	return from
+}
+
+// headingLine returns the line of an ATX heading without text, which has
+// no lines of its own: the first line after the previous block that is a run
+// of as many #s as its level.
+func (p *parser) headingLine(n *ast.Heading) int {
+	from := p.searchStart(n)
+	marker := strings.Repeat("#", n.Level)
+	for l := from; l <= len(p.lineStarts); l++ {
+		s := strings.TrimLeft(p.lineText(l), " >\t-*+0123456789.)")
+		if rest, ok := strings.CutPrefix(s, marker); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return l
+		}
+	}
+	return from
+}
+
+// searchStart returns the first line a block without lines of its own can
+// be on: the line after the previous block, or where its container can
+// start. It only looks at earlier blocks and containers, never at the
+// block's own line: containers start with their first child, which would
+// ask the search again.
+func (p *parser) searchStart(n ast.Node) int {
+	from := 1
+	if prev := n.PreviousSibling(); prev != nil {
+		from = p.blockEndLine(prev) + 1
+	} else if parent := n.Parent(); parent != nil && parent.Kind() != ast.KindDocument {
+		from = p.searchStart(parent)
+	}
+	return max(from, p.line(0))
+}
+
+// sections sets the end lines and word counts of the sections the headings
+// start.
+func (p *parser) sections(headings []*Heading) {
+	last := len(p.lineStarts)
+	for i, h := range headings {
+		end := last
+		for _, next := range headings[i+1:] {
+			if next.Level <= h.Level {
+				end = next.StartLine - 1
+				break
+			}
+		}
+		for end > h.StartLine && strings.TrimSpace(p.lineText(end)) == "" {
+			end--
+		}
+		h.EndLine = end
+		for l := h.StartLine; l <= end; l++ {
+			h.Words += p.words[l]
+		}
+	}
+}
+
+// nest turns a list of headings into a tree.
+func nest(headings []*Heading) []*Heading {
+	roots := []*Heading{}
+	var stack []*Heading
+	for _, h := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, h)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		stack = append(stack, h)
+	}
+	return roots
+}
@@ -0,0 +1,164 @@
+package structure
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testDocument = `---
+title: Runbook
+tags: [ops, oncall]
+---
+
+# Runbook
+
+Restart the [service](https://example.com/service) when it's down.
+
+## Restarting
+
+` + "```sh" + `
+systemctl restart service
+` + "```" + `
+
+See <https://example.com/status> and
+![the dashboard](dashboard.png).
+
+### Checks
+
+    curl localhost
+
+## Escalating
+
+Page the [on-call](#on-call-person).
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFrontMatter := map[string]any{"title": "Runbook", "tags": []any{"ops", "oncall"}}
+	if !reflect.DeepEqual(doc.FrontMatter, wantFrontMatter) {
+		t.Errorf("expected front matter %v, got %v", wantFrontMatter, doc.FrontMatter)
+	}
+
+	var headings []Heading
+	Walk(doc.Headings, func(h *Heading) {
+		hh := *h
+		hh.Children = nil
+		headings = append(headings, hh)
+	})
+	wantHeadings := []Heading{
+		{Level: 1, Text: "Runbook", Slug: "runbook", StartLine: 6, EndLine: 25, Words: 18},
+		{Level: 2, Text: "Restarting", Slug: "restarting", StartLine: 10, EndLine: 21, Words: 7},
+		{Level: 3, Text: "Checks", Slug: "checks", StartLine: 19, EndLine: 21, Words: 1},
+		{Level: 2, Text: "Escalating", Slug: "escalating", StartLine: 23, EndLine: 25, Words: 4},
+	}
+	if !reflect.DeepEqual(headings, wantHeadings) {
+		t.Errorf("expected headings\n%+v\ngot\n%+v", wantHeadings, headings)
+	}
+	if len(doc.Headings) != 1 || len(doc.Headings[0].Children) != 2 {
+		t.Errorf("expected one top-level heading with two children, got %+v", doc.Headings)
+	}
+
+	wantLinks := []Link{
+		{URL: "https://example.com/service", Text: "service", Line: 8},
+		{URL: "https://example.com/status", Text: "https://example.com/status", Line: 16},
+		{URL: "dashboard.png", Text: "the dashboard", Line: 17, Image: true},
+		{URL: "#on-call-person", Text: "on-call", Line: 25},
+	}
+	if !reflect.DeepEqual(doc.Links, wantLinks) {
+		t.Errorf("expected links\n%+v\ngot\n%+v", wantLinks, doc.Links)
+	}
+
+	wantCode := []CodeBlock{
+		{Language: "sh", StartLine: 12, EndLine: 14},
+		{StartLine: 21, EndLine: 21},
+	}
+	if !reflect.DeepEqual(doc.CodeBlocks, wantCode) {
+		t.Errorf("expected code blocks %+v, got %+v", wantCode, doc.CodeBlocks)
+	}
+
+	if doc.Lines != 25 || doc.Words != 18 {
+		t.Errorf("expected 25 lines and 18 words, got %d and %d", doc.Lines, doc.Words)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	doc, err := Parse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Lines != 0 || doc.Words != 0 || len(doc.Headings) != 0 {
+		t.Errorf("expected an empty document, got %+v", doc)
+	}
+}
+
+func TestParseEmptyHeadings(t *testing.T) {
+	doc, err := Parse([]byte("#\n\n##\n\ntext\n\n> ###\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines [][2]int
+	Walk(doc.Headings, func(h *Heading) {
+		lines = append(lines, [2]int{h.StartLine, h.EndLine})
+	})
+	if want := [][2]int{{1, 7}, {3, 7}, {7, 7}}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected heading lines %v, got %v", want, lines)
+	}
+
+	// empty list items before empty headings
+	tests := []struct {
+		src  string
+		line int
+	}{
+		{"-\n#\n", 2},
+		{"para\n\n-\n#\n", 4},
+		{"- a\n-\n\n#\n", 4},
+	}
+	for _, tt := range tests {
+		doc, err := Parse([]byte(tt.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Headings) != 1 || doc.Headings[0].StartLine != tt.line {
+			t.Errorf("%q: expected a heading on line %d, got %+v", tt.src, tt.line, doc.Headings)
+		}
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		fields map[string]any
+		n      int
+	}{
+		{"none", "# Title\n", nil, 0},
+		{"yaml", "---\ntitle: x\n---\n# Title\n", map[string]any{"title": "x"}, 17},
+		{"crlf", "---\r\ntitle: x\r\n---\r\nbody", map[string]any{"title": "x"}, 20},
+		{"toml", "+++\ntitle = \"x\"\ntags = [\"a\"]\n+++\n# Title\n", map[string]any{"title": "x", "tags": []any{"a"}}, 33},
+		{"unclosed", "---\ntitle: x\n", nil, 0},
+		{"rule", "text\n---\n", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, n, err := FrontMatter([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) || n != tt.n {
+				t.Errorf("expected %v (%d), got %v (%d)", tt.fields, tt.n, fields, n)
+			}
+		})
+	}
+
+	if _, _, err := FrontMatter([]byte("---\n: [\n---\n")); err == nil {
+		t.Error("expected invalid front matter to fail")
+	}
+	if _, _, err := FrontMatter([]byte("+++\ntitle =\n+++\n")); err == nil {
+		t.Error("expected invalid TOML front matter to fail")
+	}
+}
@@ -0,0 +1,311 @@
+// Package tabular renders delimiter-separated data, such as CSV and TSV
+// files, as aligned tables for the terminal.
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultMaxCellWidth is the width at which cells are truncated by default.
+const DefaultMaxCellWidth = 40
+
+// margin is the left margin of rendered tables, matching glamour's document
+// margin.
+const margin = "  "
+
+// Extensions are the file extensions of the formats we can render.
+var Extensions = []string{".csv", ".tsv"}
+
+// candidateDelimiters are the delimiters we try when sniffing a file.
+var candidateDelimiters = []rune{',', '\t', ';', '|'}
+
+var (
+	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#7D56F4", Dark: "#AD8CFF"})
+	borderStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#BDBDBD", Dark: "#4A4A4A"})
+)
+
+// Table is parsed tabular data.
+type Table struct {
+	// Header holds the column names, if the data has a header row.
+	Header []string
+	Rows   [][]string
+}
+
+// IsTableFile returns whether a file contains tabular data, based on its
+// extension.
+func IsTableFile(filename string) bool {
+	ext := filepath.Ext(filename)
+	for _, v := range Extensions {
+		if strings.EqualFold(ext, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses delimiter-separated data. TSV files are always split on
+// tabs; for other files the delimiter is detected from the data. A header
+// row is detected as well.
+func Parse(b []byte, filename string) (*Table, error) {
+	b = bytes.TrimPrefix(b, []byte("\ufeff"))
+
+	var (
+		rows [][]string
+		err  error
+	)
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		rows = parseTSV(b)
+	} else {
+		rows, err = parseDelimited(b, detectDelimiter(b))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t := &Table{Rows: rows}
+	if hasHeader(rows) {
+		t.Header, t.Rows = rows[0], rows[1:]
+	}
+	return t, nil
+}
+
+// parseTSV splits TSV data into rows. Unlike CSV, fields in TSV files
+// aren't quoted, so quotes are kept as they are.
+func parseTSV(b []byte) [][]string {
+	var rows [][]string
+	for _, l := range strings.Split(string(b), "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		if l == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(l, "\t"))
+	}
+	return rows
+}
+
+func parseDelimited(b []byte, delim rune) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = delim
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse table: %w", err)
+		}
+		rows = append(rows, rec)
+	}
+}
+
+// detectDelimiter picks the candidate delimiter which splits the first lines
+// of the data into the same, largest number of fields.
+func detectDelimiter(b []byte) rune {
+	var lines []string
+	for _, l := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		lines = append(lines, l)
+		if len(lines) == 10 {
+			break
+		}
+	}
+
+	best, bestScore := ',', 0
+	if len(lines) == 0 {
+		return best
+	}
+	for _, d := range candidateDelimiters {
+		n := countOutsideQuotes(lines[:1], d)
+		if n == 0 {
+			continue
+		}
+		consistent := 0
+		for _, l := range lines {
+			if countOutsideQuotes([]string{l}, d) == n {
+				consistent++
+			}
+		}
+		// prefer delimiters used consistently, then more columns
+		score := consistent*1000 + n
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
+
+// countOutsideQuotes counts how often d appears in lines outside of
+// double-quoted fields.
+func countOutsideQuotes(lines []string, d rune) int {
+	n := 0
+	for _, l := range lines {
+		quoted := false
+		for _, r := range l {
+			switch r {
+			case '"':
+				quoted = !quoted
+			case d:
+				if !quoted {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// hasHeader guesses whether the first row is a header: its cells must be
+// non-empty, distinct and not numeric.
+func hasHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, c := range rows[0] {
+		c = strings.TrimSpace(c)
+		if c == "" || isNumeric(c) || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "%")
+	s = strings.TrimLeft(s, "$€£")
+	s = strings.ReplaceAll(s, ",", "")
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// Render renders the table with aligned columns. Cells wider than
+// maxCellWidth are truncated; a maxCellWidth of 0 disables truncation.
+// Lines aren't wrapped, so the result may be wider than the terminal.
+func (t *Table) Render(maxCellWidth int) string {
+	cols := len(t.Header)
+	for _, r := range t.Rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	cell := func(row []string, i int) string {
+		if i >= len(row) {
+			return ""
+		}
+		s := strings.Join(strings.Fields(row[i]), " ")
+		if maxCellWidth > 0 {
+			s = ansi.Truncate(s, maxCellWidth, "…")
+		}
+		return s
+	}
+
+	widths := make([]int, cols)
+	numeric := make([]bool, cols)
+	for i := range cols {
+		widths[i] = ansi.StringWidth(cell(t.Header, i))
+		numeric[i] = len(t.Rows) > 0
+		for _, r := range t.Rows {
+			c := cell(r, i)
+			widths[i] = max(widths[i], ansi.StringWidth(c))
+			if c != "" && !isNumeric(c) {
+				numeric[i] = false
+			}
+		}
+	}
+
+	sep := borderStyle.Render("│")
+	line := func(row []string, style *lipgloss.Style) string {
+		var b strings.Builder
+		for i := range cols {
+			if i > 0 {
+				b.WriteString(" " + sep + " ")
+			}
+			c := cell(row, i)
+			pad := strings.Repeat(" ", widths[i]-ansi.StringWidth(c))
+			if style != nil {
+				c = style.Render(c)
+			}
+			if numeric[i] {
+				b.WriteString(pad + c)
+			} else {
+				b.WriteString(c + pad)
+			}
+		}
+		return margin + strings.TrimRight(b.String(), " ")
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	if t.Header != nil {
+		b.WriteString(line(t.Header, &headerStyle) + "\n")
+		parts := make([]string, cols)
+		for i, w := range widths {
+			parts[i] = strings.Repeat("─", w)
+		}
+		b.WriteString(margin + borderStyle.Render(strings.Join(parts, "─┼─")) + "\n")
+	}
+	for _, r := range t.Rows {
+		b.WriteString(line(r, nil) + "\n")
+	}
+	return b.String()
+}
+
+// Markdown returns the table as a GitHub Flavored Markdown table. Tables
+// without a header get an empty one, which markdown requires.
+func (t *Table) Markdown() string {
+	cols := len(t.Header)
+	for _, r := range t.Rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	escape := strings.NewReplacer("\\", "\\\\", "|", "\\|")
+	line := func(row []string) string {
+		cells := make([]string, cols)
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = escape.Replace(strings.Join(strings.Fields(row[i]), " "))
+			}
+		}
+		return "| " + strings.Join(cells, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(line(t.Header))
+	b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+	for _, r := range t.Rows {
+		b.WriteString(line(r))
+	}
+	return b.String()
+}
+
+// Render parses delimiter-separated data and renders it as a table.
+func Render(b []byte, filename string, maxCellWidth int) (string, error) {
+	t, err := Parse(b, filename)
+	if err != nil {
+		return "", err
+	}
+	return t.Render(maxCellWidth), nil
+}
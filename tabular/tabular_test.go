@@ -0,0 +1,147 @@
+package tabular
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		in       string
+		header   []string
+		rows     [][]string
+	}{
+		{
+			name:     "comma with header",
+			filename: "a.csv",
+			in:       "name,age\nBob,42\nAlice,7\n",
+			header:   []string{"name", "age"},
+			rows:     [][]string{{"Bob", "42"}, {"Alice", "7"}},
+		},
+		{
+			name:     "numeric first row is data",
+			filename: "a.csv",
+			in:       "1,2\n3,4\n",
+			rows:     [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:     "semicolon delimiter",
+			filename: "a.csv",
+			in:       "a;b;c\n1,5;2;3\n",
+			header:   []string{"a", "b", "c"},
+			rows:     [][]string{{"1,5", "2", "3"}},
+		},
+		{
+			name:     "quoted delimiters are ignored when sniffing",
+			filename: "a.csv",
+			in:       "city,country\n\"Paris; France\",FR\n",
+			header:   []string{"city", "country"},
+			rows:     [][]string{{"Paris; France", "FR"}},
+		},
+		{
+			name:     "tab delimiter in csv file",
+			filename: "a.csv",
+			in:       "x\ty\n1\t2\n",
+			header:   []string{"x", "y"},
+			rows:     [][]string{{"1", "2"}},
+		},
+		{
+			name:     "tsv keeps quotes",
+			filename: "a.TSV",
+			in:       "title\tsize\n\"quoted\" text, with comma\t3\n",
+			header:   []string{"title", "size"},
+			rows:     [][]string{{`"quoted" text, with comma`, "3"}},
+		},
+		{
+			name:     "duplicate header cells are data",
+			filename: "a.csv",
+			in:       "a,a\nb,c\n",
+			rows:     [][]string{{"a", "a"}, {"b", "c"}},
+		},
+		{
+			name:     "byte order mark",
+			filename: "a.csv",
+			in:       "\ufeffname,age\nBob,1\n",
+			header:   []string{"name", "age"},
+			rows:     [][]string{{"Bob", "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl, err := Parse([]byte(tt.in), tt.filename)
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if !reflect.DeepEqual(tbl.Header, tt.header) {
+				t.Errorf("header = %q, want %q", tbl.Header, tt.header)
+			}
+			if !reflect.DeepEqual(tbl.Rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", tbl.Rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tbl := &Table{
+		Header: []string{"name", "count"},
+		Rows: [][]string{
+			{"apples", "3"},
+			{"a rather long name", "12"},
+			{"short"},
+		},
+	}
+
+	got := ansi.Strip(tbl.Render(10))
+	want := strings.Join([]string{
+		"",
+		"  name       │ count",
+		"  ───────────┼──────",
+		"  apples     │     3",
+		"  a rather … │    12",
+		"  short      │",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tbl := &Table{
+		Rows: [][]string{
+			{"a|b", "1"},
+			{"c"},
+		},
+	}
+
+	want := strings.Join([]string{
+		"|  |  |",
+		"| --- | --- |",
+		"| a\\|b | 1 |",
+		"| c |  |",
+		"",
+	}, "\n")
+	if got := tbl.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIsTableFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"data.csv":  true,
+		"DATA.TSV":  true,
+		"README.md": false,
+		"csv":       false,
+	} {
+		if got := IsTableFile(name); got != want {
+			t.Errorf("IsTableFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
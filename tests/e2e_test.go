@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"encoding/json"
+	"flag"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -8,7 +10,10 @@ import (
 	"testing"
 )
 
-var glowBin string
+var (
+	glowBin string
+	update  = flag.Bool("update", false, "update golden files")
+)
 
 func TestMain(m *testing.M) {
 	tmp, err := os.MkdirTemp("", "glow-e2e-*")
@@ -120,6 +125,321 @@ func TestLongStdinPipedPrintsOutput(t *testing.T) {
 	}
 }
 
+func TestRenderMultipleFiles(t *testing.T) {
+	out, err := exec.Command(glowBin, "testdata/test.md", "testdata/long.md", "testdata/test.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow with multiple files failed: %v\n%s", err, out)
+	}
+	output := string(out)
+	for _, want := range []string{"testdata/test.md", "testdata/long.md", "Long Test Document"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got: %s", want, output)
+		}
+	}
+	if n := strings.Count(output, "testdata/test.md"); n != 1 {
+		t.Errorf("expected duplicate file to be rendered once, got %d headers", n)
+	}
+}
+
+func TestRenderGlob(t *testing.T) {
+	out, err := exec.Command(glowBin, "testdata/*.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow with glob failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "testdata/long.md") {
+		t.Errorf("expected glob to expand to testdata/long.md, got: %s", out)
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		colored bool
+	}{
+		{"auto", nil, false},
+		{"always", []string{"--color", "always"}, true},
+		{"always with profile", []string{"--color", "always", "--color-profile", "256"}, true},
+		{"never", []string{"--color", "never"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "test.txt")
+			args := append([]string{"-o", out}, tt.args...)
+			if b, err := exec.Command(glowBin, append(args, "testdata/test.md")...).CombinedOutput(); err != nil {
+				t.Fatalf("glow -o failed: %v\n%s", err, b)
+			}
+			b, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), "Test") {
+				t.Errorf("expected the file to contain the document, got: %s", b)
+			}
+			if colored := strings.Contains(string(b), "\x1b["); colored != tt.colored {
+				t.Errorf("expected colored to be %v, got: %q", tt.colored, b)
+			}
+		})
+	}
+}
+
+func TestOutputFileKept(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	keep := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(doc, []byte("# Doc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keep, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the output can't be an input
+	if b, err := exec.Command(glowBin, doc, "-o", doc).CombinedOutput(); err == nil {
+		t.Errorf("expected writing over the input to fail, got: %s", b)
+	}
+	if b, _ := os.ReadFile(doc); string(b) != "# Doc\n" {
+		t.Errorf("expected the input to be kept, got %q", b)
+	}
+
+	// failures leave the output file alone
+	if b, err := exec.Command(glowBin, filepath.Join(dir, "missing.md"), "-o", keep).CombinedOutput(); err == nil {
+		t.Errorf("expected a missing source to fail, got: %s", b)
+	}
+	if b, _ := os.ReadFile(keep); string(b) != "keep" {
+		t.Errorf("expected the output file to be kept, got %q", b)
+	}
+}
+
+func TestColorPiped(t *testing.T) {
+	out, err := exec.Command(glowBin, "--color", "always", "testdata/test.md").Output()
+	if err != nil {
+		t.Fatalf("glow --color always failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "\x1b[") {
+		t.Errorf("expected colored output, got: %q", out)
+	}
+}
+
+func TestInvalidColor(t *testing.T) {
+	for _, args := range [][]string{
+		{"--color", "sometimes"},
+		{"--color-profile", "rainbow"},
+		{"-o", "out.txt", "--pager"},
+	} {
+		if err := exec.Command(glowBin, append(args, "testdata/test.md")...).Run(); err == nil {
+			t.Errorf("expected glow %v to fail", args)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	out, err := exec.Command(glowBin, "--json", "testdata/test.md").Output()
+	if err != nil {
+		t.Fatalf("glow --json failed: %v\n%s", err, out)
+	}
+	var doc struct {
+		Headings []struct {
+			Text     string `json:"text"`
+			Children []struct {
+				Text      string `json:"text"`
+				StartLine int    `json:"start_line"`
+			} `json:"children"`
+		} `json:"headings"`
+		CodeBlocks []struct {
+			Language string `json:"language"`
+		} `json:"code_blocks"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("expected json, got: %s", out)
+	}
+	if len(doc.Headings) != 1 || doc.Headings[0].Text != "Test Document" || len(doc.Headings[0].Children) != 2 {
+		t.Fatalf("unexpected headings: %s", out)
+	}
+	if h := doc.Headings[0].Children[1]; h.Text != "Code Example" || h.StartLine != 11 {
+		t.Errorf("unexpected heading: %+v", h)
+	}
+	if len(doc.CodeBlocks) != 1 || doc.CodeBlocks[0].Language != "go" {
+		t.Errorf("unexpected code blocks: %+v", doc.CodeBlocks)
+	}
+}
+
+func TestSection(t *testing.T) {
+	out, err := exec.Command(glowBin, "--section", "code example", "testdata/test.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow --section failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Hello, world!") || strings.Contains(string(out), "Item one") {
+		t.Errorf("expected only the Code Example section, got: %s", out)
+	}
+
+	out, err = exec.Command(glowBin, "--lines", "5:9", "testdata/test.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow --lines failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Item three") || strings.Contains(string(out), "Test Document") {
+		t.Errorf("expected only lines 5 to 9, got: %s", out)
+	}
+
+	if err := exec.Command(glowBin, "--section", "Missing", "testdata/test.md").Run(); err == nil {
+		t.Error("expected a missing section to fail")
+	}
+}
+
+func TestTOC(t *testing.T) {
+	cmd := exec.Command(glowBin, "--toc=markdown", "-")
+	cmd.Stdin = strings.NewReader("# Title\n\n## Getting Started\n\ntext\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow --toc=markdown failed: %v\n%s", err, out)
+	}
+	if want := "- [Title](#title)\n  - [Getting Started](#getting-started)\n"; string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+
+	out, err = exec.Command(glowBin, "--toc", "testdata/test.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow --toc failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "11    Code Example  #code-example") {
+		t.Errorf("expected the outline to list Code Example on line 11, got: %s", out)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A\n\n[b](b.md#b) [c](c.md)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.md"), []byte("# B\n\n[a](a.md#a)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(glowBin, "check", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err == nil {
+		t.Fatal("expected glow check to fail on a broken link")
+	}
+	if want := "a.md:3: broken link: c.md does not exist\n"; string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+
+	if out, err := exec.Command(glowBin, "check", filepath.Join(dir, "b.md")).CombinedOutput(); err != nil {
+		t.Errorf("expected glow check to pass, got: %v\n%s", err, out)
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	const doc = "+++\ntitle = \"Runbook\"\ntags = [\"ops\", \"oncall\"]\n+++\n\n# Restarting\n"
+	cmd := exec.Command(glowBin, "-")
+	cmd.Stdin = strings.NewReader(doc)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"title  Runbook", "tags   ops, oncall", "Restarting"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected the output to contain %q, got: %s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "+++") {
+		t.Errorf("expected the front matter to be removed, got: %s", out)
+	}
+
+	cmd = exec.Command(glowBin, "--front-matter=false", "-")
+	cmd.Stdin = strings.NewReader(doc)
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow --front-matter=false failed: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "Runbook") {
+		t.Errorf("expected no front matter, got: %s", out)
+	}
+}
+
+func TestStats(t *testing.T) {
+	cmd := exec.Command(glowBin, "stats", "--json", "-")
+	cmd.Stdin = strings.NewReader("# Title\n\nA few words.\n\n```go\nx := 1\n```\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow stats --json failed: %v\n%s", err, out)
+	}
+	var stats []struct {
+		Words          int            `json:"words"`
+		ReadingMinutes int            `json:"reading_minutes"`
+		CodeBlocks     map[string]int `json:"code_blocks"`
+	}
+	if err := json.Unmarshal(out, &stats); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if len(stats) != 1 || stats[0].Words != 4 || stats[0].ReadingMinutes != 1 || stats[0].CodeBlocks["go"] != 1 {
+		t.Errorf("unexpected stats: %s", out)
+	}
+
+	out, err = exec.Command(glowBin, "stats", "testdata/test.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("glow stats failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"testdata/test.md", "Reading time", "Longest sections"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected the report to contain %q, got: %s", want, out)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "test.html")
+	if b, err := exec.Command(glowBin, "export", "--format", "html", "testdata/test.md", "-o", out).CombinedOutput(); err != nil {
+		t.Fatalf("glow export failed: %v\n%s", err, b)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "<style>", "<main>"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected the page to contain %q, got: %s", want, b)
+		}
+	}
+}
+
+func TestExportSVG(t *testing.T) {
+	out, err := exec.Command(glowBin, "export", "--format", "svg", "-w", "40", "-s", "dark", "testdata/test.md").Output()
+	if err != nil {
+		t.Fatalf("glow export --format svg failed: %v\n%s", err, out)
+	}
+	// colors are kept, even though the output isn't a terminal
+	for _, want := range []string{"<svg", "fill=\"#00afff\""} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected the image to contain %q, got: %s", want, out)
+		}
+	}
+}
+
+func TestExportSVGGolden(t *testing.T) {
+	golden := filepath.Join("testdata", "export.svg")
+	// highlighting used to pick different colors from run to run
+	for range 5 {
+		out, err := exec.Command(glowBin, "export", "--format", "svg", "-w", "60", "-s", "dark", "testdata/export.md").Output()
+		if err != nil {
+			t.Fatalf("glow export --format svg failed: %v\n%s", err, out)
+		}
+		if *update {
+			if err := os.WriteFile(golden, out, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != string(want) {
+			t.Fatalf("the image doesn't match the golden file, got:\n%s", out)
+		}
+	}
+}
+
 func TestHelpFlag(t *testing.T) {
 	out, err := exec.Command(glowBin, "--help").CombinedOutput()
 	if err != nil {
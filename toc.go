@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Table of contents formats.
+const (
+	tocText     = "text"
+	tocMarkdown = "markdown"
+)
+
+var (
+	tocLineStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+	tocTopStyle     = lipgloss.NewStyle().Bold(true)
+	tocHeadingStyle = lipgloss.NewStyle()
+	tocSlugStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#036B46"})
+)
+
+var markdownLinkText = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// renderTOC reads a source and returns the table of contents of its
+// headings in the --toc format. Selections narrow down the headings listed.
+func renderTOC(src *source) (string, error) {
+	md, _, err := readMarkdown(src)
+	if err != nil {
+		return "", err
+	}
+	doc, err := structure.Parse([]byte(md))
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	roots := doc.Headings
+	if selection.Section != "" {
+		h := doc.Find(selection.Section)
+		if h == nil {
+			return "", fmt.Errorf("no section named %q", selection.Section)
+		}
+		roots = []*structure.Heading{h}
+	}
+	var headings []*structure.Heading
+	structure.Walk(roots, func(h *structure.Heading) {
+		switch {
+		case selection.MaxLevel > 0 && h.Level > selection.MaxLevel:
+		case selection.Start > 0 && h.StartLine < selection.Start:
+		case selection.End > 0 && h.StartLine > selection.End:
+		default:
+			headings = append(headings, h)
+		}
+	})
+
+	if tocFormat == tocMarkdown {
+		return markdownTOC(headings), nil
+	}
+	return textTOC(headings), nil
+}
+
+// minLevel returns the level of the highest headings, which aren't
+// indented.
+func minLevel(headings []*structure.Heading) int {
+	level := 6 //nolint:mnd
+	for _, h := range headings {
+		level = min(level, h.Level)
+	}
+	return level
+}
+
+// textTOC returns an indented outline of the headings, with the lines
+// they're on and their anchors.
+func textTOC(headings []*structure.Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+	top := minLevel(headings)
+	digits := len(strconv.Itoa(headings[len(headings)-1].StartLine))
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, h := range headings {
+		style := tocHeadingStyle
+		if h.Level == top {
+			style = tocTopStyle
+		}
+		fmt.Fprintf(&b, "  %s  %s%s  %s\n",
+			tocLineStyle.Render(fmt.Sprintf("%*d", digits, h.StartLine)),
+			strings.Repeat("  ", h.Level-top),
+			style.Render(h.Text),
+			tocSlugStyle.Render("#"+h.Slug),
+		)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// markdownTOC returns the table of contents as a nested markdown list of
+// links to the headings, ready to be put in the document.
+func markdownTOC(headings []*structure.Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+	top := minLevel(headings)
+
+	var b strings.Builder
+	for _, h := range headings {
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", h.Level-top), markdownLinkText.Replace(h.Text), h.Slug)
+	}
+	return b.String()
+}
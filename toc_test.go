@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/glow/v2/structure"
+)
+
+func TestMarkdownTOC(t *testing.T) {
+	headings := []*structure.Heading{
+		{Level: 2, Text: "Install", Slug: "install", StartLine: 3},
+		{Level: 3, Text: "From [source]", Slug: "from-source", StartLine: 9},
+		{Level: 2, Text: "Usage", Slug: "usage", StartLine: 20},
+	}
+	want := "- [Install](#install)\n  - [From \\[source\\]](#from-source)\n- [Usage](#usage)\n"
+	if got := markdownTOC(headings); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if got := markdownTOC(nil); got != "" {
+		t.Errorf("expected no table of contents without headings, got %q", got)
+	}
+}
+
+func TestTextTOC(t *testing.T) {
+	headings := []*structure.Heading{
+		{Level: 1, Text: "Glow", Slug: "glow", StartLine: 1},
+		{Level: 2, Text: "Install", Slug: "install", StartLine: 12},
+	}
+	want := "\n   1  Glow  #glow\n  12    Install  #install\n\n"
+	if got := textTOC(headings); got != want {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, got)
+	}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/log"
+)
+
+// readingPosition is where reading a book was left off.
+type readingPosition struct {
+	Chapter int `json:"chapter"`
+	Line    int `json:"line"`
+}
+
+// loadBook loads the current chapter of a book into md. When the book is
+// opened rather than reloaded, the stored reading position is restored.
+func loadBook(md *markdown, data []byte) error {
+	book, err := epub.Parse(data)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if md.book == nil {
+		md.book = book
+		pos := readingPositions(config.ReadingPositionsFile)[bookKey(*md)]
+		md.chapter, md.yOffset = pos.Chapter, pos.Line
+	}
+	md.book = book
+	md.chapter = max(0, min(md.chapter, len(book.Chapters)-1))
+	md.Body = book.Chapters[md.chapter].Markdown
+	return nil
+}
+
+// bookKey identifies a book in the stored reading positions. It's the
+// book's identifier, so the position is kept when the file is moved.
+func bookKey(md markdown) string {
+	if md.book.Identifier != "" {
+		return md.book.Identifier
+	}
+	if path, err := filepath.Abs(md.localPath); err == nil {
+		return path
+	}
+	return md.localPath
+}
+
+// readingPositions reads the stored reading positions, by book.
+func readingPositions(file string) map[string]readingPosition {
+	positions := map[string]readingPosition{}
+	if file == "" {
+		return positions
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Error("unable to read reading positions", "error", err)
+		}
+		return positions
+	}
+	if err := json.Unmarshal(b, &positions); err != nil {
+		log.Error("unable to parse reading positions", "error", err)
+	}
+	return positions
+}
+
+// storeReadingPosition stores the reading position of a book.
+func storeReadingPosition(file, key string, pos readingPosition) error {
+	positions := readingPositions(file)
+	positions[key] = pos
+	b, err := json.MarshalIndent(positions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode reading positions: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to create data dir: %w", err)
+	}
+	if err := os.WriteFile(file, b, 0o600); err != nil {
+		return fmt.Errorf("unable to write reading positions: %w", err)
+	}
+	return nil
+}
+
+// saveReadingPosition remembers where the current book was left off.
+func (m pagerModel) saveReadingPosition() {
+	md := m.currentDocument
+	if md.book == nil || config.ReadingPositionsFile == "" {
+		return
+	}
+	pos := readingPosition{Chapter: md.chapter, Line: m.viewport.YOffset}
+	if err := storeReadingPosition(config.ReadingPositionsFile, bookKey(md), pos); err != nil {
+		log.Error("unable to save reading position", "error", err)
+	}
+}
+
+// goToChapter shows a chapter of the current book from its start.
+func (m *pagerModel) goToChapter(i int) tea.Cmd {
+	book := m.currentDocument.book
+	switch {
+	case i < 0:
+		return m.showStatusMessage(pagerStatusMessage{"first chapter", false})
+	case i >= len(book.Chapters):
+		return m.showStatusMessage(pagerStatusMessage{"last chapter", false})
+	}
+
+	m.currentDocument.chapter = i
+	m.currentDocument.Body = book.Chapters[i].Markdown
+	m.clearSearch()
+	m.viewport.GotoTop()
+	m.saveReadingPosition()
+	m.renderSeq++
+	return renderWithGlamour(*m, m.currentDocument.Body)
+}
+
+func (m *pagerModel) handleChapterKeys(msg tea.KeyMsg) tea.Cmd {
+	chapters := m.currentDocument.book.Chapters
+	switch msg.String() {
+	case "k", "up":
+		m.chapterCursor = max(0, m.chapterCursor-1)
+	case "j", "down":
+		m.chapterCursor = min(len(chapters)-1, m.chapterCursor+1)
+	case "g", "home":
+		m.chapterCursor = 0
+	case "G", "end":
+		m.chapterCursor = len(chapters) - 1
+
+	case keyEnter:
+		m.state = pagerStateBrowse
+		if m.chapterCursor == m.currentDocument.chapter {
+			return nil
+		}
+		cmd := m.goToChapter(m.chapterCursor)
+		if m.viewport.HighPerformanceRendering {
+			return tea.Batch(cmd, viewport.Sync(m.viewport))
+		}
+		return cmd
+
+	case keyEsc, "T", "q":
+		m.state = pagerStateBrowse
+	}
+	return nil
+}
+
+// chapterListView renders the chapters of the current book in place of the
+// document.
+func (m pagerModel) chapterListView() string {
+	book := m.currentDocument.book
+	items := make([]string, 0, len(book.Chapters))
+	for i, c := range book.Chapters {
+		items = append(items, fmt.Sprintf("%d. %s", i+1, c.Title))
+	}
+	title := book.Title
+	if title == "" {
+		title = "Chapters"
+	}
+	return m.listView(title, items, m.chapterCursor)
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/epub"
+)
+
+// testBook builds an EPUB book with the given chapters.
+func testBook(t *testing.T, chapters ...string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`,
+	}
+	var manifest, spine strings.Builder
+	for i, c := range chapters {
+		name := string(rune('a'+i)) + ".xhtml"
+		manifest.WriteString(`<item id="` + name + `" href="` + name + `"/>`)
+		spine.WriteString(`<itemref idref="` + name + `"/>`)
+		files[name] = "<html><body><h1>" + c + "</h1><p>Text of " + c + ".</p></body></html>"
+	}
+	files["content.opf"] = `<package><metadata><identifier>test-book</identifier><title>Test</title></metadata>` +
+		`<manifest>` + manifest.String() + `</manifest><spine>` + spine.String() + `</spine></package>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestChapterNavigation(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	positions := filepath.Join(t.TempDir(), "positions.json")
+	m := testPagerModel(80, 10, Config{ReadingPositionsFile: positions})
+	m.currentDocument = markdown{Note: "book.epub", localPath: "book.epub"}
+	if err := loadBook(&m.currentDocument, testBook(t, "One", "Two", "Three")); err != nil {
+		t.Fatalf("loadBook() error: %v", err)
+	}
+	if m.currentDocument.chapter != 0 || !strings.Contains(m.currentDocument.Body, "# One") {
+		t.Fatalf("expected the first chapter, got %d %q", m.currentDocument.chapter, m.currentDocument.Body)
+	}
+
+	render := func(cmd tea.Cmd) {
+		t.Helper()
+		msg, ok := cmd().(contentRenderedMsg)
+		if !ok {
+			t.Fatal("expected the chapter to be rendered")
+		}
+		m, _ = m.update(msg)
+	}
+
+	render(m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")}))
+	if m.currentDocument.chapter != 1 || !strings.Contains(m.viewport.View(), "Two") {
+		t.Errorf("expected the second chapter, got %d %q", m.currentDocument.chapter, m.viewport.View())
+	}
+	if !strings.Contains(m.View(), "EPUB ch 2/3") {
+		t.Errorf("status bar should show the chapter, got %q", m.View())
+	}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if m.state != pagerStateChapters || !m.inInputMode() {
+		t.Fatalf("expected the chapter list, state %v", m.state)
+	}
+	if view := m.View(); !strings.Contains(view, "Test") || !strings.Contains(view, "3. Three") {
+		t.Errorf("View() should show the chapter list, got %q", view)
+	}
+	m.handleChapterKeys(tea.KeyMsg{Type: tea.KeyDown})
+	render(m.handleChapterKeys(tea.KeyMsg{Type: tea.KeyEnter}))
+	if m.state != pagerStateBrowse || m.currentDocument.chapter != 2 {
+		t.Errorf("expected the third chapter, got %d in state %v", m.currentDocument.chapter, m.state)
+	}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.state != pagerStateStatusMessage || m.statusMessage != "last chapter" {
+		t.Errorf("expected a status message, got state %v %q", m.state, m.statusMessage)
+	}
+
+	// reopening the book restores the chapter
+	m.saveReadingPosition()
+	reopened := markdown{Note: "book.epub", localPath: "book.epub"}
+	if err := loadBook(&reopened, testBook(t, "One", "Two", "Three")); err != nil {
+		t.Fatalf("loadBook() error: %v", err)
+	}
+	if reopened.chapter != 2 || !strings.Contains(reopened.Body, "# Three") {
+		t.Errorf("expected the third chapter to be restored, got %d", reopened.chapter)
+	}
+}
+
+func TestReadingPositions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "glow", "positions.json")
+	if got := readingPositions(file); len(got) != 0 {
+		t.Errorf("expected no positions, got %v", got)
+	}
+	if err := storeReadingPosition(file, "a", readingPosition{Chapter: 1, Line: 20}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storeReadingPosition(file, "b", readingPosition{Chapter: 3}); err != nil {
+		t.Fatal(err)
+	}
+	got := readingPositions(file)
+	if got["a"] != (readingPosition{Chapter: 1, Line: 20}) || got["b"] != (readingPosition{Chapter: 3}) {
+		t.Errorf("readingPositions() = %v", got)
+	}
+
+	// books without an identifier are known by their path
+	md := markdown{localPath: "/books/novel.epub", book: &epub.Book{}}
+	if key := bookKey(md); key != filepath.Clean("/books/novel.epub") {
+		t.Errorf("bookKey() = %q", key)
+	}
+}
@@ -1,5 +1,7 @@
 package ui
 
+import "time"
+
 // Config contains TUI-specific configuration.
 type Config struct {
 	ShowAllFiles     bool
@@ -11,9 +13,21 @@ type Config struct {
 	EnableMouse      bool
 	PreserveNewLines bool
 
+	// List CSV and TSV files in the file listing, too
+	ShowTables bool
+
 	// Working directory or file path
 	Path string
 
+	// Files the file listing is restricted to, instead of searching Path
+	Files []string
+
+	// When the document was fetched, if it was served from the offline cache
+	CachedAt time.Time
+
+	// File the reading positions in books are stored in
+	ReadingPositionsFile string
+
 	// For debugging the UI
 	HighPerformancePager bool `env:"GLOW_HIGH_PERFORMANCE_PAGER" envDefault:"true"`
 	GlamourEnabled       bool `env:"GLOW_ENABLE_GLAMOUR"         envDefault:"true"`
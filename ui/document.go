@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/convert"
+	"github.com/charmbracelet/glow/v2/datatree"
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/glow/v2/tabular"
+	"github.com/charmbracelet/glow/v2/utils"
+)
+
+// documentBody returns the content to render for a local file: documents in
+// other formats are converted to markdown, and front matter is removed from
+// markdown documents.
+func documentBody(path string, content []byte) (string, error) {
+	if notebook.IsNotebook(path) || datatree.IsDataFile(path) {
+		// notebooks and data files are parsed when they're rendered
+		return string(content), nil
+	}
+	if conv := convert.ForFile(path); conv != nil {
+		md, err := conv(content)
+		if err != nil {
+			return "", fmt.Errorf("unable to convert document: %w", err)
+		}
+		return md, nil
+	}
+	return string(utils.RemoveFrontmatter(content)), nil
+}
+
+// documentMetadata returns the fields of the front matter of a markdown
+// document. Front matter that doesn't parse is ignored.
+func documentMetadata(path string, content []byte) []structure.Field {
+	if !utils.IsMarkdownFile(path) {
+		return nil
+	}
+	fields, _, err := structure.FrontMatter(content)
+	if err != nil {
+		return nil
+	}
+	return structure.Metadata(fields)
+}
+
+// isCodeFile returns whether a file should be rendered as source code
+// rather than as a document.
+func isCodeFile(path string) bool {
+	return !utils.IsMarkdownFile(path) &&
+		!notebook.IsNotebook(path) &&
+		!epub.IsEPUB(path) &&
+		convert.ForFile(path) == nil
+}
+
+// documentFormat returns the name of the format a document was written in,
+// or an empty string for markdown and source code.
+func documentFormat(path string) string {
+	switch {
+	case notebook.IsNotebook(path):
+		return "Notebook"
+	case epub.IsEPUB(path):
+		return "EPUB"
+	case tabular.IsTableFile(path):
+		return strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+	case datatree.IsDataFile(path):
+		return datatree.FormatName(path)
+	}
+	return convert.FormatName(path)
+}
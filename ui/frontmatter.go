@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// frontMatterView renders the front matter of the current document in place
+// of the document.
+func (m pagerModel) frontMatterView() string {
+	height := max(1, m.viewport.Height)
+	lines := []string{"", "  " + fuchsiaFg("Front matter"), ""}
+
+	metadata := m.currentDocument.metadata
+	var nameWidth int
+	for _, f := range metadata {
+		nameWidth = max(nameWidth, xansi.StringWidth(f.Name))
+	}
+	for _, f := range metadata {
+		line := "  " + brightGrayFg(fmt.Sprintf("%-*s", nameWidth, f.Name)) + "  " + f.Value
+		lines = append(lines, xansi.Truncate(line, max(0, m.viewport.Width), ellipsis))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:height], "\n")
+}
+
+func (m *pagerModel) handleFrontMatterKeys(msg tea.KeyMsg) {
+	switch msg.String() {
+	case keyEsc, keyEnter, "m", "q":
+		m.state = pagerStateBrowse
+	}
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFrontMatterPanel(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	content := []byte("+++\ntitle = \"Runbook\"\ntags = [\"ops\", \"oncall\"]\n+++\n# Runbook\n")
+	m := testPagerModel(80, 10, Config{})
+	m.currentDocument = markdown{Note: "runbook.md", Body: "# Runbook\n", metadata: documentMetadata("runbook.md", content)}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if m.state != pagerStateFrontMatter || !m.inInputMode() {
+		t.Fatalf("expected the front matter to be shown, state %v", m.state)
+	}
+	view := m.View()
+	for _, want := range []string{"Front matter", "title  Runbook", "tags   ops, oncall"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q, got %q", want, view)
+		}
+	}
+	m.handleFrontMatterKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if m.state != pagerStateBrowse {
+		t.Errorf("state = %v, want browse", m.state)
+	}
+
+	// documents without front matter have no panel
+	m.currentDocument = markdown{Note: "notes.md", Body: "# Notes\n"}
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if m.state == pagerStateFrontMatter {
+		t.Error("expected no front matter panel")
+	}
+}
+
+func TestDocumentMetadata(t *testing.T) {
+	content := []byte("---\ntitle: Notes\n---\n# Notes\n")
+	if got := documentMetadata("notes.md", content); len(got) != 1 || got[0].Value != "Notes" {
+		t.Errorf("expected the title field, got %+v", got)
+	}
+	if got := documentMetadata("notes.txt", content); got != nil {
+		t.Errorf("expected no front matter for source code, got %+v", got)
+	}
+	if got := documentMetadata("bad.md", []byte("---\n: [\n---\n")); got != nil {
+		t.Errorf("expected invalid front matter to be ignored, got %+v", got)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	md := markdown{Note: "docs/runbook.md"}
+	if got := md.displayName(); got != "docs/runbook.md" {
+		t.Errorf("displayName() = %q, want the path", got)
+	}
+	md.title = "Runbook"
+	if got := md.displayName(); got != "Runbook" {
+		t.Errorf("displayName() = %q, want the title", got)
+	}
+	md.buildFilterValue()
+	if md.filterValue != "Runbook" {
+		t.Errorf("filterValue = %q, want the title", md.filterValue)
+	}
+}
@@ -5,10 +5,10 @@ package ui
 
 import "path/filepath"
 
-func ignorePatterns(m commonModel) []string {
+func ignorePatterns(cfg Config) []string {
 	return []string{
-		filepath.Join(m.cfg.HomeDir, "Library"),
-		m.cfg.Gopath,
+		filepath.Join(cfg.HomeDir, "Library"),
+		cfg.Gopath,
 		"node_modules",
 		".*",
 	}
@@ -6,6 +6,8 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/glow/v2/structure"
 	"github.com/charmbracelet/log"
 	"github.com/dustin/go-humanize"
 	"golang.org/x/text/runes"
@@ -23,6 +25,29 @@ type markdown struct {
 	// field is ephemeral, and should only be referenced during filtering.
 	filterValue string
 
+	// When the document was fetched, if it was served from the offline
+	// cache rather than the network.
+	cachedAt time.Time
+
+	// Books are shown a chapter at a time. Body is the current chapter.
+	book    *epub.Book
+	chapter int
+
+	// Line to scroll to once the document is rendered, which restores the
+	// reading position in books.
+	yOffset int
+
+	// words counts the words of the document, if it was read to estimate
+	// its reading time.
+	words int
+
+	// title is the title set in the front matter of the document, which is
+	// shown instead of its path.
+	title string
+
+	// Fields of the front matter of the document, once it's loaded.
+	metadata []structure.Field
+
 	Body    string
 	Note    string
 	Modtime time.Time
@@ -30,19 +55,38 @@ type markdown struct {
 
 // Generate the value we're doing to filter against.
 func (m *markdown) buildFilterValue() {
-	note, err := normalize(m.Note)
+	name := m.displayName()
+	note, err := normalize(name)
 	if err != nil {
-		log.Error("error normalizing", "note", m.Note, "error", err)
-		m.filterValue = m.Note
+		log.Error("error normalizing", "note", name, "error", err)
+		m.filterValue = name
 	}
 
 	m.filterValue = note
 }
 
+// displayName returns the name the document is listed under: the title in
+// its front matter, or its path.
+func (m markdown) displayName() string {
+	if m.title != "" {
+		return m.title
+	}
+	return m.Note
+}
+
 func (m markdown) relativeTime() string {
 	return relativeTime(m.Modtime)
 }
 
+// readingTime returns the estimated reading time of the document, if its
+// words were counted.
+func (m markdown) readingTime() string {
+	if m.words == 0 {
+		return ""
+	}
+	return structure.FormatReadingTime(m.words)
+}
+
 // Normalize text to aid in the filtering process. In particular, we remove
 // diacritics, "ö" becomes "o". Note that Mn is the unicode key for nonspacing
 // marks.
@@ -13,6 +13,11 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glow/v2/datatree"
+	"github.com/charmbracelet/glow/v2/mermaid"
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/glow/v2/tabular"
 	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -26,6 +31,9 @@ import (
 const (
 	statusBarHeight = 1
 	lineNumberWidth = 4
+
+	// How many columns the pager scrolls left or right at a time.
+	horizontalScrollStep = 8
 )
 
 var (
@@ -96,6 +104,10 @@ const (
 	pagerStateStatusMessage
 	pagerStateSearch
 	pagerStateJumpToLine
+	pagerStateTOC
+	pagerStateChapters
+	pagerStateStats
+	pagerStateFrontMatter
 )
 
 type pagerModel struct {
@@ -120,6 +132,22 @@ type pagerModel struct {
 	// Jump to line
 	lineInput textinput.Model
 
+	// Table of contents
+	toc       []tocEntry
+	tocCursor int
+
+	// Chapter list of books
+	chapterCursor int
+
+	// Statistics of the document, while they're shown
+	stats *structure.Stats
+
+	// Tree of data files, and the paths of its collapsed nodes
+	tree          *datatree.Node
+	treeNodes     []*datatree.Node
+	treeCollapsed map[string]bool
+	treeCursor    int
+
 	watcher *fsnotify.Watcher
 
 	renderSeq int
@@ -181,6 +209,28 @@ func (m *pagerModel) setContent(s string) {
 	m.viewport.SetContent(s)
 }
 
+// setHorizontalScroll enables or disables scrolling left and right, which
+// is used for content that isn't wrapped, like tables.
+func (m *pagerModel) setHorizontalScroll(enabled bool) {
+	if enabled {
+		m.viewport.SetHorizontalStep(horizontalScrollStep)
+		return
+	}
+	m.viewport.SetHorizontalStep(0)
+	m.viewport.SetXOffset(0)
+}
+
+func (m pagerModel) scrollsHorizontally() bool {
+	return tabular.IsTableFile(m.currentDocument.Note)
+}
+
+// handlesLeftKey returns whether h moves within the document, like left,
+// rather than going back to the files. It collapses the nodes of trees and
+// scrolls tables.
+func (m pagerModel) handlesLeftKey() bool {
+	return m.tree != nil || m.scrollsHorizontally()
+}
+
 func (m *pagerModel) toggleHelp() {
 	m.showHelp = !m.showHelp
 	m.setSize(m.common.width, m.common.height)
@@ -210,11 +260,16 @@ func (m *pagerModel) showStatusMessage(msg pagerStatusMessage) tea.Cmd {
 }
 
 // inInputMode returns true when the pager is in a state that consumes
-// arbitrary key input (search prompt, jump prompt) or has active search
-// results that esc should clear before unloading the document.
+// arbitrary key input (search prompt, jump prompt, table of contents, chapter
+// list, statistics, front matter) or has active search results that esc should
+// clear before unloading the document.
 func (m pagerModel) inInputMode() bool {
 	return m.state == pagerStateSearch ||
 		m.state == pagerStateJumpToLine ||
+		m.state == pagerStateTOC ||
+		m.state == pagerStateChapters ||
+		m.state == pagerStateStats ||
+		m.state == pagerStateFrontMatter ||
 		m.searchQuery != ""
 }
 
@@ -226,6 +281,7 @@ func (m *pagerModel) clearSearch() {
 
 func (m *pagerModel) unload() {
 	log.Debug("unload")
+	m.saveReadingPosition()
 	if m.showHelp {
 		m.toggleHelp()
 	}
@@ -234,8 +290,12 @@ func (m *pagerModel) unload() {
 	}
 	m.state = pagerStateBrowse
 	m.clearSearch()
+	m.toc = nil
+	m.stats = nil
+	m.tree, m.treeNodes, m.treeCollapsed, m.treeCursor = nil, nil, nil, 0
 	m.viewport.SetContent("")
 	m.viewport.YOffset = 0
+	m.setHorizontalScroll(false)
 	m.unwatchFile()
 }
 
@@ -253,6 +313,27 @@ func findMatches(content string, query string) []int {
 	return matches
 }
 
+// findMatches finds the lines of the current document that contain the
+// query. In a tree, they're the visible nodes.
+func (m pagerModel) findMatches(query string) []int {
+	if m.tree != nil {
+		return findMatches(m.treeLines(), query)
+	}
+	return findMatches(m.currentDocument.Body, query)
+}
+
+// showMatch scrolls to the current search match, or moves the cursor of a
+// tree to it.
+func (m *pagerModel) showMatch() {
+	line := m.searchMatches[m.searchIndex]
+	if m.tree != nil {
+		m.treeCursor = line
+		m.renderTree()
+		return
+	}
+	m.viewport.SetYOffset(line)
+}
+
 func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
@@ -270,12 +351,34 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			cmds = append(cmds, m.handleJumpInput(msg))
 			return m, tea.Batch(cmds...)
 
+		case pagerStateTOC:
+			cmds = append(cmds, m.handleTOCKeys(msg))
+			return m, tea.Batch(cmds...)
+
+		case pagerStateChapters:
+			cmds = append(cmds, m.handleChapterKeys(msg))
+			return m, tea.Batch(cmds...)
+
+		case pagerStateStats:
+			m.handleStatsKeys(msg)
+			return m, nil
+
+		case pagerStateFrontMatter:
+			m.handleFrontMatterKeys(msg)
+			return m, nil
+
 		case pagerStateStatusMessage:
 			// Any key returns to browse
 			m.state = pagerStateBrowse
 			return m, nil
 
 		case pagerStateBrowse:
+			if m.tree != nil {
+				// the cursor moves rather than the viewport
+				if cmd, ok := m.handleTreeKeys(msg); ok {
+					return m, cmd
+				}
+			}
 			cmds = append(cmds, m.handleBrowseKeys(msg))
 		}
 
@@ -304,6 +407,13 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			m.currentDocument.Body = msg.body
 		}
 		m.setContent(msg.content)
+		m.setHorizontalScroll(m.scrollsHorizontally())
+		m.toc = m.tableOfContents(msg.content)
+		m.loadTree(m.currentDocument.Body)
+		if m.currentDocument.yOffset > 0 {
+			m.viewport.SetYOffset(m.currentDocument.yOffset)
+			m.currentDocument.yOffset = 0
+		}
 		if m.viewport.HighPerformanceRendering {
 			cmds = append(cmds, viewport.Sync(m.viewport))
 		}
@@ -383,12 +493,20 @@ func (m *pagerModel) handleBrowseKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 
 	case "right":
+		if m.scrollsHorizontally() {
+			// the viewport scrolls to the right
+			break
+		}
 		m.viewport.ViewDown()
 		if m.viewport.HighPerformanceRendering {
 			cmds = append(cmds, viewport.Sync(m.viewport))
 		}
 
 	case "left":
+		if m.scrollsHorizontally() {
+			// the viewport scrolls to the left
+			break
+		}
 		m.viewport.ViewUp()
 		if m.viewport.HighPerformanceRendering {
 			cmds = append(cmds, viewport.Sync(m.viewport))
@@ -434,6 +552,43 @@ func (m *pagerModel) handleBrowseKeys(msg tea.KeyMsg) tea.Cmd {
 		m.lineInput.Focus()
 		return textinput.Blink
 
+	case "t":
+		if len(m.toc) == 0 {
+			return m.showStatusMessage(pagerStatusMessage{"no headings", false})
+		}
+		m.state = pagerStateTOC
+		m.tocCursor = m.tocCurrentEntry()
+
+	case "s":
+		stats, ok := m.documentStats()
+		if !ok {
+			return m.showStatusMessage(pagerStatusMessage{"no statistics", false})
+		}
+		m.state = pagerStateStats
+		m.stats = stats
+
+	case "m":
+		if len(m.currentDocument.metadata) == 0 {
+			return m.showStatusMessage(pagerStatusMessage{"no front matter", false})
+		}
+		m.state = pagerStateFrontMatter
+
+	case "]":
+		if m.currentDocument.book != nil {
+			return m.goToChapter(m.currentDocument.chapter + 1)
+		}
+
+	case "[":
+		if m.currentDocument.book != nil {
+			return m.goToChapter(m.currentDocument.chapter - 1)
+		}
+
+	case "T":
+		if m.currentDocument.book != nil {
+			m.state = pagerStateChapters
+			m.chapterCursor = m.currentDocument.chapter
+		}
+
 	case "n":
 		if m.searchQuery != "" && len(m.searchMatches) > 0 {
 			m.searchIndex++
@@ -441,7 +596,7 @@ func (m *pagerModel) handleBrowseKeys(msg tea.KeyMsg) tea.Cmd {
 				m.searchIndex = 0
 				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"search wrapped", false}))
 			}
-			m.viewport.SetYOffset(m.searchMatches[m.searchIndex])
+			m.showMatch()
 			if m.viewport.HighPerformanceRendering {
 				cmds = append(cmds, viewport.Sync(m.viewport))
 			}
@@ -454,7 +609,7 @@ func (m *pagerModel) handleBrowseKeys(msg tea.KeyMsg) tea.Cmd {
 				m.searchIndex = len(m.searchMatches) - 1
 				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"search wrapped", false}))
 			}
-			m.viewport.SetYOffset(m.searchMatches[m.searchIndex])
+			m.showMatch()
 			if m.viewport.HighPerformanceRendering {
 				cmds = append(cmds, viewport.Sync(m.viewport))
 			}
@@ -473,14 +628,14 @@ func (m *pagerModel) handleSearchInput(msg tea.KeyMsg) tea.Cmd {
 			return m.showStatusMessage(pagerStatusMessage{"no pattern", false})
 		}
 		m.searchQuery = query
-		m.searchMatches = findMatches(m.currentDocument.Body, query)
+		m.searchMatches = m.findMatches(query)
 		if len(m.searchMatches) == 0 {
 			m.searchQuery = ""
 			m.state = pagerStateBrowse
 			return m.showStatusMessage(pagerStatusMessage{"no matches", false})
 		}
 		m.searchIndex = 0
-		m.viewport.SetYOffset(m.searchMatches[0])
+		m.showMatch()
 		m.state = pagerStateBrowse
 		if m.viewport.HighPerformanceRendering {
 			return viewport.Sync(m.viewport)
@@ -549,9 +704,44 @@ func (m *pagerModel) handleJumpInput(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
+func (m *pagerModel) handleTOCKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "k", "up":
+		m.tocCursor = max(0, m.tocCursor-1)
+	case "j", "down":
+		m.tocCursor = min(len(m.toc)-1, m.tocCursor+1)
+	case "g", "home":
+		m.tocCursor = 0
+	case "G", "end":
+		m.tocCursor = len(m.toc) - 1
+
+	case keyEnter:
+		m.state = pagerStateBrowse
+		m.viewport.SetYOffset(m.toc[m.tocCursor].line)
+		if m.viewport.HighPerformanceRendering {
+			return viewport.Sync(m.viewport)
+		}
+
+	case keyEsc, "t", "q":
+		m.state = pagerStateBrowse
+	}
+	return nil
+}
+
 func (m pagerModel) View() string {
 	var b strings.Builder
-	fmt.Fprint(&b, m.viewport.View()+"\n")
+	switch m.state { //nolint:exhaustive
+	case pagerStateTOC:
+		fmt.Fprint(&b, m.tocView()+"\n")
+	case pagerStateChapters:
+		fmt.Fprint(&b, m.chapterListView()+"\n")
+	case pagerStateStats:
+		fmt.Fprint(&b, m.statsView()+"\n")
+	case pagerStateFrontMatter:
+		fmt.Fprint(&b, m.frontMatterView()+"\n")
+	default:
+		fmt.Fprint(&b, m.viewport.View()+"\n")
+	}
 
 	// Footer
 	m.statusBarView(&b)
@@ -624,6 +814,31 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		matchCounter = statusBarScrollPosStyle(matchCounter)
 	}
 
+	// Format of documents converted from other formats
+	var format string
+	if f := documentFormat(m.currentDocument.Note); f != "" {
+		format = " " + f + " "
+	}
+	if book := m.currentDocument.book; book != nil {
+		format += fmt.Sprintf("ch %d/%d ", m.currentDocument.chapter+1, len(book.Chapters))
+	}
+	if showStatusMessage {
+		format = statusBarMessageScrollPosStyle(format)
+	} else {
+		format = statusBarScrollPosStyle(format)
+	}
+
+	// Age of documents served from the offline cache
+	var cacheAge string
+	if !m.currentDocument.cachedAt.IsZero() {
+		cacheAge = " cached " + relativeTime(m.currentDocument.cachedAt) + " "
+	}
+	if showStatusMessage {
+		cacheAge = statusBarMessageScrollPosStyle(cacheAge)
+	} else {
+		cacheAge = statusBarScrollPosStyle(cacheAge)
+	}
+
 	// Scroll percent
 	percent := math.Max(minPercent, math.Min(maxPercent, m.viewport.ScrollPercent()))
 	scrollPercent := fmt.Sprintf(" %3.f%% ", percent*percentToStringMagnitude)
@@ -647,10 +862,15 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		note = m.statusMessage
 	} else {
 		note = m.currentDocument.Note
+		if m.tree != nil {
+			note += " › " + m.treePath()
+		}
 	}
 	note = truncate.StringWithTail(" "+note+" ", uint(max(0, //nolint:gosec
 		m.common.width-
 			ansi.PrintableRuneWidth(logo)-
+			ansi.PrintableRuneWidth(format)-
+			ansi.PrintableRuneWidth(cacheAge)-
 			ansi.PrintableRuneWidth(matchCounter)-
 			ansi.PrintableRuneWidth(pageIndicator)-
 			ansi.PrintableRuneWidth(scrollPercent)-
@@ -667,6 +887,8 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		m.common.width-
 			ansi.PrintableRuneWidth(logo)-
 			ansi.PrintableRuneWidth(note)-
+			ansi.PrintableRuneWidth(format)-
+			ansi.PrintableRuneWidth(cacheAge)-
 			ansi.PrintableRuneWidth(matchCounter)-
 			ansi.PrintableRuneWidth(pageIndicator)-
 			ansi.PrintableRuneWidth(scrollPercent)-
@@ -679,10 +901,12 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		emptySpace = statusBarNoteStyle(emptySpace)
 	}
 
-	fmt.Fprintf(b, "%s%s%s%s%s%s%s",
+	fmt.Fprintf(b, "%s%s%s%s%s%s%s%s%s",
 		logo,
 		note,
 		emptySpace,
+		format,
+		cacheAge,
 		matchCounter,
 		pageIndicator,
 		scrollPercent,
@@ -697,27 +921,51 @@ func (m pagerModel) helpView() (s string) {
 		"/       search",
 		"n/N     next/prev match",
 		":       jump to line/pct",
+		"t       table of contents",
+		"s       statistics",
+	}
+	if len(m.currentDocument.metadata) > 0 {
+		col1 = append(col1, "m       front matter")
+	}
+	if m.currentDocument.book != nil {
+		col1 = append(col1,
+			"[/]     prev/next chapter",
+			"T       chapter list",
+		)
+	}
+	if m.tree != nil {
+		col1 = append(col1,
+			"enter   fold/unfold",
+			"y       copy path",
+		)
+	}
+	col1 = append(col1,
 		"c       copy contents",
 		"e       edit this document",
 		"r       reload this document",
 		"esc     back to files",
 		"q       quit",
-	}
+	)
 
 	s += "\n"
 	s += "k/↑      up                  " + col1[0] + "\n"
 	s += "j/↓      down                " + col1[1] + "\n"
 	s += "b/pgup   page up             " + col1[2] + "\n"
 	s += "f/pgdn   page down           " + col1[3] + "\n"
-	s += "←/→      page back/fwd       " + col1[4] + "\n"
+	switch {
+	case m.tree != nil:
+		s += "←/→ h/l  collapse/expand     " + col1[4] + "\n"
+	case m.scrollsHorizontally():
+		s += "←/→ h/l  scroll left/right   " + col1[4] + "\n"
+	default:
+		s += "←/→      page back/fwd       " + col1[4] + "\n"
+	}
 	s += "u        ½ page up           " + col1[5] + "\n"
 	s += "d        ½ page down         " + col1[6] + "\n"
-	s += "                             " + col1[7] + "\n"
-	s += "                             " + col1[8]
-
-	if len(col1) > 9 {
-		s += "\n                             " + col1[9]
+	for _, c := range col1[7:] {
+		s += "                             " + c + "\n"
 	}
+	s = strings.TrimSuffix(s, "\n")
 
 	s = indent(s, 2)
 
@@ -775,7 +1023,18 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 		return markdown, nil
 	}
 
-	isCode := !utils.IsMarkdownFile(m.currentDocument.Note)
+	if tabular.IsTableFile(m.currentDocument.Note) {
+		return tabular.Render([]byte(markdown), m.currentDocument.Note, tabular.DefaultMaxCellWidth)
+	}
+
+	// data files are shown as trees, or as code when they don't parse
+	if datatree.IsDataFile(m.currentDocument.Note) {
+		if root, err := datatree.Parse([]byte(markdown), m.currentDocument.Note); err == nil {
+			return datatree.Render(root), nil
+		}
+	}
+
+	isCode := isCodeFile(m.currentDocument.Note)
 	width := m.effectiveGlamourWidth()
 	if isCode {
 		width = 0
@@ -794,8 +1053,18 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 		return "", fmt.Errorf("error creating glamour renderer: %w", err)
 	}
 
+	if notebook.IsNotebook(m.currentDocument.Note) {
+		nb, err := notebook.Parse([]byte(markdown))
+		if err != nil {
+			return "", err //nolint:wrapcheck
+		}
+		return nb.Render(r) //nolint:wrapcheck
+	}
+
 	if isCode {
 		markdown = utils.WrapCodeBlock(markdown, filepath.Ext(m.currentDocument.Note))
+	} else {
+		markdown = mermaid.RenderBlocks(markdown)
 	}
 
 	out, err := r.Render(markdown)
@@ -3,6 +3,7 @@ package ui
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -146,6 +147,45 @@ func TestStatusBarView(t *testing.T) {
 		}
 	})
 
+	t.Run("cached document shows age", func(t *testing.T) {
+		cfg := Config{}
+		m := testPagerModel(80, 24, cfg)
+		m.currentDocument = markdown{cachedAt: time.Now().Add(-3 * time.Hour)}
+		m.state = pagerStateBrowse
+
+		var b strings.Builder
+		m.statusBarView(&b)
+		got := b.String()
+		if !strings.Contains(got, "cached 3 hours ago") {
+			t.Errorf("statusBarView() should contain cache age, got: %q", got)
+		}
+	})
+
+	t.Run("converted document shows format", func(t *testing.T) {
+		for note, want := range map[string]string{
+			"guide.rst":  " reStructuredText ",
+			"guide.adoc": " AsciiDoc ",
+			"data.csv":   " CSV ",
+			"nb.ipynb":   " Notebook ",
+			"readme.md":  "",
+			"main.go":    "",
+		} {
+			m := testPagerModel(80, 24, Config{})
+			m.currentDocument = markdown{Note: note}
+			m.state = pagerStateBrowse
+
+			var b strings.Builder
+			m.statusBarView(&b)
+			got := b.String()
+			if want != "" && !strings.Contains(got, want) {
+				t.Errorf("statusBarView() for %s should contain %q, got: %q", note, want, got)
+			}
+			if want == "" && documentFormat(note) != "" {
+				t.Errorf("documentFormat(%q) = %q, want none", note, documentFormat(note))
+			}
+		}
+	})
+
 	t.Run("narrow width no panic", func(t *testing.T) {
 		cfg := Config{}
 		m := testPagerModel(10, 5, cfg)
@@ -451,3 +491,54 @@ func TestArrowKeyPaging(t *testing.T) {
 		}
 	})
 }
+
+func TestTableHorizontalScroll(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	cfg := Config{GlamourEnabled: true}
+	m := testPagerModel(20, 10, cfg)
+	m.currentDocument = markdown{Note: "data.csv"}
+
+	row := strings.Repeat("abcdefghij,", 10) + "z\n"
+	out, err := glamourRender(m, row+row+row)
+	if err != nil {
+		t.Fatalf("glamourRender() error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg{content: out})
+
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("right arrow should not page tables, got YOffset %d", m.viewport.YOffset)
+	}
+	if p := m.viewport.HorizontalScrollPercent(); p == 0 {
+		t.Error("right arrow should scroll tables to the right")
+	}
+
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyLeft})
+	if p := m.viewport.HorizontalScrollPercent(); p != 0 {
+		t.Errorf("left arrow should scroll back, got %v", p)
+	}
+
+	// h scrolls back too, rather than going back to the files
+	app := model{common: m.common, state: stateShowDocument, pager: m}
+	app.pager, _ = app.pager.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if p := app.pager.viewport.HorizontalScrollPercent(); p == 0 {
+		t.Error("l should scroll tables to the right")
+	}
+	updated, _ := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	app = updated.(model)
+	if app.state != stateShowDocument {
+		t.Fatal("h should not close tables")
+	}
+	if p := app.pager.viewport.HorizontalScrollPercent(); p != 0 {
+		t.Errorf("h should scroll back, got %v", p)
+	}
+
+	m.currentDocument = markdown{Note: "doc.md"}
+	m, _ = m.update(contentRenderedMsg{content: "# doc"})
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyRight})
+	if !strings.Contains(m.viewport.View(), "# doc") {
+		t.Errorf("documents shouldn't scroll horizontally, got %q", m.viewport.View())
+	}
+}
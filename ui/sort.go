@@ -7,6 +7,6 @@ import (
 
 func sortMarkdowns(mds []*markdown) {
 	slices.SortStableFunc(mds, func(a, b *markdown) int {
-		return cmp.Compare(a.Note, b.Note)
+		return cmp.Compare(a.displayName(), b.displayName())
 	})
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/epub"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/muesli/reflow/ansi"
@@ -318,6 +319,8 @@ func (m stashModel) getVisibleMarkdowns() []*markdown {
 // alters the model.
 func (m *stashModel) openMarkdown(md *markdown) tea.Cmd {
 	m.viewState = stashStateLoadingDocument
+	// books are opened where reading was left off
+	md.book = nil
 	cmd := loadLocalMarkdown(md)
 	return tea.Batch(cmd, m.spinner.Tick)
 }
@@ -860,7 +863,16 @@ func loadLocalMarkdown(md *markdown) tea.Cmd {
 			log.Debug("error reading local file", "error", err)
 			return errMsg{err}
 		}
-		md.Body = string(data)
+		if epub.IsEPUB(md.localPath) {
+			err = loadBook(md, data)
+		} else {
+			md.Body, err = documentBody(md.localPath, data)
+			md.metadata = documentMetadata(md.localPath, data)
+		}
+		if err != nil {
+			log.Debug("error converting local file", "error", err)
+			return errMsg{err}
+		}
 		return fetchedMarkdownMsg(md)
 	}
 }
@@ -19,7 +19,7 @@ func stashItemView(b *strings.Builder, m stashModel, index int, md *markdown) {
 	var (
 		truncateTo  = uint(m.common.width - stashViewHorizontalPadding*2) //nolint:gosec
 		gutter      string
-		title       = truncate.StringWithTail(md.Note, truncateTo, ellipsis)
+		title       = truncate.StringWithTail(md.displayName(), truncateTo, ellipsis)
 		date        = md.relativeTime()
 		editedBy    = ""
 		hasEditedBy = false
@@ -27,6 +27,10 @@ func stashItemView(b *strings.Builder, m stashModel, index int, md *markdown) {
 		separator   = ""
 	)
 
+	if rt := md.readingTime(); rt != "" {
+		date += " · " + rt
+	}
+
 	isSelected := index == m.cursor()
 	isFiltering := m.filterState == filtering
 	singleFilteredItem := isFiltering && len(m.getVisibleMarkdowns()) == 1
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/structure"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/dustin/go-humanize"
+)
+
+// documentStats returns the statistics of the current document. Tables and
+// source code have none.
+func (m pagerModel) documentStats() (*structure.Stats, bool) {
+	md, ok := m.markdownBody()
+	if !ok {
+		return nil, false
+	}
+	doc, err := structure.Parse([]byte(md))
+	if err != nil {
+		return nil, false
+	}
+	s := doc.Stats()
+	return &s, true
+}
+
+// statsView renders the statistics of the current document in place of the
+// document.
+func (m pagerModel) statsView() string {
+	height := max(1, m.viewport.Height)
+	lines := []string{"", "  " + fuchsiaFg("Statistics"), ""}
+	row := func(label, value string) {
+		lines = append(lines, "  "+brightGrayFg(fmt.Sprintf("%-16s", label))+value)
+	}
+
+	s := m.stats
+	row("Words", humanize.Comma(int64(s.Words)))
+	row("Reading time", structure.FormatReadingTime(s.Words))
+	row("Lines", humanize.Comma(int64(s.Lines)))
+	row("Headings", s.HeadingCounts())
+	row("Code blocks", s.CodeBlockCounts())
+	row("Links", humanize.Comma(int64(s.Links)))
+	row("Images", humanize.Comma(int64(s.Images)))
+
+	if len(s.LongestSections) > 0 {
+		lines = append(lines, "", "  "+fuchsiaFg("Longest sections"), "")
+		digits := len(humanize.Comma(int64(s.LongestSections[0].Words)))
+		for _, sec := range s.LongestSections {
+			unit := "words"
+			if sec.Words == 1 {
+				unit = "word "
+			}
+			lines = append(lines, fmt.Sprintf("  %s  %s",
+				brightGrayFg(fmt.Sprintf("%*s %s", digits, humanize.Comma(int64(sec.Words)), unit)),
+				sec.Text,
+			))
+		}
+	}
+
+	for i, l := range lines {
+		lines[i] = xansi.Truncate(l, max(0, m.viewport.Width), ellipsis)
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:height], "\n")
+}
+
+func (m *pagerModel) handleStatsKeys(msg tea.KeyMsg) {
+	switch msg.String() {
+	case keyEsc, keyEnter, "s", "q":
+		m.state = pagerStateBrowse
+		m.stats = nil
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/gitcha"
+)
+
+func TestStatsOverlay(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	m := testPagerModel(80, 20, Config{})
+	m.currentDocument = markdown{
+		Note: "notes.md",
+		Body: "# One\n\nSome words here.\n\n## Two\n\n```go\nx := 1\n```\n\n[a link](https://x.org)\n",
+	}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.state != pagerStateStats || !m.inInputMode() {
+		t.Fatalf("expected statistics to be shown, state %v", m.state)
+	}
+	view := m.View()
+	for _, want := range []string{"Statistics", "Words", "h1 1 · h2 1", "go 1", "Longest sections"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q, got %q", want, view)
+		}
+	}
+
+	m.handleStatsKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != pagerStateBrowse || m.stats != nil {
+		t.Errorf("state = %v, want browse", m.state)
+	}
+
+	// source code has no statistics
+	m.currentDocument = markdown{Note: "main.go", Body: "package main\n"}
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.state == pagerStateStats {
+		t.Error("expected no statistics for source code")
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  string
+	}{
+		{0, ""},
+		{150, "1 min read"},
+		{1000, "5 min read"},
+		{1001, "6 min read"},
+	}
+	for _, tt := range tests {
+		if got := (markdown{words: tt.words}).readingTime(); got != tt.want {
+			t.Errorf("readingTime() with %d words = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestReadMarkdownSummary(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) gitcha.SearchResult {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gitcha.SearchResult{Path: path, Info: info}
+	}
+
+	tests := []struct {
+		name  string
+		res   gitcha.SearchResult
+		words int
+		title string
+	}{
+		{"front matter", write("post.md", "---\ntitle: A Post\ntags: [a, b]\n---\n\n# Hello\n\nThree more words.\n"), 4, "A Post"},
+		{"no front matter", write("notes.md", "Just some notes.\n"), 3, ""},
+		{"long", write("long.md", strings.Repeat("word ", 2*summaryLimit/5)), 2 * summaryLimit / 5, ""},
+		{"not markdown", write("notes.txt", "Just some notes.\n"), 0, ""},
+		{"invalid front matter", write("bad.md", "---\n: [\n---\nwords\n"), 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, title := readMarkdownSummary(tt.res)
+			if words != tt.words || title != tt.title {
+				t.Errorf("expected %d words and title %q, got %d and %q", tt.words, tt.title, words, title)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/glow/v2/tabular"
+	"github.com/charmbracelet/glow/v2/utils"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// How many runes of a heading are used to find it in the rendered document.
+// Long headings may be wrapped.
+const tocMatchLength = 30
+
+// tocEntry is a heading in the table of contents.
+type tocEntry struct {
+	level int
+	title string
+	// line is where the heading is in the rendered document.
+	line int
+}
+
+// tableOfContents returns the table of contents of the current document,
+// with the headings located in its rendered content.
+func (m pagerModel) tableOfContents(content string) []tocEntry {
+	md, ok := m.markdownBody()
+	if !ok {
+		return nil
+	}
+	return locateHeadings(documentHeadings(md), content)
+}
+
+// markdownBody returns the markdown of the current document: the markdown
+// cells of notebooks, and nothing for tables and source code.
+func (m pagerModel) markdownBody() (string, bool) {
+	path := m.currentDocument.Note
+	if tabular.IsTableFile(path) {
+		return "", false
+	}
+
+	md := m.currentDocument.Body
+	switch {
+	case notebook.IsNotebook(path):
+		nb, err := notebook.Parse([]byte(md))
+		if err != nil {
+			return "", false
+		}
+		var cells []string
+		for _, c := range nb.Cells {
+			if c.Type == notebook.CellMarkdown {
+				cells = append(cells, c.Source)
+			}
+		}
+		md = strings.Join(cells, "\n\n")
+	case isCodeFile(path):
+		return "", false
+	}
+	return md, true
+}
+
+// documentHeadings returns the headings of a markdown document, in order.
+// Headings without text are left out.
+func documentHeadings(md string) []tocEntry {
+	doc, err := structure.Parse([]byte(md))
+	if err != nil {
+		// the pager shows documents with invalid front matter regardless
+		doc, err = structure.Parse(utils.RemoveFrontmatter([]byte(md)))
+		if err != nil {
+			return nil
+		}
+	}
+
+	var headings []tocEntry
+	structure.Walk(doc.Headings, func(h *structure.Heading) {
+		if h.Text != "" {
+			headings = append(headings, tocEntry{level: h.Level, title: h.Text})
+		}
+	})
+	return headings
+}
+
+// locateHeadings sets the lines of the headings in the rendered content.
+// Headings are looked for in order, and those which can't be found are
+// dropped.
+func locateHeadings(headings []tocEntry, content string) []tocEntry {
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		lines[i] = normalizeHeading(xansi.Strip(l))
+	}
+
+	found := make([]tocEntry, 0, len(headings))
+	next := 0
+	for _, h := range headings {
+		needle := []rune(normalizeHeading(h.title))
+		if len(needle) > tocMatchLength {
+			needle = needle[:tocMatchLength]
+		}
+		if i := findHeadingLine(lines[next:], string(needle)); i >= 0 {
+			h.line = next + i
+			found = append(found, h)
+			next = h.line + 1
+		}
+	}
+	return found
+}
+
+// findHeadingLine returns the index of the line a heading is on. Lines
+// starting with the heading are preferred over lines mentioning it.
+func findHeadingLine(lines []string, heading string) int {
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimLeft(l, "# "), heading) {
+			return i
+		}
+	}
+	for i, l := range lines {
+		if strings.Contains(l, heading) {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeHeading makes headings comparable regardless of how they're
+// styled, e.g. whether emphasis markers are shown.
+func normalizeHeading(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '_', '`', '~':
+			return -1
+		}
+		return r
+	}, s)
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// tocCurrentEntry returns the index of the heading of the section which is
+// shown at the top of the viewport.
+func (m pagerModel) tocCurrentEntry() int {
+	current := 0
+	for i, e := range m.toc {
+		if e.line > m.viewport.YOffset {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// tocView renders the table of contents in place of the document.
+func (m pagerModel) tocView() string {
+	minLevel := 6
+	for _, e := range m.toc {
+		minLevel = min(minLevel, e.level)
+	}
+	items := make([]string, 0, len(m.toc))
+	for _, e := range m.toc {
+		items = append(items, strings.Repeat("  ", e.level-minLevel)+e.title)
+	}
+	return m.listView("Contents", items, m.tocCursor)
+}
+
+// listView renders a list to pick from, like the table of contents, in
+// place of the document. The list scrolls to keep the cursor in view.
+func (m pagerModel) listView(title string, items []string, cursor int) string {
+	height := max(1, m.viewport.Height)
+	lines := []string{"", "  " + fuchsiaFg(title), ""}
+	visible := max(1, height-len(lines))
+	start := max(0, min(cursor-visible/2, len(items)-visible))
+	end := min(len(items), start+visible)
+
+	for i := start; i < end; i++ {
+		item := xansi.Truncate(items[i], max(0, m.viewport.Width-4), ellipsis)
+		if i == cursor {
+			lines = append(lines, dullFuchsiaFg(verticalLine)+" "+fuchsiaFg(item))
+		} else {
+			lines = append(lines, "  "+brightGrayFg(item))
+		}
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:height], "\n")
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDocumentHeadings(t *testing.T) {
+	md := "# Title\n\nIntro\n\n## *Setup* `steps`\n\n```sh\n# not a heading\n```\n\n" +
+		"Other\n-----\n\n### [Link](https://x.org) ###\n\n- item\n---\n\n#hashtag\n\n" +
+		"- ## In a list\n\n> Quoted\n> ===\n\n<div>\n# not a heading either\n</div>\n\n##\n"
+	want := []tocEntry{
+		{level: 1, title: "Title"},
+		{level: 2, title: "Setup steps"},
+		{level: 2, title: "Other"},
+		{level: 3, title: "Link"},
+		{level: 2, title: "In a list"},
+		{level: 1, title: "Quoted"},
+	}
+	if got := documentHeadings(md); !reflect.DeepEqual(got, want) {
+		t.Errorf("documentHeadings() = %+v, want %+v", got, want)
+	}
+
+	if got := documentHeadings("---\n: [\n---\n# Title\n"); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("documentHeadings() with invalid front matter = %+v, want %+v", got, want[:1])
+	}
+}
+
+func TestLocateHeadings(t *testing.T) {
+	content := "\n  \x1b[1m Title \x1b[0m\n\n  intro mentions Usage\n\n  ## Usage\n\n  ## **Bold** heading\n"
+	headings := []tocEntry{
+		{level: 1, title: "Title"},
+		{level: 2, title: "Usage"},
+		{level: 2, title: "Missing"},
+		{level: 2, title: "Bold heading"},
+	}
+	got := locateHeadings(headings, content)
+	want := []tocEntry{
+		{level: 1, title: "Title", line: 1},
+		{level: 2, title: "Usage", line: 5},
+		{level: 2, title: "Bold heading", line: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("locateHeadings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTOCNavigation(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	m := testPagerModel(80, 10, Config{})
+	m.currentDocument = markdown{Note: "notes.org", Body: "# One\n\n## Two\n\n# Three\n"}
+	content := "One\n" + strings.Repeat("text\n", 20) + "Two\n" + strings.Repeat("text\n", 20) + "Three\n" +
+		strings.Repeat("text\n", 20)
+	m.viewport.SetContent(content)
+	m.toc = m.tableOfContents(content)
+	if len(m.toc) != 3 {
+		t.Fatalf("expected 3 headings, got %+v", m.toc)
+	}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.state != pagerStateTOC || !m.inInputMode() {
+		t.Fatalf("expected table of contents to be shown, state %v", m.state)
+	}
+	if !strings.Contains(m.View(), "Contents") {
+		t.Errorf("View() should show the table of contents, got %q", m.View())
+	}
+
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyDown})
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyDown})
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyDown})
+	if m.tocCursor != 2 {
+		t.Errorf("cursor = %d, want 2", m.tocCursor)
+	}
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyUp})
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != pagerStateBrowse {
+		t.Errorf("state = %v, want browse", m.state)
+	}
+	if m.viewport.YOffset != m.toc[1].line {
+		t.Errorf("YOffset = %d, want %d", m.viewport.YOffset, m.toc[1].line)
+	}
+
+	// reopening selects the current section
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.tocCursor != 1 {
+		t.Errorf("cursor = %d, want 1", m.tocCursor)
+	}
+	m.handleTOCKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != pagerStateBrowse {
+		t.Errorf("state = %v, want browse", m.state)
+	}
+}
+
+func TestTOCWithoutHeadings(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	m := testPagerModel(80, 10, Config{})
+	m.currentDocument = markdown{Note: "main.py", Body: "# a comment\nprint(1)\n"}
+	m.toc = m.tableOfContents("# a comment\nprint(1)\n")
+	if len(m.toc) != 0 {
+		t.Errorf("code files shouldn't have headings, got %+v", m.toc)
+	}
+
+	m.handleBrowseKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.state != pagerStateStatusMessage || m.statusMessage != "no headings" {
+		t.Errorf("expected a status message, got state %v %q", m.state, m.statusMessage)
+	}
+}
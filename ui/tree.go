@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/datatree"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// loadTree shows the current data file as a tree. Files that don't parse
+// are left as highlighted code. Collapsed nodes are kept across reloads.
+func (m *pagerModel) loadTree(body string) {
+	m.tree = nil
+	if !datatree.IsDataFile(m.currentDocument.Note) || !config.GlamourEnabled {
+		return
+	}
+	root, err := datatree.Parse([]byte(body), m.currentDocument.Note)
+	if err != nil {
+		return
+	}
+	m.tree = root
+	if m.treeCollapsed == nil {
+		m.treeCollapsed = map[string]bool{}
+	}
+	m.renderTree()
+}
+
+// renderTree renders the visible nodes of the tree into the viewport, with
+// the cursor on the selected one.
+func (m *pagerModel) renderTree() {
+	m.treeNodes = datatree.Visible(m.tree, m.treeCollapsed)
+	m.treeCursor = max(0, min(m.treeCursor, len(m.treeNodes)-1))
+
+	lines := []string{""}
+	for i, n := range m.treeNodes {
+		prefix := "  "
+		if i == m.treeCursor {
+			prefix = dullFuchsiaFg(verticalLine) + " "
+		}
+		line := prefix + datatree.Line(n, m.treeCollapsed[n.Path()])
+		if m.viewport.Width > 0 {
+			line = xansi.Truncate(line, m.viewport.Width, ellipsis)
+		}
+		lines = append(lines, line)
+	}
+	m.setContent(strings.Join(lines, "\n"))
+
+	// keep the cursor in view; the first line is blank
+	line := m.treeCursor + 1
+	switch {
+	case line < m.viewport.YOffset+1:
+		m.viewport.SetYOffset(line - 1)
+	case line >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+}
+
+// treeLines returns the visible lines of the tree, without styling.
+func (m pagerModel) treeLines() string {
+	lines := make([]string, len(m.treeNodes))
+	for i, n := range m.treeNodes {
+		lines[i] = xansi.Strip(datatree.Line(n, m.treeCollapsed[n.Path()]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// treePath returns the path of the node under the cursor.
+func (m pagerModel) treePath() string {
+	if len(m.treeNodes) == 0 {
+		return ""
+	}
+	if p := m.treeNodes[m.treeCursor].Path(); p != "" {
+		return p
+	}
+	return "."
+}
+
+// toggleTreeNode collapses or expands the node under the cursor.
+func (m *pagerModel) toggleTreeNode(collapse bool) {
+	n := m.treeNodes[m.treeCursor]
+	if !n.Collapsible() || m.treeCollapsed[n.Path()] == collapse {
+		return
+	}
+	if collapse {
+		m.treeCollapsed[n.Path()] = true
+	} else {
+		delete(m.treeCollapsed, n.Path())
+	}
+	// matches are lines of the tree, which has changed
+	m.clearSearch()
+}
+
+// handleTreeKeys moves the cursor of a tree and folds its nodes. It reports
+// whether the key was handled.
+func (m *pagerModel) handleTreeKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if len(m.treeNodes) == 0 {
+		return nil, false
+	}
+	var cmd tea.Cmd
+	n := m.treeNodes[m.treeCursor]
+	switch msg.String() {
+	case "k", "up":
+		m.treeCursor--
+	case "j", "down":
+		m.treeCursor++
+	case "g", "home":
+		m.treeCursor = 0
+	case "G", "end":
+		m.treeCursor = len(m.treeNodes) - 1
+	case "b", "pgup":
+		m.treeCursor -= m.viewport.Height
+	case "f", "pgdown":
+		m.treeCursor += m.viewport.Height
+	case "u":
+		m.treeCursor -= m.viewport.Height / 2 //nolint:mnd
+	case "d":
+		m.treeCursor += m.viewport.Height / 2 //nolint:mnd
+
+	case keyEnter, " ":
+		m.toggleTreeNode(!m.treeCollapsed[n.Path()])
+	case "l", "right":
+		switch {
+		case !n.Collapsible():
+		case m.treeCollapsed[n.Path()]:
+			m.toggleTreeNode(false)
+		default:
+			// the first member
+			m.treeCursor++
+		}
+	case "h", "left":
+		if n.Collapsible() && !m.treeCollapsed[n.Path()] {
+			m.toggleTreeNode(true)
+		} else {
+			m.treeCursor = m.treeParent(m.treeCursor)
+		}
+
+	case "y":
+		path := m.treePath()
+		// Copy using OSC 52
+		termenv.Copy(path)
+		// Copy using native system clipboard
+		_ = clipboard.WriteAll(path)
+		cmd = m.showStatusMessage(pagerStatusMessage{"Copied path " + path, false})
+
+	default:
+		return nil, false
+	}
+
+	m.renderTree()
+	if m.viewport.HighPerformanceRendering {
+		cmd = tea.Batch(cmd, viewport.Sync(m.viewport))
+	}
+	return cmd, true
+}
+
+// treeParent returns the position of the parent of a visible node, or of
+// the node itself at the top level.
+func (m pagerModel) treeParent(i int) int {
+	parent := m.treeNodes[i].Parent
+	for j := i - 1; j >= 0; j-- {
+		if m.treeNodes[j] == parent {
+			return j
+		}
+	}
+	return i
+}
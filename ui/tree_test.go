@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestTreeNavigation(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	cfg := Config{GlamourEnabled: true}
+	m := testPagerModel(80, 10, cfg)
+	m.currentDocument = markdown{Note: "pod.yaml"}
+
+	body := strings.Join([]string{
+		"spec:",
+		"  containers:",
+		"    - name: web",
+		"      image: nginx",
+		"kind: Pod",
+	}, "\n")
+	m, _ = m.update(contentRenderedMsg{body: body, content: body})
+	if m.tree == nil {
+		t.Fatal("expected a tree")
+	}
+
+	keys := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "left":
+				msg = tea.KeyMsg{Type: tea.KeyLeft}
+			}
+			m, _ = m.update(msg)
+		}
+	}
+
+	keys("j", "j", "j", "j")
+	if got := m.treePath(); got != "spec.containers[0].image" {
+		t.Errorf("got path %q", got)
+	}
+	var b strings.Builder
+	m.statusBarView(&b)
+	if got := xansi.Strip(b.String()); !strings.Contains(got, "pod.yaml › spec.containers[0].image") {
+		t.Errorf("status bar should show the path, got %q", got)
+	}
+
+	// to the array element, and collapse it
+	keys("h", "h")
+	if got := m.treePath(); got != "spec.containers[0]" {
+		t.Errorf("got path %q", got)
+	}
+	if len(m.treeNodes) != 4 {
+		t.Errorf("expected 4 visible nodes, got %d", len(m.treeNodes))
+	}
+
+	keys("g", "enter")
+	var lines []string
+	for _, l := range strings.Split(xansi.Strip(m.viewport.View()), "\n") {
+		lines = append(lines, strings.TrimRight(l, " "))
+	}
+	if got := strings.Join(lines, "\n"); !strings.Contains(got, "│ ▸ spec {1 key}\n    kind: \"Pod\"") {
+		t.Errorf("spec should be collapsed, got:\n%s", got)
+	}
+	keys("l", "l")
+	if got := m.treePath(); got != "spec.containers" {
+		t.Errorf("got path %q", got)
+	}
+
+	// h collapses nodes rather than going back to the files
+	app := model{common: m.common, state: stateShowDocument, pager: m}
+	updated, _ := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	app = updated.(model)
+	if app.state != stateShowDocument || app.pager.tree == nil {
+		t.Fatal("h should not close trees")
+	}
+	if !app.pager.treeCollapsed["spec.containers"] {
+		t.Errorf("h should collapse spec.containers, got %v", app.pager.treeCollapsed)
+	}
+
+	// search moves the cursor
+	m.searchInput.SetValue("kind")
+	m.state = pagerStateSearch
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.treePath(); got != "kind" {
+		t.Errorf("got path %q", got)
+	}
+}
+
+func TestTreeInvalidData(t *testing.T) {
+	savedConfig := config
+	t.Cleanup(func() { config = savedConfig })
+
+	cfg := Config{GlamourEnabled: true}
+	m := testPagerModel(80, 10, cfg)
+	m.currentDocument = markdown{Note: "broken.json"}
+
+	m, _ = m.update(contentRenderedMsg{body: `{"a": `, content: `{"a": `})
+	if m.tree != nil {
+		t.Error("invalid data shouldn't be shown as a tree")
+	}
+}
@@ -2,14 +2,21 @@
 package ui
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/glow/v2/epub"
+	"github.com/charmbracelet/glow/v2/notebook"
+	"github.com/charmbracelet/glow/v2/structure"
+	"github.com/charmbracelet/glow/v2/tabular"
 	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/log"
 	"github.com/muesli/gitcha"
@@ -25,7 +32,8 @@ var (
 	config Config
 
 	markdownExtensions = []string{
-		"*.md", "*.mdown", "*.mkdn", "*.mkd", "*.markdown",
+		"*.md", "*.mdown", "*.mkdn", "*.mkd", "*.markdown", "*" + notebook.Extension,
+		"*.rst", "*.rest", "*.adoc", "*.asciidoc", "*.org", "*.docx", "*.odt", "*" + epub.Extension, "*.mmd",
 	}
 )
 
@@ -60,7 +68,13 @@ type (
 )
 
 type (
-	foundLocalFileMsg       gitcha.SearchResult
+	foundLocalFileMsg struct {
+		res gitcha.SearchResult
+		// words counts the words of markdown files, and title is the title
+		// in their front matter.
+		words int
+		title string
+	}
 	localFileSearchFinished struct{}
 	statusMessageTimeoutMsg applicationContext
 )
@@ -155,7 +169,7 @@ func newModel(cfg Config, content string) tea.Model {
 	path := cfg.Path
 	if path == "" && content != "" {
 		m.state = stateShowDocument
-		m.pager.currentDocument = markdown{Body: content}
+		m.pager.currentDocument = markdown{Body: content, cachedAt: cfg.CachedAt}
 		return m
 	}
 
@@ -190,13 +204,8 @@ func (m model) Init() tea.Cmd {
 	case stateShowStash:
 		cmds = append(cmds, findLocalFiles(*m.common))
 	case stateShowDocument:
-		content, err := os.ReadFile(m.common.cfg.Path)
-		if err != nil {
-			log.Error("unable to read file", "file", m.common.cfg.Path, "error", err)
-			return func() tea.Msg { return errMsg{err} }
-		}
-		body := string(utils.RemoveFrontmatter(content))
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		md := m.pager.currentDocument
+		cmds = append(cmds, loadLocalMarkdown(&md))
 	}
 
 	return tea.Batch(cmds...)
@@ -251,12 +260,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			if m.state == stateShowDocument {
+				m.pager.saveReadingPosition()
+			}
 			return m, tea.Quit
 
 		case "h", "delete":
 			if m.state == stateShowDocument && m.pager.inInputMode() {
 				break // let pager textinput handle cursor/delete
 			}
+			if m.state == stateShowDocument && msg.String() == "h" && m.pager.handlesLeftKey() {
+				break // let pager collapse the tree node or scroll to the left
+			}
 			if m.state == stateShowDocument {
 				cmds = append(cmds, m.unloadDocument()...)
 				return m, tea.Batch(cmds...)
@@ -267,6 +282,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Ctrl+C always quits no matter where in the application you are.
 		case "ctrl+c":
+			if m.state == stateShowDocument {
+				m.pager.saveReadingPosition()
+			}
 			return m, tea.Quit
 		}
 
@@ -285,10 +303,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case fetchedMarkdownMsg:
 		// We've loaded a markdown file's contents for rendering
 		m.pager.currentDocument = *msg
-		body := string(utils.RemoveFrontmatter([]byte(msg.Body)))
-		m.pager.currentDocument.Body = body
 		m.pager.renderSeq++
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		cmds = append(cmds, renderWithGlamour(m.pager, msg.Body))
 
 	case contentRenderedMsg:
 		m.state = stateShowDocument
@@ -302,7 +318,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case foundLocalFileMsg:
-		newMd := localFileToMarkdown(m.common.cwd, gitcha.SearchResult(msg))
+		newMd := localFileToMarkdown(m.common.cwd, msg.res)
+		newMd.words, newMd.title = msg.words, msg.title
 		m.stash.addMarkdowns(newMd)
 		if m.stash.filterApplied() {
 			newMd.buildFilterValue()
@@ -367,6 +384,9 @@ func errorView(err error, fatal bool) string {
 // COMMANDS
 
 func findLocalFiles(m commonModel) tea.Cmd {
+	if len(m.cfg.Files) > 0 {
+		return listLocalFiles(m.cfg.Files)
+	}
 	return func() tea.Msg {
 		log.Info("findLocalFiles")
 		var (
@@ -392,19 +412,59 @@ func findLocalFiles(m commonModel) tea.Cmd {
 
 		log.Debug("local directory is", "cwd", cwd)
 
-		// Switch between FindFiles and FindAllFiles to bypass .gitignore rules
-		var ch chan gitcha.SearchResult
-		if m.cfg.ShowAllFiles {
-			ch, err = gitcha.FindAllFilesExcept(cwd, markdownExtensions, nil)
-		} else {
-			ch, err = gitcha.FindFilesExcept(cwd, markdownExtensions, ignorePatterns(m))
+		ch, err := FindFiles(m.cfg, cwd)
+		if err != nil {
+			log.Error("error finding local files", "error", err)
+			return errMsg{err}
 		}
 
+		return initLocalFileSearchMsg{ch: ch, cwd: cwd}
+	}
+}
+
+// FindFiles searches a directory for the files the file listing shows,
+// following its ignore rules.
+func FindFiles(cfg Config, dir string) (chan gitcha.SearchResult, error) {
+	// Switch between FindFiles and FindAllFiles to bypass .gitignore rules
+	if cfg.ShowAllFiles {
+		return gitcha.FindAllFilesExcept(dir, searchExtensions(cfg), nil) //nolint:wrapcheck
+	}
+	return gitcha.FindFilesExcept(dir, searchExtensions(cfg), ignorePatterns(cfg)) //nolint:wrapcheck
+}
+
+// searchExtensions returns the patterns of the files to list.
+func searchExtensions(cfg Config) []string {
+	if !cfg.ShowTables {
+		return markdownExtensions
+	}
+	exts := slices.Clone(markdownExtensions)
+	for _, ext := range tabular.Extensions {
+		exts = append(exts, "*"+ext)
+	}
+	return exts
+}
+
+// listLocalFiles feeds a fixed set of files into the file listing, the same
+// way a local file search would.
+func listLocalFiles(files []string) tea.Cmd {
+	return func() tea.Msg {
+		cwd, err := os.Getwd()
 		if err != nil {
-			log.Error("error finding local files", "error", err)
+			log.Error("error listing local files", "error", err)
 			return errMsg{err}
 		}
 
+		ch := make(chan gitcha.SearchResult, len(files))
+		for _, path := range files {
+			info, err := os.Stat(path)
+			if err != nil {
+				log.Error("unable to stat file", "file", path, "error", err)
+				continue
+			}
+			ch <- gitcha.SearchResult{Path: path, Info: info}
+		}
+		close(ch)
+
 		return initLocalFileSearchMsg{ch: ch, cwd: cwd}
 	}
 }
@@ -415,7 +475,8 @@ func findNextLocalFile(m model) tea.Cmd {
 
 		if ok {
 			// Okay now find the next one
-			return foundLocalFileMsg(res)
+			words, title := readMarkdownSummary(res)
+			return foundLocalFileMsg{res: res, words: words, title: title}
 		}
 		// We're done
 		log.Debug("local file search finished")
@@ -443,6 +504,46 @@ func localFileToMarkdown(cwd string, res gitcha.SearchResult) *markdown {
 	}
 }
 
+// summaryLimit is how much of a markdown file the stash reads to estimate
+// its reading time.
+const summaryLimit = 64 * 1024
+
+// readMarkdownSummary estimates the words of a markdown file, for its reading
+// time, and returns the title in its front matter. Only the start of the file
+// is read, so the words of longer files are extrapolated from their size, and
+// markup counts towards them. Other files aren't read.
+func readMarkdownSummary(res gitcha.SearchResult) (int, string) {
+	if !utils.IsMarkdownFile(res.Path) {
+		return 0, ""
+	}
+	f, err := os.Open(res.Path)
+	if err != nil {
+		return 0, ""
+	}
+	defer f.Close() //nolint:errcheck
+	b, err := io.ReadAll(io.LimitReader(f, summaryLimit))
+	if err != nil || len(b) == 0 {
+		return 0, ""
+	}
+	fields, n, err := structure.FrontMatter(b)
+	if err != nil {
+		return 0, ""
+	}
+	size := int64(len(b))
+	if res.Info != nil && res.Info.Size() > size {
+		// leave out the word the limit cut in half
+		if i := bytes.LastIndexAny(b, " \t\n"); i > n {
+			b = b[:i]
+		}
+		size = res.Info.Size()
+	}
+	words := structure.CountWords(string(b[n:]))
+	if size > int64(len(b)) {
+		words = int(int64(words) * size / int64(len(b)))
+	}
+	return words, structure.Title(fields)
+}
+
 func stripAbsolutePath(fullPath, cwd string) string {
 	fp, _ := filepath.EvalSymlinks(fullPath)
 	cp, _ := filepath.EvalSymlinks(cwd)
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestURLParser(t *testing.T) {
 	for path, url := range map[string]string{
@@ -15,7 +18,7 @@ func TestURLParser(t *testing.T) {
 	} {
 		t.Run(path, func(t *testing.T) {
 			t.Skip("test uses network, sometimes fails for no reason")
-			got, err := readmeURL(path)
+			got, err := readmeURL(context.Background(), path)
 			if err != nil {
 				t.Fatalf("expected no error, got %v", err)
 			}
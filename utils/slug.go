@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slugs generates the anchors GitHub links headings with, like
+// "getting-started" for "Getting Started!". Repeated headings get numbered
+// anchors, like "usage-1", so a Slugs should be used for one document.
+type Slugs map[string]int
+
+// Slug returns the anchor of a heading, given its text without markup.
+func (s Slugs) Slug(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+
+	slug := b.String()
+	base := slug
+	for {
+		if _, ok := s[slug]; !ok {
+			break
+		}
+		s[base]++
+		slug = base + "-" + strconv.Itoa(s[base])
+	}
+	s[slug] = 0
+	return slug
+}
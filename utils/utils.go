@@ -2,6 +2,8 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,7 +16,8 @@ import (
 	"github.com/mitchellh/go-homedir"
 )
 
-// RemoveFrontmatter removes the front matter header of a markdown file.
+// RemoveFrontmatter removes the YAML or TOML front matter header of a
+// markdown file.
 func RemoveFrontmatter(content []byte) []byte {
 	if frontmatterBoundaries := detectFrontmatter(content); frontmatterBoundaries[0] == 0 {
 		return content[frontmatterBoundaries[1]:]
@@ -22,11 +25,16 @@ func RemoveFrontmatter(content []byte) []byte {
 	return content
 }
 
-var yamlPattern = regexp.MustCompile(`(?m)^---\r?\n(\s*\r?\n)?`)
+var (
+	yamlPattern = regexp.MustCompile(`(?m)^---\r?\n(\s*\r?\n)?`)
+	tomlPattern = regexp.MustCompile(`(?m)^\+\+\+\r?\n(\s*\r?\n)?`)
+)
 
 func detectFrontmatter(c []byte) []int {
-	if matches := yamlPattern.FindAllIndex(c, 2); len(matches) > 1 {
-		return []int{matches[0][0], matches[1][1]}
+	for _, pattern := range []*regexp.Regexp{yamlPattern, tomlPattern} {
+		if matches := pattern.FindAllIndex(c, 2); len(matches) > 1 && matches[0][0] == 0 {
+			return []int{matches[0][0], matches[1][1]}
+		}
 	}
 	return []int{-1, -1}
 }
@@ -69,11 +77,42 @@ func IsMarkdownFile(filename string) bool {
 	return false
 }
 
+// StyleConfig returns the glamour style with the given name, or reads it
+// from a JSON file. The auto style is the dark or the light style, depending
+// on the terminal's background.
+func StyleConfig(style string) (ansi.StyleConfig, error) {
+	if style == styles.AutoStyle {
+		style = styles.LightStyle
+		if lipgloss.HasDarkBackground() {
+			style = styles.DarkStyle
+		}
+	}
+	if s, ok := styles.DefaultStyles[style]; ok {
+		return *s, nil
+	}
+
+	var cfg ansi.StyleConfig
+	b, err := os.ReadFile(ExpandPath(style))
+	if err != nil {
+		return cfg, fmt.Errorf("unable to read style: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("unable to parse style: %w", err)
+	}
+	return cfg, nil
+}
+
 // GlamourStyle returns a glamour.TermRendererOption based on the given style.
 func GlamourStyle(style string, isCode bool) glamour.TermRendererOption {
 	if !isCode {
 		if style == styles.AutoStyle {
-			return glamour.WithAutoStyle()
+			// glamour's auto style falls back to notty whenever stdout isn't a
+			// terminal, even when colors were asked for, e.g. with
+			// --color=always, so pick the dark or light style ourselves.
+			if lipgloss.HasDarkBackground() {
+				return glamour.WithStandardStyle(styles.DarkStyle)
+			}
+			return glamour.WithStandardStyle(styles.LightStyle)
 		}
 		return glamour.WithStylePath(style)
 	}
@@ -37,6 +37,11 @@ func TestRemoveFrontmatter(t *testing.T) {
 			input: "some text\n---\ntitle: hello\n---\nbody",
 			want:  "some text\n---\ntitle: hello\n---\nbody",
 		},
+		{
+			name:  "TOML frontmatter stripped",
+			input: "+++\ntitle = \"hello\"\n+++\n# Body",
+			want:  "# Body",
+		},
 		{
 			name:  "frontmatter with blank line",
 			input: "---\n\ntitle: hello\n---\n# Body",
@@ -197,3 +202,47 @@ func TestGlamourStyle(t *testing.T) {
 		})
 	}
 }
+
+func TestStyleConfig(t *testing.T) {
+	cfg, err := StyleConfig("dark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Document.Color == nil || *cfg.Document.Color != "252" {
+		t.Errorf("expected the dark style, got %+v", cfg.Document)
+	}
+
+	path := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(path, []byte(`{"document": {"color": "#123456"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = StyleConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Document.Color == nil || *cfg.Document.Color != "#123456" {
+		t.Errorf("expected the custom style, got %+v", cfg.Document)
+	}
+
+	if _, err := StyleConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing style")
+	}
+}
+
+func TestSlugs(t *testing.T) {
+	slugs := Slugs{}
+	for _, tt := range []struct{ heading, want string }{
+		{"Getting Started!", "getting-started"},
+		{"API: v2.0 (beta)", "api-v20-beta"},
+		{"snake_case and-dashes", "snake_case-and-dashes"},
+		{"Überblick", "überblick"},
+		{"Usage", "usage"},
+		{"Usage", "usage-1"},
+		{"Usage", "usage-2"},
+		{"Usage 1", "usage-1-1"},
+	} {
+		if got := slugs.Slug(tt.heading); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}